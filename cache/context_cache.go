@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotFound key不存在或已过期
+	ErrNotFound = errors.New("cache: key not found")
+	// ErrTypeMismatch 缓存中的值不是字符串
+	ErrTypeMismatch = errors.New("cache: value is not a string")
+	// ErrTTLNotSupported 缓存后端不支持查询剩余有效期
+	ErrTTLNotSupported = errors.New("cache: ttl lookup not supported")
+)
+
+// ContextCache 支持context、按字符串读写并返回错误的缓存接口
+// Memory、Redis、Memcache 均已实现，其他 Cache 可通过 ToContextCache 转换
+type ContextCache interface {
+	// GetStringContext 获取字符串，key不存在时返回 ErrNotFound，值不是字符串时返回 ErrTypeMismatch
+	GetStringContext(ctx context.Context, key string) (string, error)
+	// SetStringContext 设置字符串
+	SetStringContext(ctx context.Context, key, val string, timeout time.Duration) error
+	// IsExistContext 判断key是否存在
+	IsExistContext(ctx context.Context, key string) (bool, error)
+	// DeleteContext 删除
+	DeleteContext(ctx context.Context, key string) error
+	// TTLContext 获取剩余有效期，key不存在时返回 ErrNotFound，不支持时返回 ErrTTLNotSupported
+	TTLContext(ctx context.Context, key string) (time.Duration, error)
+}
+
+// ToContextCache 将 Cache 转换为 ContextCache
+// c 本身实现了 ContextCache 时直接返回，否则通过 Get/Set 适配，TTLContext 返回 ErrTTLNotSupported
+func ToContextCache(c Cache) ContextCache {
+	if cc, ok := c.(ContextCache); ok {
+		return cc
+	}
+	return &contextCacheAdapter{c}
+}
+
+// FromContextCache 将 ContextCache 转换为 Cache，以便用于只接受 Cache 的地方
+// c 本身实现了 Cache 时直接返回；Set 仅支持 string 及 []byte 类型的值
+func FromContextCache(c ContextCache) Cache {
+	if legacy, ok := c.(Cache); ok {
+		return legacy
+	}
+	return &cacheAdapter{c}
+}
+
+// toString 将缓存中取出的值转换为字符串
+func toString(val interface{}) (string, error) {
+	switch v := val.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	}
+	return "", ErrTypeMismatch
+}
+
+// contextCacheAdapter 将 Cache 适配为 ContextCache
+type contextCacheAdapter struct {
+	c Cache
+}
+
+func (a *contextCacheAdapter) GetStringContext(ctx context.Context, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	val := a.c.Get(key)
+	if val == nil {
+		return "", ErrNotFound
+	}
+	return toString(val)
+}
+
+func (a *contextCacheAdapter) SetStringContext(ctx context.Context, key, val string, timeout time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.c.Set(key, val, timeout)
+}
+
+func (a *contextCacheAdapter) IsExistContext(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return a.c.IsExist(key), nil
+}
+
+func (a *contextCacheAdapter) DeleteContext(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.c.Delete(key)
+}
+
+func (a *contextCacheAdapter) TTLContext(ctx context.Context, key string) (time.Duration, error) {
+	return 0, ErrTTLNotSupported
+}
+
+// cacheAdapter 将 ContextCache 适配为 Cache
+type cacheAdapter struct {
+	c ContextCache
+}
+
+func (a *cacheAdapter) Get(key string) interface{} {
+	val, err := a.c.GetStringContext(context.Background(), key)
+	if err != nil {
+		return nil
+	}
+	return val
+}
+
+func (a *cacheAdapter) Set(key string, val interface{}, timeout time.Duration) error {
+	switch v := val.(type) {
+	case string:
+		return a.c.SetStringContext(context.Background(), key, v, timeout)
+	case []byte:
+		return a.c.SetStringContext(context.Background(), key, string(v), timeout)
+	}
+	return ErrTypeMismatch
+}
+
+func (a *cacheAdapter) IsExist(key string) bool {
+	ok, err := a.c.IsExistContext(context.Background(), key)
+	return err == nil && ok
+}
+
+func (a *cacheAdapter) Delete(key string) error {
+	return a.c.DeleteContext(context.Background(), key)
+}
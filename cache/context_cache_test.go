@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// bytesCache 以[]byte返回值的Cache
+type bytesCache struct {
+	data map[string]interface{}
+}
+
+func (c *bytesCache) Get(key string) interface{} {
+	if val, ok := c.data[key].(string); ok {
+		return []byte(val)
+	}
+	return c.data[key]
+}
+
+func (c *bytesCache) Set(key string, val interface{}, timeout time.Duration) error {
+	c.data[key] = val
+	return nil
+}
+
+func (c *bytesCache) IsExist(key string) bool {
+	_, ok := c.data[key]
+	return ok
+}
+
+func (c *bytesCache) Delete(key string) error {
+	delete(c.data, key)
+	return nil
+}
+
+func TestMemoryContextCache(t *testing.T) {
+	ctx := context.Background()
+	mem := NewMemory()
+	defer mem.Close()
+
+	var cc ContextCache = mem
+	_, err := cc.GetStringContext(ctx, "token")
+	assert.Equal(t, ErrNotFound, err)
+
+	assert.Nil(t, cc.SetStringContext(ctx, "token", "value", time.Minute))
+	val, err := cc.GetStringContext(ctx, "token")
+	assert.Nil(t, err)
+	assert.Equal(t, "value", val)
+
+	ttl, err := cc.TTLContext(ctx, "token")
+	assert.Nil(t, err)
+	assert.True(t, ttl > 59*time.Second && ttl <= time.Minute)
+
+	assert.Nil(t, mem.Set("number", 1, time.Minute))
+	_, err = cc.GetStringContext(ctx, "number")
+	assert.Equal(t, ErrTypeMismatch, err)
+
+	assert.Nil(t, cc.DeleteContext(ctx, "token"))
+	ok, err := cc.IsExistContext(ctx, "token")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = cc.GetStringContext(canceled, "number")
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestContextCacheAdapter(t *testing.T) {
+	ctx := context.Background()
+	legacy := &bytesCache{data: map[string]interface{}{}}
+
+	cc := ToContextCache(legacy)
+	assert.Nil(t, cc.SetStringContext(ctx, "token", "value", time.Minute))
+	val, err := cc.GetStringContext(ctx, "token")
+	assert.Nil(t, err)
+	assert.Equal(t, "value", val)
+	_, err = cc.TTLContext(ctx, "token")
+	assert.Equal(t, ErrTTLNotSupported, err)
+
+	// 实现了ContextCache的Cache原样返回
+	mem := NewMemory()
+	defer mem.Close()
+	assert.Equal(t, ContextCache(mem), ToContextCache(mem))
+
+	c := FromContextCache(cc)
+	assert.Equal(t, "value", c.Get("token"))
+	assert.True(t, c.IsExist("token"))
+	assert.Equal(t, ErrTypeMismatch, c.Set("number", 1, time.Minute))
+	assert.Nil(t, c.Delete("token"))
+	assert.Nil(t, c.Get("token"))
+}
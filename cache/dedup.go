@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMsgDedupTTL 回调消息去重记录的默认保存时间，微信在5秒内未收到回复时会重试3次
+const DefaultMsgDedupTTL = time.Minute
+
+const (
+	msgDedupPending = "pending"
+	msgDedupDone    = "done:"
+)
+
+// MsgDedup 微信回调消息去重，记录每条消息的处理状态及首次处理的回复
+// Cache 实现了 Locker（如 Redis）时使用 TryLock 原子地认领首次投递，否则仅保证通过同一 MsgDedup 认领的原子性
+type MsgDedup struct {
+	cache  Cache
+	ttl    time.Duration
+	prefix string
+
+	// mu 保证未实现 Locker 的 Cache 认领消息的原子性
+	mu sync.Mutex
+}
+
+// NewMsgDedup 创建回调消息去重，ttl小于等于0时使用 DefaultMsgDedupTTL
+func NewMsgDedup(cache Cache, ttl time.Duration) *MsgDedup {
+	if ttl <= 0 {
+		ttl = DefaultMsgDedupTTL
+	}
+	return &MsgDedup{cache: cache, ttl: ttl, prefix: "gowechat_msg_dedup_"}
+}
+
+// MsgDedupKey 返回消息的去重key，普通消息使用MsgId，事件推送等没有MsgId的消息使用 FromUserName+CreateTime+Event
+func MsgDedupKey(appID string, msgID int64, fromUserName string, createTime int64, event string) string {
+	if msgID != 0 {
+		return appID + "_" + strconv.FormatInt(msgID, 10)
+	}
+	return strings.Join([]string{appID, fromUserName, strconv.FormatInt(createTime, 10), event}, "_")
+}
+
+// Begin 开始处理key对应的消息
+// 首次投递时认领该消息并返回first为true；重复投递时first为false，reply为首次处理的回复，首次处理尚未完成或没有回复时为空
+// 认领失败时按首次投递处理，宁可重复处理也不丢失消息
+func (d *MsgDedup) Begin(key string) (reply []byte, first bool) {
+	if reply, ok := d.result(key); ok {
+		return reply, false
+	}
+	if claimed, err := d.claim(key); err != nil || claimed {
+		return nil, true
+	}
+	reply, _ = d.result(key)
+	return reply, false
+}
+
+// Finish 记录首次处理的回复，reply为空表示不回复
+func (d *MsgDedup) Finish(key string, reply []byte) {
+	_ = d.cache.Set(d.prefix+key, msgDedupDone+string(reply), d.ttl)
+}
+
+// Abort 首次处理失败（如处理方法panic）时释放认领，微信重试时重新处理该消息
+func (d *MsgDedup) Abort(key string) {
+	_ = d.cache.Delete(d.claimKey(key))
+}
+
+// result 返回首次处理完成时记录的回复
+func (d *MsgDedup) result(key string) ([]byte, bool) {
+	val, ok := d.cache.Get(d.prefix + key).(string)
+	if !ok || !strings.HasPrefix(val, msgDedupDone) {
+		return nil, false
+	}
+	return []byte(val[len(msgDedupDone):]), true
+}
+
+// claim 认领key对应的消息，已被其他请求认领时返回false
+func (d *MsgDedup) claim(key string) (bool, error) {
+	if locker, ok := d.cache.(Locker); ok {
+		// 认领在处理完成或租期到期后失效，无需释放
+		_, claimed, err := locker.TryLock(context.Background(), d.claimKey(key), d.ttl)
+		return claimed, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.cache.IsExist(d.claimKey(key)) {
+		return false, nil
+	}
+	return true, d.cache.Set(d.claimKey(key), msgDedupPending, d.ttl)
+}
+
+func (d *MsgDedup) claimKey(key string) string {
+	return d.prefix + "claim_" + key
+}
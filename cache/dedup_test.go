@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMsgDedup(t *testing.T) {
+	dedup := NewMsgDedup(NewMemory(), time.Minute)
+
+	// 并发投递时只有一个请求认领成功
+	var firsts int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, first := dedup.Begin("wx1_100"); first {
+				atomic.AddInt32(&firsts, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), firsts)
+
+	// 处理失败释放认领后重新处理
+	dedup.Abort("wx1_100")
+	_, first := dedup.Begin("wx1_100")
+	assert.True(t, first)
+
+	dedup.Finish("wx1_100", []byte("reply"))
+	reply, first := dedup.Begin("wx1_100")
+	assert.False(t, first)
+	assert.Equal(t, "reply", string(reply))
+}
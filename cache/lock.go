@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Locker 分布式锁，多实例部署时保证同一时刻只有一个实例从微信服务器刷新access_token等凭证
+type Locker interface {
+	// TryLock 尝试获取key对应的锁，ttl为锁的租期，到期后自动释放
+	// 获取成功时ok为true，并返回用于释放锁的unlock；锁被其他实例持有时ok为false
+	TryLock(ctx context.Context, key string, ttl time.Duration) (unlock func() error, ok bool, err error)
+}
+
+// MemoryLocker 进程内的锁，适用于单实例部署或测试
+type MemoryLocker struct {
+	mu     sync.Mutex
+	leases map[string]lease
+}
+
+type lease struct {
+	token   string
+	expired time.Time
+}
+
+// NewMemoryLocker create new MemoryLocker
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{
+		leases: map[string]lease{},
+	}
+}
+
+// TryLock 尝试获取key对应的锁
+func (l *MemoryLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (func() error, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if current, ok := l.leases[key]; ok && current.expired.After(time.Now()) {
+		return nil, false, nil
+	}
+	token := lockToken()
+	l.leases[key] = lease{token: token, expired: time.Now().Add(ttl)}
+	return func() error {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		// 租期已过且被其他调用方重新持有时，不能误删
+		if current, ok := l.leases[key]; ok && current.token == token {
+			delete(l.leases, key)
+		}
+		return nil
+	}, true, nil
+}
+
+// lockToken 生成锁的持有者标识，释放锁时校验，防止释放了其他实例持有的锁
+func lockToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().String()
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryLocker(t *testing.T) {
+	locker := NewMemoryLocker()
+	ctx := context.Background()
+
+	unlock, ok, err := locker.TryLock(ctx, "lock", time.Minute)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	_, ok, err = locker.TryLock(ctx, "lock", time.Minute)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	assert.Nil(t, unlock())
+	unlock, ok, _ = locker.TryLock(ctx, "lock", time.Millisecond)
+	assert.True(t, ok)
+
+	// 租期已过，可以被其他调用方获取，原持有者释放时不影响新的持有者
+	time.Sleep(2 * time.Millisecond)
+	_, ok, _ = locker.TryLock(ctx, "lock", time.Minute)
+	assert.True(t, ok)
+	assert.Nil(t, unlock())
+	_, ok, _ = locker.TryLock(ctx, "lock", time.Minute)
+	assert.False(t, ok)
+}
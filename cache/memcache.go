@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"encoding/json"
 	"time"
 
@@ -55,3 +56,59 @@ func (mem *Memcache) Set(key string, val interface{}, timeout time.Duration) (er
 func (mem *Memcache) Delete(key string) error {
 	return mem.conn.Delete(key)
 }
+
+// GetStringContext 获取字符串
+func (mem *Memcache) GetStringContext(ctx context.Context, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	item, err := mem.conn.Get(key)
+	if err == memcache.ErrCacheMiss {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	var result interface{}
+	if err = json.Unmarshal(item.Value, &result); err != nil {
+		return string(item.Value), nil
+	}
+	return toString(result)
+}
+
+// SetStringContext 设置字符串，与 Set 一样以json格式写入
+func (mem *Memcache) SetStringContext(ctx context.Context, key, val string, timeout time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return mem.Set(key, val, timeout)
+}
+
+// IsExistContext 判断key是否存在
+func (mem *Memcache) IsExistContext(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	_, err := mem.conn.Get(key)
+	if err == memcache.ErrCacheMiss {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// DeleteContext 删除
+func (mem *Memcache) DeleteContext(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	err := mem.conn.Delete(key)
+	if err == memcache.ErrCacheMiss {
+		return nil
+	}
+	return err
+}
+
+// TTLContext memcache 协议不支持查询剩余有效期
+func (mem *Memcache) TTLContext(ctx context.Context, key string) (time.Duration, error) {
+	return 0, ErrTTLNotSupported
+}
@@ -1,74 +1,329 @@
 package cache
 
 import (
+	"container/list"
+	"context"
+	"hash/fnv"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// Memory struct contains *memcache.Client
+const (
+	// defaultMemoryShards 默认分片数
+	defaultMemoryShards = 16
+	// defaultCleanupInterval 默认清理过期数据的间隔
+	defaultCleanupInterval = time.Minute
+)
+
+// MemoryOpts 内存缓存配置
+type MemoryOpts struct {
+	Shards          int           `yml:"shards" json:"shards"`                     // 分片数，默认16
+	MaxEntries      int           `yml:"max_entries" json:"max_entries"`           // 最大条目数，超出时按LRU淘汰，0表示不限制，见 NewMemoryWithOpts
+	CleanupInterval time.Duration `yml:"cleanup_interval" json:"cleanup_interval"` // 后台清理过期数据的间隔，默认1分钟，小于0时不清理
+}
+
+// MemoryStats 内存缓存统计
+type MemoryStats struct {
+	Hits      uint64 // 命中次数
+	Misses    uint64 // 未命中次数，包含已过期
+	Evictions uint64 // 因过期或超出最大条目数被淘汰的次数
+	Entries   int    // 当前条目数
+}
+
+// Memory 并发安全的内存缓存
+// 按key分片加读写锁，后台定期清理过期数据，可选按LRU限制最大条目数
 type Memory struct {
+	// Mutex 仅为兼容之前版本中调用 Lock/Unlock 的代码保留，缓存本身不使用该锁
 	sync.Mutex
+	*memory
+}
+
+type memory struct {
+	hits      uint64
+	misses    uint64
+	evictions uint64
+	entries   int64
+
+	shards     []*shard
+	maxEntries int64
+	stop       chan struct{}
+	once       sync.Once
+}
 
-	data map[string]*data
+type shard struct {
+	sync.RWMutex
+	data map[string]*list.Element
+	lru  *list.List
 }
 
 type data struct {
+	Key     string
 	Data    interface{}
 	Expired time.Time
 }
 
-// NewMemory create new memcache
+// NewMemory create new memory cache
 func NewMemory() *Memory {
-	return &Memory{
-		data: map[string]*data{},
-	}
+	return NewMemoryWithOpts(&MemoryOpts{})
 }
 
-// Get return cached value
-func (mem *Memory) Get(key string) interface{} {
-	if ret, ok := mem.data[key]; ok {
-		if ret.Expired.Before(time.Now()) {
-			mem.deleteKey(key)
-			return nil
+// NewMemoryWithOpts 按配置创建内存缓存
+// MaxEntries 限制所有分片的条目总数，超出时先淘汰写入分片中最久未使用的条目，该分片只剩新写入的条目时依次淘汰其他分片的
+// LRU 顺序只在分片内维护，淘汰的不一定是全局最久未使用的条目；并发写入时条目数可能短暂超出 MaxEntries
+func NewMemoryWithOpts(opts *MemoryOpts) *Memory {
+	shardCount := opts.Shards
+	if shardCount <= 0 {
+		shardCount = defaultMemoryShards
+	}
+
+	m := &memory{
+		shards: make([]*shard, shardCount),
+		stop:   make(chan struct{}),
+	}
+	if opts.MaxEntries > 0 {
+		m.maxEntries = int64(opts.MaxEntries)
+	}
+	for i := range m.shards {
+		m.shards[i] = &shard{
+			data: map[string]*list.Element{},
+			lru:  list.New(),
 		}
-		return ret.Data
 	}
-	return nil
+
+	interval := opts.CleanupInterval
+	if interval == 0 {
+		interval = defaultCleanupInterval
+	}
+	if interval > 0 {
+		go m.janitor(interval)
+	}
+
+	mem := &Memory{memory: m}
+	// 未调用Close时，Memory被回收后停止后台清理
+	runtime.SetFinalizer(mem, func(mem *Memory) {
+		mem.Close()
+	})
+	return mem
 }
 
-// IsExist check value exists in memcache.
-func (mem *Memory) IsExist(key string) bool {
-	if ret, ok := mem.data[key]; ok {
-		if ret.Expired.Before(time.Now()) {
-			mem.deleteKey(key)
-			return false
-		}
-		return true
+// Get return cached value
+func (m *memory) Get(key string) interface{} {
+	s := m.shard(key)
+	if m.maxEntries > 0 {
+		// LRU需要调整顺序，使用写锁
+		s.Lock()
+		defer s.Unlock()
+	} else {
+		s.RLock()
+		defer s.RUnlock()
+	}
+
+	elem, ok := s.data[key]
+	if !ok {
+		atomic.AddUint64(&m.misses, 1)
+		return nil
 	}
-	return false
+	item := elem.Value.(*data)
+	if item.Expired.Before(time.Now()) {
+		// 过期数据由后台或写入时清理，此处只读不删
+		atomic.AddUint64(&m.misses, 1)
+		return nil
+	}
+	if m.maxEntries > 0 {
+		s.lru.MoveToFront(elem)
+	}
+	atomic.AddUint64(&m.hits, 1)
+	return item.Data
+}
+
+// IsExist check value exists in memory.
+func (m *memory) IsExist(key string) bool {
+	s := m.shard(key)
+	s.RLock()
+	defer s.RUnlock()
+
+	elem, ok := s.data[key]
+	return ok && !elem.Value.(*data).Expired.Before(time.Now())
 }
 
 // Set cached value with key and expire time.
-func (mem *Memory) Set(key string, val interface{}, timeout time.Duration) (err error) {
-	mem.Lock()
-	defer mem.Unlock()
+func (m *memory) Set(key string, val interface{}, timeout time.Duration) (err error) {
+	index := m.shardIndex(key)
+	s := m.shards[index]
+	s.Lock()
 
-	mem.data[key] = &data{
+	item := &data{
+		Key:     key,
 		Data:    val,
 		Expired: time.Now().Add(timeout),
 	}
+	if elem, ok := s.data[key]; ok {
+		elem.Value = item
+		s.lru.MoveToFront(elem)
+		s.Unlock()
+		return nil
+	}
+	s.data[key] = s.lru.PushFront(item)
+	atomic.AddInt64(&m.entries, 1)
+	s.Unlock()
+
+	if m.maxEntries > 0 {
+		m.evictOverflow(index)
+	}
 	return nil
 }
 
-// Delete delete value in memcache.
-func (mem *Memory) Delete(key string) error {
-	mem.deleteKey(key)
+// Delete delete value in memory.
+func (m *memory) Delete(key string) error {
+	s := m.shard(key)
+	s.Lock()
+	defer s.Unlock()
+
+	if elem, ok := s.data[key]; ok {
+		s.lru.Remove(elem)
+		delete(s.data, key)
+		atomic.AddInt64(&m.entries, -1)
+	}
 	return nil
 }
 
-// deleteKey
-func (mem *Memory) deleteKey(key string) {
-	mem.Lock()
-	defer mem.Unlock()
-	delete(mem.data, key)
+// GetStringContext 获取字符串
+func (m *memory) GetStringContext(ctx context.Context, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	val := m.Get(key)
+	if val == nil {
+		return "", ErrNotFound
+	}
+	return toString(val)
+}
+
+// SetStringContext 设置字符串
+func (m *memory) SetStringContext(ctx context.Context, key, val string, timeout time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.Set(key, val, timeout)
+}
+
+// IsExistContext 判断key是否存在
+func (m *memory) IsExistContext(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return m.IsExist(key), nil
+}
+
+// DeleteContext 删除
+func (m *memory) DeleteContext(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.Delete(key)
+}
+
+// TTLContext 获取剩余有效期
+func (m *memory) TTLContext(ctx context.Context, key string) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s := m.shard(key)
+	s.RLock()
+	defer s.RUnlock()
+
+	elem, ok := s.data[key]
+	if !ok {
+		return 0, ErrNotFound
+	}
+	ttl := time.Until(elem.Value.(*data).Expired)
+	if ttl <= 0 {
+		return 0, ErrNotFound
+	}
+	return ttl, nil
+}
+
+// Stats 返回命中、淘汰等统计信息
+func (m *memory) Stats() MemoryStats {
+	stats := MemoryStats{
+		Hits:      atomic.LoadUint64(&m.hits),
+		Misses:    atomic.LoadUint64(&m.misses),
+		Evictions: atomic.LoadUint64(&m.evictions),
+		Entries:   int(atomic.LoadInt64(&m.entries)),
+	}
+	return stats
+}
+
+// Close 停止后台清理，可重复调用
+func (m *memory) Close() error {
+	m.once.Do(func() {
+		close(m.stop)
+	})
+	return nil
+}
+
+// shard 根据key选择分片
+func (m *memory) shard(key string) *shard {
+	return m.shards[m.shardIndex(key)]
+}
+
+func (m *memory) shardIndex(key string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(m.shards)))
+}
+
+// evictOverflow 条目总数超出 MaxEntries 时，从写入的分片开始依次淘汰最久未使用的条目
+// 写入的分片保留最新的条目；每次只持有一个分片的锁，避免分片间死锁
+func (m *memory) evictOverflow(index int) {
+	for i := 0; i < len(m.shards) && atomic.LoadInt64(&m.entries) > m.maxEntries; i++ {
+		s := m.shards[(index+i)%len(m.shards)]
+		keep := 0
+		if i == 0 {
+			keep = 1
+		}
+		s.Lock()
+		for s.lru.Len() > keep && atomic.LoadInt64(&m.entries) > m.maxEntries {
+			m.evict(s, s.lru.Back())
+		}
+		s.Unlock()
+	}
+}
+
+// evict 淘汰一个条目，调用方需持有分片的写锁
+func (m *memory) evict(s *shard, elem *list.Element) {
+	s.lru.Remove(elem)
+	delete(s.data, elem.Value.(*data).Key)
+	atomic.AddInt64(&m.entries, -1)
+	atomic.AddUint64(&m.evictions, 1)
+}
+
+// janitor 定期清理过期数据
+func (m *memory) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.deleteExpired()
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// deleteExpired 清理所有分片中已过期的数据
+func (m *memory) deleteExpired() {
+	now := time.Now()
+	for _, s := range m.shards {
+		s.Lock()
+		for _, elem := range s.data {
+			if elem.Value.(*data).Expired.Before(now) {
+				m.evict(s, elem)
+			}
+		}
+		s.Unlock()
+	}
 }
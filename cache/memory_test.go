@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	mem := NewMemory()
+	defer mem.Close()
+
+	assert.Nil(t, mem.Set("username", "silenceper", time.Minute))
+	assert.True(t, mem.IsExist("username"))
+	assert.Equal(t, "silenceper", mem.Get("username"))
+	assert.Nil(t, mem.Delete("username"))
+	assert.False(t, mem.IsExist("username"))
+	assert.Nil(t, mem.Get("username"))
+
+	stats := mem.Stats()
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, 0, stats.Entries)
+}
+
+func TestMemoryJanitor(t *testing.T) {
+	mem := NewMemoryWithOpts(&MemoryOpts{CleanupInterval: 5 * time.Millisecond})
+	defer mem.Close()
+
+	assert.Nil(t, mem.Set("expired", "value", time.Millisecond))
+	assert.Nil(t, mem.Set("alive", "value", time.Minute))
+	time.Sleep(50 * time.Millisecond)
+
+	stats := mem.Stats()
+	assert.Equal(t, 1, stats.Entries)
+	assert.Equal(t, uint64(1), stats.Evictions)
+	assert.Nil(t, mem.Close())
+}
+
+func TestMemoryLRU(t *testing.T) {
+	mem := NewMemoryWithOpts(&MemoryOpts{Shards: 1, MaxEntries: 2})
+	defer mem.Close()
+
+	assert.Nil(t, mem.Set("a", 1, time.Minute))
+	assert.Nil(t, mem.Set("b", 2, time.Minute))
+	// 访问a后，b成为最久未使用的条目
+	assert.Equal(t, 1, mem.Get("a"))
+	assert.Nil(t, mem.Set("c", 3, time.Minute))
+
+	assert.True(t, mem.IsExist("a"))
+	assert.False(t, mem.IsExist("b"))
+	assert.True(t, mem.IsExist("c"))
+	assert.Equal(t, uint64(1), mem.Stats().Evictions)
+}
+
+func TestMemoryConcurrent(t *testing.T) {
+	mem := NewMemoryWithOpts(&MemoryOpts{MaxEntries: 64})
+	defer mem.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				key := fmt.Sprintf("key_%d", j%100)
+				_ = mem.Set(key, i, time.Minute)
+				mem.Get(key)
+				mem.IsExist(key)
+				if j%10 == 0 {
+					_ = mem.Delete(key)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.True(t, mem.Stats().Entries <= 64)
+}
+
+func TestMemoryMaxEntries(t *testing.T) {
+	mem := NewMemoryWithOpts(&MemoryOpts{Shards: 4, MaxEntries: 4})
+	defer mem.Close()
+
+	// 集中在同一分片的key不会在总数未超出时被淘汰
+	var keys []string
+	for i := 0; len(keys) < 4; i++ {
+		if key := fmt.Sprintf("key_%d", i); mem.shardIndex(key) == 0 {
+			keys = append(keys, key)
+			assert.Nil(t, mem.Set(key, i, time.Minute))
+		}
+	}
+	assert.Equal(t, uint64(0), mem.Stats().Evictions)
+	for _, key := range keys {
+		assert.True(t, mem.IsExist(key))
+	}
+
+	for i := 0; i < 20; i++ {
+		assert.Nil(t, mem.Set(fmt.Sprintf("other_%d", i), i, time.Minute))
+		assert.Equal(t, 4, mem.Stats().Entries)
+	}
+	assert.True(t, mem.IsExist("other_19"))
+
+	// 兼容之前版本的 Lock/Unlock
+	mem.Lock()
+	mem.Unlock()
+}
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// TokenBucket 令牌桶限流的状态存储，多实例共享同一存储时共享同一限额
+type TokenBucket interface {
+	// TakeToken 从key对应的令牌桶中取出一个令牌，rate为每秒生成的令牌数，burst为桶容量
+	// 令牌不足时同样预占令牌，并返回需要等待的时长
+	TakeToken(ctx context.Context, key string, rate float64, burst int) (wait time.Duration, err error)
+}
+
+// TokenRefunder 可归还令牌的令牌桶，预占令牌后放弃调用（如等待时ctx取消）时归还，避免占用后续调用的额度
+type TokenRefunder interface {
+	// RefundToken 归还一个 TakeToken 取出的令牌，令牌数不超过burst
+	RefundToken(ctx context.Context, key string, burst int) error
+}
+
+// MemoryTokenBucket 进程内的令牌桶，适用于单实例部署或测试
+type MemoryTokenBucket struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewMemoryTokenBucket create new MemoryTokenBucket
+func NewMemoryTokenBucket() *MemoryTokenBucket {
+	return &MemoryTokenBucket{
+		buckets: map[string]*bucket{},
+	}
+}
+
+// TakeToken 从key对应的令牌桶中取出一个令牌
+func (b *MemoryTokenBucket) TakeToken(ctx context.Context, key string, rate float64, burst int) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if rate <= 0 {
+		return 0, nil
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	current, ok := b.buckets[key]
+	if !ok {
+		current = &bucket{tokens: float64(burst), last: now}
+		b.buckets[key] = current
+	}
+	if elapsed := now.Sub(current.last); elapsed > 0 {
+		current.tokens = math.Min(float64(burst), current.tokens+elapsed.Seconds()*rate)
+		current.last = now
+	}
+	current.tokens--
+	if current.tokens >= 0 {
+		return 0, nil
+	}
+	return time.Duration(-current.tokens / rate * float64(time.Second)), nil
+}
+
+// RefundToken 归还一个令牌
+func (b *MemoryTokenBucket) RefundToken(ctx context.Context, key string, burst int) error {
+	if burst <= 0 {
+		burst = 1
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if current, ok := b.buckets[key]; ok {
+		current.tokens = math.Min(float64(burst), current.tokens+1)
+	}
+	return nil
+}
@@ -1,7 +1,9 @@
 package cache
 
 import (
+	"context"
 	"encoding/json"
+	"strconv"
 	"time"
 
 	"github.com/gomodule/redigo/redis"
@@ -110,3 +112,190 @@ func (r *Redis) Delete(key string) error {
 
 	return nil
 }
+
+// GetStringContext 获取字符串
+func (r *Redis) GetStringContext(ctx context.Context, key string) (string, error) {
+	conn, err := r.conn.GetContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	data, err := redis.Bytes(conn.Do("GET", key))
+	if err == redis.ErrNil {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	// Set 写入的是json，兼容其他程序直接写入的原始字符串
+	var reply interface{}
+	if err = json.Unmarshal(data, &reply); err != nil {
+		return string(data), nil
+	}
+	return toString(reply)
+}
+
+// SetStringContext 设置字符串，与 Set 一样以json格式写入
+func (r *Redis) SetStringContext(ctx context.Context, key, val string, timeout time.Duration) error {
+	conn, err := r.conn.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	data, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Do("SETEX", key, int64(timeout/time.Second), data)
+	return err
+}
+
+// IsExistContext 判断key是否存在
+func (r *Redis) IsExistContext(ctx context.Context, key string) (bool, error) {
+	conn, err := r.conn.GetContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	return redis.Bool(conn.Do("EXISTS", key))
+}
+
+// DeleteContext 删除
+func (r *Redis) DeleteContext(ctx context.Context, key string) error {
+	conn, err := r.conn.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Do("DEL", key)
+	return err
+}
+
+// TTLContext 获取剩余有效期
+func (r *Redis) TTLContext(ctx context.Context, key string) (time.Duration, error) {
+	conn, err := r.conn.GetContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	ms, err := redis.Int64(conn.Do("PTTL", key))
+	if err != nil {
+		return 0, err
+	}
+	// -2 表示key不存在，-1 表示未设置过期时间
+	if ms == -2 {
+		return 0, ErrNotFound
+	}
+	if ms < 0 {
+		return 0, nil
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// unlockScript 仅当锁仍由自己持有时才删除
+var unlockScript = redis.NewScript(1, `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) else return 0 end`)
+
+// TryLock 使用 SET key token NX PX ttl 实现的分布式锁
+func (r *Redis) TryLock(ctx context.Context, key string, ttl time.Duration) (func() error, bool, error) {
+	conn := r.conn.Get()
+	defer conn.Close()
+
+	token := lockToken()
+	reply, err := redis.String(conn.Do("SET", key, token, "NX", "PX", int64(ttl/time.Millisecond)))
+	if err == redis.ErrNil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if reply != "OK" {
+		return nil, false, nil
+	}
+	return func() error {
+		conn := r.conn.Get()
+		defer conn.Close()
+		_, err := unlockScript.Do(conn, key, token)
+		return err
+	}, true, nil
+}
+
+// takeTokenScript 令牌桶，状态以hash保存在key中：tokens 剩余令牌数，ts 上次更新的毫秒时间戳
+// ARGV: 每毫秒生成的令牌数、桶容量、当前毫秒时间戳、key的有效期（毫秒），返回需要等待的毫秒数
+var takeTokenScript = redis.NewScript(1, `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+if now > ts then
+	tokens = math.min(burst, tokens + (now - ts) * rate)
+	ts = now
+end
+tokens = tokens - 1
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(ts))
+redis.call("PEXPIRE", KEYS[1], ARGV[4])
+if tokens >= 0 then
+	return 0
+end
+return math.ceil(-tokens / rate)
+`)
+
+// TakeToken 使用lua脚本实现的令牌桶，多实例共享同一限额
+func (r *Redis) TakeToken(ctx context.Context, key string, rate float64, burst int) (time.Duration, error) {
+	if rate <= 0 {
+		return 0, nil
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	conn, err := r.conn.GetContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	perMillisecond := rate / 1000
+	// 桶从空到满所需的时间之后key即可过期
+	ttl := int64(float64(burst)/perMillisecond) + 1000
+	wait, err := redis.Int64(takeTokenScript.Do(conn, key, strconv.FormatFloat(perMillisecond, 'f', -1, 64), burst, time.Now().UnixNano()/int64(time.Millisecond), ttl))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(wait) * time.Millisecond, nil
+}
+
+// refundTokenScript 归还一个令牌，key已过期时不处理
+// ARGV: 桶容量
+var refundTokenScript = redis.NewScript(1, `
+local tokens = tonumber(redis.call("HGET", KEYS[1], "tokens"))
+if tokens == nil then
+	return 0
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(math.min(tonumber(ARGV[1]), tokens + 1)))
+return 1
+`)
+
+// RefundToken 归还一个 TakeToken 取出的令牌
+func (r *Redis) RefundToken(ctx context.Context, key string, burst int) error {
+	if burst <= 0 {
+		burst = 1
+	}
+	conn, err := r.conn.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = refundTokenScript.Do(conn, key, burst)
+	return err
+}
@@ -0,0 +1,184 @@
+package wechat
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/amazing-gao/wechat/v2/miniprogram"
+	miniMessage "github.com/amazing-gao/wechat/v2/miniprogram/message"
+	miniServer "github.com/amazing-gao/wechat/v2/miniprogram/server"
+	"github.com/amazing-gao/wechat/v2/officialaccount"
+	offMessage "github.com/amazing-gao/wechat/v2/officialaccount/message"
+	offServer "github.com/amazing-gao/wechat/v2/officialaccount/server"
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+// OfficialAccountMessageHandler 公众号消息处理方法，officialAccount 为接收消息的账号
+type OfficialAccountMessageHandler func(officialAccount *officialaccount.OfficialAccount, msg *offMessage.MixMessage) *offMessage.Reply
+
+// MiniProgramMessageHandler 小程序消息处理方法，miniProgram 为接收消息的账号
+type MiniProgramMessageHandler func(miniProgram *miniprogram.MiniProgram, msg *miniMessage.MiniProgramMixMessage) *miniMessage.Reply
+
+// AppIDResolver 从回调请求中解析账号AppID
+type AppIDResolver func(r *http.Request) string
+
+// PathAppIDResolver 返回从 prefix 之后的第一段路径解析AppID的 AppIDResolver，如 prefix 为 /wechat/ 时 /wechat/wx123 解析为 wx123
+func PathAppIDResolver(prefix string) AppIDResolver {
+	return func(r *http.Request) string {
+		if !strings.HasPrefix(r.URL.Path, prefix) {
+			return ""
+		}
+		appID := strings.TrimPrefix(r.URL.Path[len(prefix):], "/")
+		if idx := strings.IndexByte(appID, '/'); idx >= 0 {
+			appID = appID[:idx]
+		}
+		return appID
+	}
+}
+
+// CallbackRouterOpts 回调路由配置
+type CallbackRouterOpts struct {
+	// Resolver 解析AppID，为空时使用 PathAppIDResolver("/wechat/")
+	Resolver AppIDResolver
+	// OfficialAccountHandler 未单独设置处理方法的公众号使用的处理方法
+	OfficialAccountHandler OfficialAccountMessageHandler
+	// MiniProgramHandler 未单独设置处理方法的小程序使用的处理方法
+	MiniProgramHandler MiniProgramMessageHandler
+	// ErrorHandler 处理失败时调用，为空时记录日志并返回400，账号未注册时返回404，签名校验失败时返回403
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, appID string, err error)
+}
+
+// CallbackRouter 多账号回调路由，实现 http.Handler
+// 根据请求解析出的AppID从 Wechat 注册的账号中查找公众号或小程序，使用该账号的Token及EncodingAESKey校验、解密消息后调用对应的处理方法
+type CallbackRouter struct {
+	wc   *Wechat
+	opts CallbackRouterOpts
+
+	mu                      sync.RWMutex
+	officialAccountHandlers map[string]OfficialAccountMessageHandler
+	miniProgramHandlers     map[string]MiniProgramMessageHandler
+}
+
+// NewCallbackRouter 创建多账号回调路由，账号需通过 RegisterOfficialAccount、RegisterMiniProgram 或 LoadAccounts 注册
+func (wc *Wechat) NewCallbackRouter(opts *CallbackRouterOpts) *CallbackRouter {
+	router := &CallbackRouter{
+		wc:                      wc,
+		officialAccountHandlers: map[string]OfficialAccountMessageHandler{},
+		miniProgramHandlers:     map[string]MiniProgramMessageHandler{},
+	}
+	if opts != nil {
+		router.opts = *opts
+	}
+	if router.opts.Resolver == nil {
+		router.opts.Resolver = PathAppIDResolver("/wechat/")
+	}
+	return router
+}
+
+// HandleOfficialAccount 设置指定公众号的消息处理方法，handler为nil时恢复使用 CallbackRouterOpts.OfficialAccountHandler
+func (router *CallbackRouter) HandleOfficialAccount(appID string, handler OfficialAccountMessageHandler) {
+	router.mu.Lock()
+	defer router.mu.Unlock()
+	if handler == nil {
+		delete(router.officialAccountHandlers, appID)
+		return
+	}
+	router.officialAccountHandlers[appID] = handler
+}
+
+// HandleMiniProgram 设置指定小程序的消息处理方法，handler为nil时恢复使用 CallbackRouterOpts.MiniProgramHandler
+func (router *CallbackRouter) HandleMiniProgram(appID string, handler MiniProgramMessageHandler) {
+	router.mu.Lock()
+	defer router.mu.Unlock()
+	if handler == nil {
+		delete(router.miniProgramHandlers, appID)
+		return
+	}
+	router.miniProgramHandlers[appID] = handler
+}
+
+// ServeHTTP 将回调请求分发到对应账号
+func (router *CallbackRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	appID := router.opts.Resolver(r)
+	if appID == "" {
+		router.handleError(w, r, appID, ErrAccountNotRegistered)
+		return
+	}
+
+	if officialAccount, err := router.wc.GetOfficialAccountByAppID(appID); err == nil {
+		router.serveOfficialAccount(w, r, officialAccount)
+		return
+	}
+	miniProgram, err := router.wc.GetMiniProgramByAppID(appID)
+	if err != nil {
+		router.handleError(w, r, appID, err)
+		return
+	}
+	router.serveMiniProgram(w, r, miniProgram)
+}
+
+func (router *CallbackRouter) serveOfficialAccount(w http.ResponseWriter, r *http.Request, officialAccount *officialaccount.OfficialAccount) {
+	appID := officialAccount.GetContext().AppID
+	router.mu.RLock()
+	handler, ok := router.officialAccountHandlers[appID]
+	router.mu.RUnlock()
+	if !ok {
+		handler = router.opts.OfficialAccountHandler
+	}
+
+	srv := officialAccount.GetServer(r, w)
+	srv.SetMessageHandler(func(msg *offMessage.MixMessage) *offMessage.Reply {
+		if handler == nil {
+			return nil
+		}
+		return handler(officialAccount, msg)
+	})
+	if err := srv.Serve(); err != nil {
+		router.handleError(w, r, appID, err)
+		return
+	}
+	if err := srv.Send(); err != nil {
+		router.handleError(w, r, appID, err)
+	}
+}
+
+func (router *CallbackRouter) serveMiniProgram(w http.ResponseWriter, r *http.Request, miniProgram *miniprogram.MiniProgram) {
+	appID := miniProgram.GetContext().AppID
+	router.mu.RLock()
+	handler, ok := router.miniProgramHandlers[appID]
+	router.mu.RUnlock()
+	if !ok {
+		handler = router.opts.MiniProgramHandler
+	}
+
+	srv := miniProgram.GetServer()
+	srv.SetMessageHandler(func(msg *miniMessage.MiniProgramMixMessage) *miniMessage.Reply {
+		if handler == nil {
+			return nil
+		}
+		return handler(miniProgram, msg)
+	})
+	srv.SetErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		router.handleError(w, r, appID, err)
+	})
+	srv.ServeHTTP(w, r)
+}
+
+func (router *CallbackRouter) handleError(w http.ResponseWriter, r *http.Request, appID string, err error) {
+	if router.opts.ErrorHandler != nil {
+		router.opts.ErrorHandler(w, r, appID, err)
+		return
+	}
+	if errors.Is(err, ErrAccountNotRegistered) {
+		http.NotFound(w, r)
+		return
+	}
+	if errors.Is(err, offServer.ErrInvalidSignature) || errors.Is(err, miniServer.ErrInvalidSignature) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	util.RedactLogger(router.wc.logger).Error("handle callback failed", "appid", appID, "path", r.URL.Path, "error", err)
+	http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+}
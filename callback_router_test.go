@@ -0,0 +1,79 @@
+package wechat
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amazing-gao/wechat/v2/cache"
+	"github.com/amazing-gao/wechat/v2/miniprogram"
+	miniConfig "github.com/amazing-gao/wechat/v2/miniprogram/config"
+	miniMessage "github.com/amazing-gao/wechat/v2/miniprogram/message"
+	"github.com/amazing-gao/wechat/v2/officialaccount"
+	offConfig "github.com/amazing-gao/wechat/v2/officialaccount/config"
+	offMessage "github.com/amazing-gao/wechat/v2/officialaccount/message"
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+func TestCallbackRouter(t *testing.T) {
+	wc := NewWechat()
+	wc.SetCache(cache.NewMemory())
+	wc.RegisterOfficialAccount(&offConfig.Config{AppID: "wx1", Token: "token1"})
+	wc.RegisterOfficialAccount(&offConfig.Config{AppID: "wx2", Token: "token2"})
+	wc.RegisterMiniProgram(&miniConfig.Config{AppID: "wx3", Token: "token3"})
+
+	var miniAppID string
+	router := wc.NewCallbackRouter(&CallbackRouterOpts{
+		OfficialAccountHandler: func(officialAccount *officialaccount.OfficialAccount, msg *offMessage.MixMessage) *offMessage.Reply {
+			return &offMessage.Reply{MsgType: offMessage.MsgTypeText, MsgData: offMessage.NewText("shared " + officialAccount.GetContext().AppID)}
+		},
+		MiniProgramHandler: func(miniProgram *miniprogram.MiniProgram, msg *miniMessage.MiniProgramMixMessage) *miniMessage.Reply {
+			miniAppID = miniProgram.GetContext().AppID
+			return nil
+		},
+	})
+	router.HandleOfficialAccount("wx2", func(officialAccount *officialaccount.OfficialAccount, msg *offMessage.MixMessage) *offMessage.Reply {
+		return &offMessage.Reply{MsgType: offMessage.MsgTypeText, MsgData: offMessage.NewText("own " + msg.Content)}
+	})
+
+	body := `<xml><ToUserName>gh</ToUserName><FromUserName>o1</FromUserName><CreateTime>1</CreateTime><MsgType>text</MsgType><Content>hi</Content></xml>`
+	serve := func(path, token, contentType string) *httptest.ResponseRecorder {
+		query := "?timestamp=1&nonce=2&signature=" + util.Signature(token, "1", "2")
+		req := httptest.NewRequest(http.MethodPost, path+query, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("/wechat/wx1", "token1", "text/xml")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "shared wx1")
+
+	rec = serve("/wechat/wx2", "token2", "text/xml")
+	assert.Contains(t, rec.Body.String(), "own hi")
+
+	// 使用其他账号的Token签名
+	rec = serve("/wechat/wx2", "token1", "text/xml")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = serve("/wechat/wx3", "token1", "text/xml")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = serve("/wechat/wx3", "token3", "text/xml")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "wx3", miniAppID)
+
+	rec = serve("/wechat/wx4", "token4", "text/xml")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestPathAppIDResolver(t *testing.T) {
+	resolver := PathAppIDResolver("/callback/")
+	assert.Equal(t, "wx1", resolver(httptest.NewRequest(http.MethodPost, "/callback/wx1", nil)))
+	assert.Equal(t, "wx1", resolver(httptest.NewRequest(http.MethodPost, "/callback/wx1/event?a=b", nil)))
+	assert.Equal(t, "", resolver(httptest.NewRequest(http.MethodPost, "/other/wx1", nil)))
+}
@@ -0,0 +1,223 @@
+// Package configloader 从YAML/JSON文件及环境变量加载公众号、小程序及微信支付的配置，并在加载时校验各字段格式
+//
+// 文件格式与 wechat.FileConfigSource 相同，如：
+//
+//	official_accounts:
+//	  - app_id: wx0123456789abcdef
+//	    app_secret: 0123456789abcdef0123456789abcdef
+//	pays:
+//	  - app_id: wx0123456789abcdef
+//	    mch_id: "1230000109"
+//
+// 环境变量 {Prefix}_{OFFICIAL_ACCOUNTS|MINI_PROGRAMS|PAYS}_{序号}_{字段} 覆盖或补充文件中对应账号的字段，
+// 如 WECHAT_OFFICIAL_ACCOUNTS_0_APP_SECRET，字段名为json tag的大写形式，可用于通过环境变量注入密钥
+package configloader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/amazing-gao/wechat/v2"
+	miniConfig "github.com/amazing-gao/wechat/v2/miniprogram/config"
+	offConfig "github.com/amazing-gao/wechat/v2/officialaccount/config"
+	payConfig "github.com/amazing-gao/wechat/v2/pay/config"
+)
+
+// sections 配置文件中的账号列表及对应的配置类型
+var sections = map[string]reflect.Type{
+	"official_accounts": reflect.TypeOf(offConfig.Config{}),
+	"mini_programs":     reflect.TypeOf(miniConfig.Config{}),
+	"pays":              reflect.TypeOf(payConfig.Config{}),
+}
+
+// Loader 从文件及环境变量加载账号配置，实现 wechat.ConfigSource
+type Loader struct {
+	// Files 配置文件路径，按扩展名 .yaml/.yml/.json 解析，多个文件中的账号依次追加
+	Files []string
+	// EnvPrefix 环境变量前缀，如 WECHAT，为空时不读取环境变量
+	EnvPrefix string
+	// Environ 返回环境变量，为空时使用 os.Environ
+	Environ func() []string
+}
+
+// Load 加载并校验配置，校验失败时返回 Errors
+func (l *Loader) Load(ctx context.Context) (*wechat.AccountConfigs, error) {
+	raw := map[string][]map[string]interface{}{}
+	for _, file := range l.Files {
+		if err := decodeFile(file, raw); err != nil {
+			return nil, err
+		}
+	}
+	if l.EnvPrefix != "" {
+		environ := l.Environ
+		if environ == nil {
+			environ = os.Environ
+		}
+		if err := applyEnv(raw, l.EnvPrefix, environ()); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	configs := &wechat.AccountConfigs{}
+	if err = decoder.Decode(configs); err != nil {
+		return nil, fmt.Errorf("configloader: %w", err)
+	}
+	if err = Validate(configs); err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+// LoadFiles 从配置文件加载并校验配置
+func LoadFiles(files ...string) (*wechat.AccountConfigs, error) {
+	return (&Loader{Files: files}).Load(context.Background())
+}
+
+// decodeFile 解析配置文件并将其中的账号追加到raw
+func decodeFile(file string, raw map[string][]map[string]interface{}) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	content := map[string][]map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &content)
+	case ".json":
+		err = json.Unmarshal(data, &content)
+	default:
+		return fmt.Errorf("configloader: unsupported config file %s", file)
+	}
+	if err != nil {
+		return fmt.Errorf("configloader: decode %s: %w", file, err)
+	}
+	for section, accounts := range content {
+		typ, ok := sections[section]
+		if !ok {
+			return fmt.Errorf("configloader: decode %s: unknown section %q", file, section)
+		}
+		for _, account := range accounts {
+			normalize(typ, account)
+		}
+		raw[section] = append(raw[section], accounts...)
+	}
+	return nil
+}
+
+// normalize 将字符串类型字段中的数字转为字符串，如YAML中未加引号的 mch_id
+func normalize(typ reflect.Type, account map[string]interface{}) {
+	for field, val := range account {
+		if kind, ok := fieldKind(typ, field); !ok || kind != reflect.String {
+			continue
+		}
+		switch v := val.(type) {
+		case int:
+			account[field] = strconv.Itoa(v)
+		case float64:
+			account[field] = strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+}
+
+// applyEnv 使用环境变量覆盖raw中对应账号的字段
+func applyEnv(raw map[string][]map[string]interface{}, prefix string, environ []string) error {
+	prefix = strings.ToUpper(prefix) + "_"
+	var errs Errors
+	for _, env := range environ {
+		kv := strings.SplitN(env, "=", 2)
+		if len(kv) != 2 || !strings.HasPrefix(kv[0], prefix) {
+			continue
+		}
+		name := kv[0][len(prefix):]
+		section, index, field, ok := parseEnvName(name)
+		if !ok {
+			continue
+		}
+		fieldPath := fmt.Sprintf("%s[%d].%s", section, index, field)
+		kind, ok := fieldKind(sections[section], field)
+		if !ok {
+			errs = append(errs, &FieldError{Field: fieldPath, Message: "unknown field in environment variable " + kv[0]})
+			continue
+		}
+		val, err := convertEnv(kv[1], kind)
+		if err != nil {
+			errs = append(errs, &FieldError{Field: fieldPath, Message: fmt.Sprintf("invalid environment variable %s: %v", kv[0], err)})
+			continue
+		}
+		for len(raw[section]) <= index {
+			raw[section] = append(raw[section], map[string]interface{}{})
+		}
+		if raw[section][index] == nil {
+			raw[section][index] = map[string]interface{}{}
+		}
+		raw[section][index][field] = val
+	}
+	if len(errs) > 0 {
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+		return errs
+	}
+	return nil
+}
+
+// parseEnvName 解析 {SECTION}_{序号}_{字段} 格式的环境变量名
+func parseEnvName(name string) (section string, index int, field string, ok bool) {
+	for s := range sections {
+		sectionPrefix := strings.ToUpper(s) + "_"
+		if !strings.HasPrefix(name, sectionPrefix) {
+			continue
+		}
+		parts := strings.SplitN(name[len(sectionPrefix):], "_", 2)
+		if len(parts) != 2 {
+			return "", 0, "", false
+		}
+		index, err := strconv.Atoi(parts[0])
+		if err != nil || index < 0 {
+			return "", 0, "", false
+		}
+		return s, index, strings.ToLower(parts[1]), true
+	}
+	return "", 0, "", false
+}
+
+// fieldKind 返回配置类型中json tag为field的字段类型，仅支持字符串、布尔及整数类型的字段
+func fieldKind(typ reflect.Type, field string) (reflect.Kind, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		tag := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		if tag != field {
+			continue
+		}
+		switch kind := typ.Field(i).Type.Kind(); kind {
+		case reflect.String, reflect.Bool, reflect.Int, reflect.Int64:
+			return kind, true
+		}
+		return reflect.Invalid, false
+	}
+	return reflect.Invalid, false
+}
+
+func convertEnv(val string, kind reflect.Kind) (interface{}, error) {
+	switch kind {
+	case reflect.Bool:
+		return strconv.ParseBool(val)
+	case reflect.Int, reflect.Int64:
+		return strconv.ParseInt(val, 10, 64)
+	}
+	return val, nil
+}
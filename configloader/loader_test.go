@@ -0,0 +1,106 @@
+package configloader
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testAppID  = "wx0123456789abcdef"
+	testSecret = "0123456789abcdef0123456789abcdef"
+	testAESKey = "abcdefghijklmnopqrstuvwxyz0123456789ABCDEFG"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	certPath := writeFile(t, "apiclient_cert.p12", "cert")
+	yamlPath := writeFile(t, "accounts.yaml", `
+official_accounts:
+  - app_id: wx0123456789abcdef
+    token: token
+    encoding_aes_key: abcdefghijklmnopqrstuvwxyz0123456789ABCDEFG
+    use_stable_token: true
+pays:
+  - app_id: wx0123456789abcdef
+    mch_id: 1230000109
+    key: 0123456789abcdef0123456789abcdef
+    cert_path: `+certPath+`
+`)
+	jsonPath := writeFile(t, "accounts.json", `{"mini_programs":[{"app_id":"wxfedcba9876543210","app_secret":"`+testSecret+`"}]}`)
+
+	loader := &Loader{
+		Files:     []string{yamlPath, jsonPath},
+		EnvPrefix: "WECHAT",
+		Environ: func() []string {
+			return []string{"WECHAT_OFFICIAL_ACCOUNTS_0_APP_SECRET=" + testSecret, "WECHAT_PAYS_0_SANDBOX=true", "PATH=/bin"}
+		},
+	}
+	configs, err := loader.Load(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, configs.OfficialAccounts, 1)
+	assert.Equal(t, testSecret, configs.OfficialAccounts[0].AppSecret)
+	assert.True(t, configs.OfficialAccounts[0].UseStableToken)
+	assert.Equal(t, "wxfedcba9876543210", configs.MiniPrograms[0].AppID)
+	assert.Equal(t, "1230000109", configs.Pays[0].MchID)
+	assert.True(t, configs.Pays[0].Sandbox)
+	assert.Equal(t, certPath, configs.Pays[0].CertPath)
+}
+
+func TestLoadValidate(t *testing.T) {
+	path := writeFile(t, "accounts.yaml", `
+official_accounts:
+  - app_id: wx0123456789abcdef
+    app_secret: `+testSecret+`
+    encoding_aes_key: `+testAESKey[:42]+`
+  - app_id: wx0123456789abcdef
+    app_secret: `+testSecret+`
+    server: api.weixin.qq.com
+pays:
+  - app_id: wx0123456789abcdef
+    mch_id: abc
+    cert_path: /nonexistent/apiclient_cert.p12
+`)
+	_, err := LoadFiles(path)
+	var errs Errors
+	assert.True(t, errors.As(err, &errs))
+	fields := make([]string, len(errs))
+	for i, e := range errs {
+		fields[i] = e.Field
+	}
+	assert.Equal(t, []string{
+		"official_accounts[0].encoding_aes_key",
+		"official_accounts[0].token",
+		"official_accounts[1].server",
+		"official_accounts[1].app_id",
+		"pays[0].mch_id",
+		"pays[0].key",
+		"pays[0].cert_path",
+	}, fields)
+	assert.Contains(t, err.Error(), "must be 43 characters, got 42")
+
+	_, err = LoadFiles(writeFile(t, "accounts.json", `{"official_accounts":[{"app_idd":"wx"}]}`))
+	assert.Contains(t, err.Error(), `unknown field "app_idd"`)
+}
+
+func TestLoadEnvError(t *testing.T) {
+	loader := &Loader{
+		EnvPrefix: "WECHAT",
+		Environ: func() []string {
+			return []string{"WECHAT_OFFICIAL_ACCOUNTS_0_APP_ID=" + testAppID, "WECHAT_OFFICIAL_ACCOUNTS_0_USE_STABLE_TOKEN=yes"}
+		},
+	}
+	_, err := loader.Load(context.Background())
+	var errs Errors
+	assert.True(t, errors.As(err, &errs))
+	assert.Equal(t, "official_accounts[0].use_stable_token", errs[0].Field)
+}
@@ -0,0 +1,203 @@
+package configloader
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/amazing-gao/wechat/v2"
+	miniConfig "github.com/amazing-gao/wechat/v2/miniprogram/config"
+	offConfig "github.com/amazing-gao/wechat/v2/officialaccount/config"
+	payConfig "github.com/amazing-gao/wechat/v2/pay/config"
+)
+
+var (
+	appIDPattern     = regexp.MustCompile(`^wx[0-9a-f]{16}$`)
+	appSecretPattern = regexp.MustCompile(`^[0-9a-zA-Z]{32}$`)
+	tokenPattern     = regexp.MustCompile(`^[0-9a-zA-Z]{3,32}$`)
+	aesKeyPattern    = regexp.MustCompile(`^[0-9a-zA-Z]{43}$`)
+	mchIDPattern     = regexp.MustCompile(`^[0-9]{8,10}$`)
+	payKeyPattern    = regexp.MustCompile(`^[0-9a-zA-Z]{32}$`)
+)
+
+// FieldError 字段校验错误，Field 如 official_accounts[0].encoding_aes_key
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Error 错误信息
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Errors 汇总的字段校验错误
+type Errors []*FieldError
+
+// Error 错误信息
+func (errs Errors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("configloader: %d invalid field(s): %s", len(errs), strings.Join(messages, "; "))
+}
+
+// validator 收集字段校验错误
+type validator struct {
+	prefix string
+	errs   Errors
+}
+
+func (v *validator) addf(field, format string, args ...interface{}) {
+	v.errs = append(v.errs, &FieldError{Field: v.prefix + field, Message: fmt.Sprintf(format, args...)})
+}
+
+// required 校验必填字段，未填写时返回false
+func (v *validator) required(field, val string) bool {
+	if val == "" {
+		v.addf(field, "is required")
+		return false
+	}
+	return true
+}
+
+func (v *validator) match(field, val string, pattern *regexp.Regexp, format string) {
+	if val != "" && !pattern.MatchString(val) {
+		v.addf(field, "must be %s", format)
+	}
+}
+
+func (v *validator) url(field, val string) {
+	if val == "" {
+		return
+	}
+	u, err := url.Parse(val)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.addf(field, "must be an absolute http(s) URL")
+	}
+}
+
+func (v *validator) file(field, path string) {
+	if path == "" {
+		return
+	}
+	info, err := os.Stat(path)
+	switch {
+	case err != nil:
+		v.addf(field, "cannot access %s: %v", path, err)
+	case info.IsDir():
+		v.addf(field, "%s is a directory", path)
+	}
+}
+
+func (v *validator) account(appID, appSecret, token, encodingAESKey, server string) {
+	if v.required("app_id", appID) {
+		v.match("app_id", appID, appIDPattern, `"wx" followed by 16 lowercase hex characters`)
+	}
+	if v.required("app_secret", appSecret) {
+		v.match("app_secret", appSecret, appSecretPattern, "32 letters or digits")
+	}
+	v.match("token", token, tokenPattern, "3-32 letters or digits")
+	if encodingAESKey != "" {
+		if len(encodingAESKey) != 43 {
+			v.addf("encoding_aes_key", "must be 43 characters, got %d", len(encodingAESKey))
+		} else {
+			v.match("encoding_aes_key", encodingAESKey, aesKeyPattern, "43 letters or digits")
+		}
+		if token == "" {
+			v.addf("token", "is required when encoding_aes_key is set")
+		}
+	}
+	v.url("server", server)
+}
+
+func (v *validator) officialAccount(cfg *offConfig.Config) {
+	v.account(cfg.AppID, cfg.AppSecret, cfg.Token, cfg.EncodingAESKey, cfg.Server)
+	v.url("open_server", cfg.OpenServer)
+}
+
+func (v *validator) miniProgram(cfg *miniConfig.Config) {
+	v.account(cfg.AppID, cfg.AppSecret, cfg.Token, cfg.EncodingAESKey, cfg.Server)
+}
+
+func (v *validator) pay(cfg *payConfig.Config) {
+	if v.required("app_id", cfg.AppID) {
+		v.match("app_id", cfg.AppID, appIDPattern, `"wx" followed by 16 lowercase hex characters`)
+	}
+	if v.required("mch_id", cfg.MchID) {
+		v.match("mch_id", cfg.MchID, mchIDPattern, "8-10 digits")
+	}
+	if v.required("key", cfg.Key) {
+		v.match("key", cfg.Key, payKeyPattern, "32 letters or digits")
+	}
+	v.url("notify_url", cfg.NotifyURL)
+	v.url("server", cfg.Server)
+	v.file("cert_path", cfg.CertPath)
+}
+
+// ValidateOfficialAccount 校验公众号配置，失败时返回 Errors
+func ValidateOfficialAccount(cfg *offConfig.Config) error {
+	v := &validator{}
+	v.officialAccount(cfg)
+	return v.err()
+}
+
+// ValidateMiniProgram 校验小程序配置，失败时返回 Errors
+func ValidateMiniProgram(cfg *miniConfig.Config) error {
+	v := &validator{}
+	v.miniProgram(cfg)
+	return v.err()
+}
+
+// ValidatePay 校验微信支付配置，失败时返回 Errors
+func ValidatePay(cfg *payConfig.Config) error {
+	v := &validator{}
+	v.pay(cfg)
+	return v.err()
+}
+
+// Validate 校验全部账号配置，同一类账号的AppID不能重复，失败时返回包含全部错误的 Errors
+func Validate(configs *wechat.AccountConfigs) error {
+	v := &validator{}
+	appIDs := map[string]int{}
+	for i, cfg := range configs.OfficialAccounts {
+		v.prefix = fmt.Sprintf("official_accounts[%d].", i)
+		v.officialAccount(cfg)
+		v.unique(appIDs, "official_accounts", i, cfg.AppID)
+	}
+	appIDs = map[string]int{}
+	for i, cfg := range configs.MiniPrograms {
+		v.prefix = fmt.Sprintf("mini_programs[%d].", i)
+		v.miniProgram(cfg)
+		v.unique(appIDs, "mini_programs", i, cfg.AppID)
+	}
+	appIDs = map[string]int{}
+	for i, cfg := range configs.Pays {
+		v.prefix = fmt.Sprintf("pays[%d].", i)
+		v.pay(cfg)
+		v.unique(appIDs, "pays", i, cfg.AppID)
+	}
+	return v.err()
+}
+
+// unique 校验AppID在section中不重复
+func (v *validator) unique(appIDs map[string]int, section string, index int, appID string) {
+	if appID == "" {
+		return
+	}
+	if first, ok := appIDs[appID]; ok {
+		v.addf("app_id", "duplicates %s[%d].app_id", section, first)
+		return
+	}
+	appIDs[appID] = index
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
@@ -0,0 +1,165 @@
+// Package otelwechat 为微信接口调用及回调处理生成OpenTelemetry span
+//
+// 作为独立的module发布，不使用时核心库不会引入OpenTelemetry的依赖
+//
+//	cfg := &offConfig.Config{
+//		...
+//		Interceptors:  []util.Interceptor{otelwechat.Interceptor()},
+//		CallbackHooks: []util.CallbackHook{otelwechat.CallbackHook()},
+//	}
+package otelwechat
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+const instrumentationName = "github.com/amazing-gao/wechat/v2/contrib/otelwechat"
+
+// span 属性
+const (
+	AttrAppID      = attribute.Key("wechat.appid")
+	AttrAPI        = attribute.Key("wechat.api")
+	AttrErrCode    = attribute.Key("wechat.errcode")
+	AttrMsgType    = attribute.Key("wechat.msg_type")
+	AttrEvent      = attribute.Key("wechat.event")
+	AttrHTTPMethod = attribute.Key("http.method")
+	AttrHTTPURL    = attribute.Key("http.url")
+	AttrHTTPStatus = attribute.Key("http.status_code")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	propagators    propagation.TextMapPropagator
+}
+
+// Option 配置项
+type Option func(*config)
+
+// WithTracerProvider 设置使用的TracerProvider，默认使用 otel.GetTracerProvider()
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(cfg *config) {
+		cfg.tracerProvider = provider
+	}
+}
+
+// WithPropagators 设置向接口请求头注入span上下文使用的Propagator，默认使用 otel.GetTextMapPropagator()
+func WithPropagators(propagators propagation.TextMapPropagator) Option {
+	return func(cfg *config) {
+		cfg.propagators = propagators
+	}
+}
+
+func newConfig(opts []Option) *config {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.tracerProvider == nil {
+		cfg.tracerProvider = otel.GetTracerProvider()
+	}
+	if cfg.propagators == nil {
+		cfg.propagators = otel.GetTextMapPropagator()
+	}
+	return cfg
+}
+
+func (cfg *config) tracer() trace.Tracer {
+	return cfg.tracerProvider.Tracer(instrumentationName)
+}
+
+// Interceptor 为每次接口调用生成一个client span，属性包含appid、接口名称及errcode
+// span上下文通过Propagator注入请求头（默认为traceparent），后续拦截器返回错误未发出请求时span同样会结束
+func Interceptor(opts ...Option) util.Interceptor {
+	cfg := newConfig(opts)
+	tracer := cfg.tracer()
+	// BeforeRequest 无法返回context，按 *util.Call 保存进行中的span
+	var spans sync.Map
+	end := func(call *util.Call) {
+		value, ok := spans.LoadAndDelete(call)
+		if !ok {
+			return
+		}
+		span := value.(trace.Span)
+		if call.StatusCode != 0 {
+			span.SetAttributes(AttrHTTPStatus.Int(call.StatusCode))
+		}
+		if call.CommonError != nil {
+			span.SetAttributes(AttrErrCode.Int64(call.CommonError.ErrCode))
+		}
+		if call.Err != nil {
+			span.RecordError(call.Err)
+			span.SetStatus(codes.Error, call.Err.Error())
+		}
+		span.End()
+	}
+
+	return util.Interceptor{
+		BeforeRequest: func(ctx context.Context, call *util.Call) error {
+			ctx, span := tracer.Start(ctx, "wechat "+call.APIName,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithTimestamp(call.StartTime),
+				trace.WithAttributes(
+					AttrAppID.String(call.AppID),
+					AttrAPI.String(call.APIName),
+					AttrHTTPMethod.String(call.Method),
+					AttrHTTPURL.String(call.URL),
+				),
+			)
+			if call.Header != nil {
+				cfg.propagators.Inject(ctx, propagation.HeaderCarrier(call.Header))
+			}
+			spans.Store(call, span)
+			return nil
+		},
+		AfterResponse: func(ctx context.Context, call *util.Call) {
+			end(call)
+		},
+		OnError: func(ctx context.Context, call *util.Call, err error) {
+			// 未收到返回或未发出请求时不会调用 AfterResponse
+			if call.StatusCode == 0 {
+				end(call)
+			}
+		},
+	}
+}
+
+// CallbackHook 为每次回调处理生成一个server span，属性包含appid、消息类型及事件类型
+func CallbackHook(opts ...Option) util.CallbackHook {
+	tracer := newConfig(opts).tracer()
+	return util.CallbackHook{
+		OnStart: func(ctx context.Context, callback *util.Callback) context.Context {
+			ctx, _ = tracer.Start(ctx, "wechat callback",
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithTimestamp(callback.StartTime),
+				trace.WithAttributes(AttrAppID.String(callback.AppID)),
+			)
+			return ctx
+		},
+		OnFinish: func(ctx context.Context, callback *util.Callback, err error) {
+			span := trace.SpanFromContext(ctx)
+			api := callback.MsgType
+			if callback.Event != "" {
+				api += "." + callback.Event
+			}
+			span.SetAttributes(
+				AttrAPI.String(api),
+				AttrMsgType.String(callback.MsgType),
+				AttrEvent.String(callback.Event),
+			)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		},
+	}
+}
@@ -0,0 +1,103 @@
+package otelwechat
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (fn doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+func TestInterceptor(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	var traceparent string
+	client := doerFunc(func(req *http.Request) (*http.Response, error) {
+		traceparent = req.Header.Get("traceparent")
+		if req.URL.Path == "/down" {
+			return nil, errors.New("connection reset")
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"errcode":40003,"errmsg":"invalid openid"}`)),
+		}, nil
+	})
+	ctx := util.WithHTTPClient(context.Background(), client)
+	ctx = util.WithAppID(ctx, "wx123")
+	ctx = util.WithInterceptors(ctx, Interceptor(WithTracerProvider(provider), WithPropagators(propagation.TraceContext{})))
+
+	_, err := util.PostJSONContext(util.WithAPIName(ctx, "SendTemplate"), "http://example.com/cgi-bin/message/template/send?access_token=abc", map[string]string{})
+	assert.Nil(t, err)
+	_, err = util.HTTPGetContext(ctx, "http://example.com/down")
+	assert.NotNil(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "wechat SendTemplate", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	attrs := map[string]interface{}{}
+	for _, attr := range spans[0].Attributes() {
+		attrs[string(attr.Key)] = attr.Value.AsInterface()
+	}
+	assert.Equal(t, "wx123", attrs["wechat.appid"])
+	assert.Equal(t, "SendTemplate", attrs["wechat.api"])
+	assert.Equal(t, int64(40003), attrs["wechat.errcode"])
+	assert.Equal(t, "http://example.com/cgi-bin/message/template/send?access_token=***", attrs["http.url"])
+	// 请求头中携带接口调用span的上下文
+	assert.Contains(t, traceparent, spans[1].SpanContext().TraceID().String())
+	assert.Contains(t, traceparent, spans[1].SpanContext().SpanID().String())
+
+	assert.Equal(t, "wechat /down", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
+
+func TestInterceptorRejected(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	errRejected := errors.New("rejected")
+	ctx := util.WithInterceptors(context.Background(), Interceptor(WithTracerProvider(provider)), util.Interceptor{
+		BeforeRequest: func(ctx context.Context, call *util.Call) error {
+			return errRejected
+		},
+	})
+
+	// 后续拦截器拒绝请求时span同样结束
+	_, err := util.HTTPGetContext(ctx, "http://example.com/cgi-bin/user/info")
+	assert.Equal(t, errRejected, err)
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+}
+
+func TestCallbackHook(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	hooks := []util.CallbackHook{CallbackHook(WithTracerProvider(provider))}
+
+	callback := &util.Callback{AppID: "wx123"}
+	ctx := util.StartCallback(context.Background(), hooks, callback)
+	callback.MsgType = "event"
+	callback.Event = "subscribe"
+	util.FinishCallback(ctx, hooks, callback, nil)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "wechat callback", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), AttrAPI.String("event.subscribe"))
+}
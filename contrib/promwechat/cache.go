@@ -0,0 +1,124 @@
+package promwechat
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/amazing-gao/wechat/v2/cache"
+)
+
+// InstrumentCache 返回统计读取命中率的缓存，name作为指标的cache标签
+// c 实现了 cache.Locker 时分布式锁仍然生效
+func (m *Metrics) InstrumentCache(name string, c cache.Cache) cache.Cache {
+	return &instrumentedCache{
+		Cache:  c,
+		ctx:    cache.ToContextCache(c),
+		hit:    m.cacheRequests.WithLabelValues(name, "hit"),
+		miss:   m.cacheRequests.WithLabelValues(name, "miss"),
+		locker: lockerOf(c),
+	}
+}
+
+type instrumentedCache struct {
+	cache.Cache
+	ctx    cache.ContextCache
+	hit    prometheus.Counter
+	miss   prometheus.Counter
+	locker cache.Locker
+}
+
+func (c *instrumentedCache) Get(key string) interface{} {
+	val := c.Cache.Get(key)
+	if val == nil {
+		c.miss.Inc()
+	} else {
+		c.hit.Inc()
+	}
+	return val
+}
+
+func (c *instrumentedCache) GetStringContext(ctx context.Context, key string) (string, error) {
+	val, err := c.ctx.GetStringContext(ctx, key)
+	switch {
+	case err == nil:
+		c.hit.Inc()
+	case errors.Is(err, cache.ErrNotFound):
+		c.miss.Inc()
+	}
+	return val, err
+}
+
+func (c *instrumentedCache) SetStringContext(ctx context.Context, key, val string, timeout time.Duration) error {
+	return c.ctx.SetStringContext(ctx, key, val, timeout)
+}
+
+func (c *instrumentedCache) IsExistContext(ctx context.Context, key string) (bool, error) {
+	return c.ctx.IsExistContext(ctx, key)
+}
+
+func (c *instrumentedCache) DeleteContext(ctx context.Context, key string) error {
+	return c.ctx.DeleteContext(ctx, key)
+}
+
+func (c *instrumentedCache) TTLContext(ctx context.Context, key string) (time.Duration, error) {
+	return c.ctx.TTLContext(ctx, key)
+}
+
+// TryLock c 未实现 cache.Locker 时总是获取成功，与不使用分布式锁的行为一致
+func (c *instrumentedCache) TryLock(ctx context.Context, key string, ttl time.Duration) (func() error, bool, error) {
+	if c.locker == nil {
+		return func() error { return nil }, true, nil
+	}
+	return c.locker.TryLock(ctx, key, ttl)
+}
+
+func lockerOf(c cache.Cache) cache.Locker {
+	if locker, ok := c.(cache.Locker); ok {
+		return locker
+	}
+	return nil
+}
+
+// MemoryCollector 将 cache.Memory 的统计信息导出为指标
+type MemoryCollector struct {
+	memory    *cache.Memory
+	hits      *prometheus.Desc
+	misses    *prometheus.Desc
+	evictions *prometheus.Desc
+	entries   *prometheus.Desc
+}
+
+// NewMemoryCollector 创建 cache.Memory 的指标，namespace为空时使用 wechat
+func NewMemoryCollector(namespace, name string, memory *cache.Memory) *MemoryCollector {
+	if namespace == "" {
+		namespace = "wechat"
+	}
+	labels := prometheus.Labels{"cache": name}
+	return &MemoryCollector{
+		memory:    memory,
+		hits:      prometheus.NewDesc(namespace+"_memory_cache_hits_total", "内存缓存命中次数", nil, labels),
+		misses:    prometheus.NewDesc(namespace+"_memory_cache_misses_total", "内存缓存未命中次数", nil, labels),
+		evictions: prometheus.NewDesc(namespace+"_memory_cache_evictions_total", "内存缓存淘汰次数", nil, labels),
+		entries:   prometheus.NewDesc(namespace+"_memory_cache_entries", "内存缓存当前条目数", nil, labels),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *MemoryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.evictions
+	ch <- c.entries
+}
+
+// Collect implements prometheus.Collector
+func (c *MemoryCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.memory.Stats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(stats.Evictions))
+	ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(stats.Entries))
+}
@@ -0,0 +1,155 @@
+// Package promwechat 提供微信接口调用、回调处理、凭证刷新及缓存命中的Prometheus指标
+//
+// 作为独立的module发布，不使用时核心库不会引入Prometheus的依赖
+//
+//	metrics := promwechat.NewMetrics("")
+//	prometheus.MustRegister(metrics)
+//	cfg := &offConfig.Config{
+//		...
+//		Cache:         metrics.InstrumentCache("redis", redisCache),
+//		Interceptors:  []util.Interceptor{metrics.Interceptor()},
+//		CallbackHooks: []util.CallbackHook{metrics.CallbackHook()},
+//		RefreshHook:   metrics.RefreshHook(),
+//	}
+package promwechat
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/amazing-gao/wechat/v2/credential"
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+// 未收到返回或返回非200时 errcode 标签的取值
+const (
+	ErrCodeNetwork = "network"
+	ErrCodeHTTP    = "http_"
+)
+
+// Metrics 实现了 prometheus.Collector，需注册到 prometheus.Registerer 后使用
+type Metrics struct {
+	calls           *prometheus.CounterVec
+	callDuration    *prometheus.HistogramVec
+	callbacks       *prometheus.CounterVec
+	callbackLatency *prometheus.HistogramVec
+	refreshes       *prometheus.CounterVec
+	cacheRequests   *prometheus.CounterVec
+}
+
+// NewMetrics 创建指标，namespace为空时使用 wechat
+func NewMetrics(namespace string) *Metrics {
+	if namespace == "" {
+		namespace = "wechat"
+	}
+	return &Metrics{
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "api_calls_total",
+			Help:      "微信接口调用次数，errcode为0表示成功",
+		}, []string{"appid", "api", "errcode"}),
+		callDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "api_call_duration_seconds",
+			Help:      "微信接口调用耗时",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"appid", "api"}),
+		callbacks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "callbacks_total",
+			Help:      "接收到的微信回调次数",
+		}, []string{"appid", "msg_type", "event", "result"}),
+		callbackLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "callback_duration_seconds",
+			Help:      "微信回调处理耗时",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"appid", "msg_type"}),
+		refreshes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "token_refreshes_total",
+			Help:      "从服务端获取access_token、jsapi_ticket的次数，reason为expired、forced或background",
+		}, []string{"appid", "kind", "reason", "result"}),
+		cacheRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_requests_total",
+			Help:      "缓存读取次数，result为hit或miss",
+		}, []string{"cache", "result"}),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+}
+
+func (m *Metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.calls, m.callDuration, m.callbacks, m.callbackLatency, m.refreshes, m.cacheRequests}
+}
+
+// Interceptor 统计接口调用次数、errcode及耗时
+func (m *Metrics) Interceptor() util.Interceptor {
+	observe := func(call *util.Call) {
+		m.calls.WithLabelValues(call.AppID, call.APIName, errCodeLabel(call)).Inc()
+		m.callDuration.WithLabelValues(call.AppID, call.APIName).Observe(call.Latency.Seconds())
+	}
+	return util.Interceptor{
+		AfterResponse: func(ctx context.Context, call *util.Call) {
+			observe(call)
+		},
+		OnError: func(ctx context.Context, call *util.Call, err error) {
+			// 未收到返回时不会调用 AfterResponse
+			if call.StatusCode == 0 {
+				observe(call)
+			}
+		},
+	}
+}
+
+// CallbackHook 统计回调次数及处理耗时
+func (m *Metrics) CallbackHook() util.CallbackHook {
+	return util.CallbackHook{
+		OnFinish: func(ctx context.Context, callback *util.Callback, err error) {
+			m.callbacks.WithLabelValues(callback.AppID, callback.MsgType, callback.Event, result(err)).Inc()
+			m.callbackLatency.WithLabelValues(callback.AppID, callback.MsgType).Observe(callback.Latency.Seconds())
+		},
+	}
+}
+
+// RefreshHook 按原因统计从服务端获取凭证的次数，包括过期后获取、失效后强制刷新及后台主动刷新
+func (m *Metrics) RefreshHook() credential.RefreshHook {
+	return func(ctx context.Context, event credential.RefreshEvent) {
+		m.refreshes.WithLabelValues(event.AppID, event.Kind, event.Reason, result(event.Err)).Inc()
+	}
+}
+
+// errCodeLabel 成功时为0，errcode不为0时为errcode，未收到返回或返回非200时分别为 network、http_<状态码>
+func errCodeLabel(call *util.Call) string {
+	switch {
+	case call.StatusCode == 0:
+		return ErrCodeNetwork
+	case call.CommonError != nil:
+		return strconv.FormatInt(call.CommonError.ErrCode, 10)
+	case call.Err != nil:
+		return ErrCodeHTTP + strconv.Itoa(call.StatusCode)
+	}
+	return "0"
+}
+
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
@@ -0,0 +1,91 @@
+package promwechat
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amazing-gao/wechat/v2/cache"
+	"github.com/amazing-gao/wechat/v2/credential"
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (fn doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+func TestInterceptor(t *testing.T) {
+	metrics := NewMetrics("")
+	client := doerFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/down":
+			return nil, errors.New("connection reset")
+		case "/busy":
+			return &http.Response{StatusCode: http.StatusBadGateway, Body: http.NoBody}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"errcode":0,"errmsg":"ok"}`)),
+		}, nil
+	})
+	ctx := util.WithInterceptors(util.WithAppID(util.WithHTTPClient(context.Background(), client), "wx123"), metrics.Interceptor())
+	_, _ = util.HTTPGetContext(ctx, "http://example.com/ok")
+	_, _ = util.HTTPGetContext(ctx, "http://example.com/down")
+	_, _ = util.HTTPGetContext(ctx, "http://example.com/busy")
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.calls.WithLabelValues("wx123", "/ok", "0")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.calls.WithLabelValues("wx123", "/down", ErrCodeNetwork)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.calls.WithLabelValues("wx123", "/busy", "http_502")))
+	assert.Equal(t, 3, testutil.CollectAndCount(metrics.callDuration))
+}
+
+func TestCallbackAndRefreshHook(t *testing.T) {
+	metrics := NewMetrics("")
+	hooks := []util.CallbackHook{metrics.CallbackHook()}
+	callback := &util.Callback{AppID: "wx123", MsgType: "text"}
+	util.FinishCallback(util.StartCallback(context.Background(), hooks, callback), hooks, callback, nil)
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.callbacks.WithLabelValues("wx123", "text", "", "success")))
+
+	// 过期后获取及后台刷新均被统计
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"access_token":"ACCESS_TOKEN","expires_in":7200}`))
+	}))
+	defer ts.Close()
+	ak := credential.NewDefaultAccessToken(ts.URL, "wx123", "secret", credential.CacheKeyOfficialAccountPrefix, cache.NewMemory())
+	ak.SetRefreshHook(metrics.RefreshHook())
+	_, err := ak.GetAccessToken()
+	assert.Nil(t, err)
+	_, err = ak.RenewContext(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.refreshes.WithLabelValues("wx123", credential.RefreshKindAccessToken, credential.RefreshReasonExpired, "success")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.refreshes.WithLabelValues("wx123", credential.RefreshKindAccessToken, credential.RefreshReasonBackground, "success")))
+}
+
+func TestInstrumentCache(t *testing.T) {
+	metrics := NewMetrics("")
+	memory := cache.NewMemory()
+	defer memory.Close()
+	c := metrics.InstrumentCache("memory", memory)
+	assert.Nil(t, c.Set("key", "val", time.Minute))
+	assert.Equal(t, "val", c.Get("key"))
+	assert.Nil(t, c.Get("missing"))
+	_, err := cache.ToContextCache(c).GetStringContext(context.Background(), "key")
+	assert.Nil(t, err)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.cacheRequests.WithLabelValues("memory", "hit")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.cacheRequests.WithLabelValues("memory", "miss")))
+
+	_, ok := c.(cache.Locker)
+	assert.True(t, ok)
+	assert.Equal(t, 4, testutil.CollectAndCount(NewMemoryCollector("", "memory", memory)))
+}
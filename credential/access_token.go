@@ -1,6 +1,22 @@
 package credential
 
+import "context"
+
 // AccessTokenHandle AccessToken 接口
 type AccessTokenHandle interface {
 	GetAccessToken() (accessToken string, err error)
 }
+
+// AccessTokenContextHandle 支持context的AccessToken 接口
+type AccessTokenContextHandle interface {
+	AccessTokenHandle
+	GetAccessTokenContext(ctx context.Context) (accessToken string, err error)
+}
+
+// AccessTokenRefreshHandle 支持强制刷新的AccessToken 接口
+// 接口返回access_token失效（40001、40014、42001）时，会调用 RefreshAccessTokenContext 刷新后重试一次
+type AccessTokenRefreshHandle interface {
+	AccessTokenContextHandle
+	// RefreshAccessTokenContext 作废staleToken对应的缓存并从服务端重新获取
+	RefreshAccessTokenContext(ctx context.Context, staleToken string) (accessToken string, err error)
+}
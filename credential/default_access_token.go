@@ -1,6 +1,7 @@
 package credential
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"sync"
@@ -12,13 +13,17 @@ import (
 
 const (
 	// AccessTokenURL 企业微信获取access_token的接口
-	workAccessTokenURL = "https://qyapi.weixin.qq.com/cgi-bin/gettoken?corpid=%s&corpsecret=%s"
+	workAccessTokenURL = "%s/cgi-bin/gettoken?corpid=%s&corpsecret=%s"
+	// defaultWorkServer 企业微信接口默认域名
+	defaultWorkServer = "https://qyapi.weixin.qq.com"
 	// CacheKeyOfficialAccountPrefix 微信公众号cache key前缀
 	CacheKeyOfficialAccountPrefix = "gowechat_officialaccount_"
 	// CacheKeyMiniProgramPrefix 小程序cache key前缀
 	CacheKeyMiniProgramPrefix = "gowechat_miniprogram_"
 	// CacheKeyWorkPrefix 企业微信cache key前缀
 	CacheKeyWorkPrefix = "gowechat_work_"
+	// CacheKeyOpenPlatformPrefix 开放平台cache key前缀
+	CacheKeyOpenPlatformPrefix = "gowechat_openplatform_"
 )
 
 // DefaultAccessToken 默认AccessToken 获取
@@ -27,22 +32,29 @@ type DefaultAccessToken struct {
 	appID           string
 	appSecret       string
 	cacheKeyPrefix  string
-	cache           cache.Cache
+	cache           cache.ContextCache
 	accessTokenLock *sync.Mutex
+	httpClient      util.Doer
+	refreshHook     RefreshHook
+	refreshLock     *refreshLock
+	useStableToken  bool
+	// ttl 最近一次写入cache的有效期，供后台刷新计算下次刷新时间
+	ttl time.Duration
 }
 
 // NewDefaultAccessToken new DefaultAccessToken
-func NewDefaultAccessToken(server, appID, appSecret, cacheKeyPrefix string, cache cache.Cache) AccessTokenHandle {
-	if cache == nil {
+func NewDefaultAccessToken(server, appID, appSecret, cacheKeyPrefix string, c cache.Cache) *DefaultAccessToken {
+	if c == nil {
 		panic("cache is ineed")
 	}
 	return &DefaultAccessToken{
 		server:          server,
 		appID:           appID,
 		appSecret:       appSecret,
-		cache:           cache,
+		cache:           cache.ToContextCache(c),
 		cacheKeyPrefix:  cacheKeyPrefix,
 		accessTokenLock: new(sync.Mutex),
+		refreshLock:     newRefreshLock(c),
 	}
 }
 
@@ -54,12 +66,43 @@ type ResAccessToken struct {
 	ExpiresIn   int64  `json:"expires_in"`
 }
 
+// SetHTTPClient 设置从服务端获取access_token时使用的HTTP客户端
+func (ak *DefaultAccessToken) SetHTTPClient(client util.Doer) {
+	ak.httpClient = client
+}
+
+// SetRefreshHook 设置每次从服务端获取access_token后的回调
+func (ak *DefaultAccessToken) SetRefreshHook(hook RefreshHook) {
+	ak.refreshHook = hook
+}
+
+// SetLocker 设置多实例部署时刷新access_token使用的分布式锁
+// 默认在cache实现了 cache.Locker 时使用cache，为nil时仅使用进程内的锁
+func (ak *DefaultAccessToken) SetLocker(locker cache.Locker) {
+	ak.refreshLock.locker = locker
+}
+
+// SetUseStableToken 设置是否使用 /cgi-bin/stable_token 接口获取access_token
+// stable_token 与 /cgi-bin/token 获取的access_token相互独立，重复获取不会使之前的access_token失效
+func (ak *DefaultAccessToken) SetUseStableToken(useStableToken bool) {
+	ak.useStableToken = useStableToken
+}
+
+func (ak *DefaultAccessToken) cacheKey() string {
+	return fmt.Sprintf("%s_access_token_%s", ak.cacheKeyPrefix, ak.appID)
+}
+
 // GetAccessToken 获取access_token,先从cache中获取，没有则从服务端获取
 func (ak *DefaultAccessToken) GetAccessToken() (accessToken string, err error) {
+	return ak.GetAccessTokenContext(context.Background())
+}
+
+// GetAccessTokenContext 获取access_token,先从cache中获取，没有则从服务端获取
+func (ak *DefaultAccessToken) GetAccessTokenContext(ctx context.Context) (accessToken string, err error) {
 	// 先从cache中取
-	accessTokenCacheKey := fmt.Sprintf("%s_access_token_%s", ak.cacheKeyPrefix, ak.appID)
-	if val := ak.cache.Get(accessTokenCacheKey); val != nil {
-		return val.(string), nil
+	accessTokenCacheKey := ak.cacheKey()
+	if val, ok := cachedString(ctx, ak.cache, accessTokenCacheKey); ok {
+		return val, nil
 	}
 
 	// 加上lock，是为了防止在并发获取token时，cache刚好失效，导致从微信服务器上获取到不同token
@@ -67,22 +110,84 @@ func (ak *DefaultAccessToken) GetAccessToken() (accessToken string, err error) {
 	defer ak.accessTokenLock.Unlock()
 
 	// 双检，防止重复从微信服务器获取
-	if val := ak.cache.Get(accessTokenCacheKey); val != nil {
-		return val.(string), nil
+	if val, ok := cachedString(ctx, ak.cache, accessTokenCacheKey); ok {
+		return val, nil
 	}
 
-	// cache失效，从微信服务器获取
+	// cache失效，从微信服务器获取，多实例时只有持有分布式锁的实例获取，其他实例等待新值
+	return ak.refreshLock.do(ctx, ak.cache, accessTokenCacheKey, func(string) bool {
+		return true
+	}, func() (string, error) {
+		return ak.fetchAccessToken(ctx, accessTokenCacheKey, "", RefreshReasonExpired)
+	})
+}
+
+// RefreshAccessTokenContext 作废失效的access_token并从服务端重新获取
+// cache中的access_token已不是staleToken时，说明已被其他请求刷新，直接返回
+func (ak *DefaultAccessToken) RefreshAccessTokenContext(ctx context.Context, staleToken string) (accessToken string, err error) {
+	accessTokenCacheKey := ak.cacheKey()
+
+	ak.accessTokenLock.Lock()
+	defer ak.accessTokenLock.Unlock()
+
+	if val, ok := cachedString(ctx, ak.cache, accessTokenCacheKey); ok && val != staleToken {
+		return val, nil
+	}
+	accessToken, err = ak.refreshLock.do(ctx, ak.cache, accessTokenCacheKey, func(val string) bool {
+		return val != staleToken
+	}, func() (string, error) {
+		if err := ak.cache.DeleteContext(ctx, accessTokenCacheKey); err != nil {
+			return "", err
+		}
+		return ak.fetchAccessToken(ctx, accessTokenCacheKey, staleToken, RefreshReasonForced)
+	})
+	return
+}
+
+// RenewContext 不论cache是否有效，主动从服务端获取新的access_token，返回写入cache的有效期
+// 多实例时若其他实例已完成刷新，直接使用其结果
+func (ak *DefaultAccessToken) RenewContext(ctx context.Context) (time.Duration, error) {
+	accessTokenCacheKey := ak.cacheKey()
+
+	ak.accessTokenLock.Lock()
+	defer ak.accessTokenLock.Unlock()
+
+	current, _ := cachedString(ctx, ak.cache, accessTokenCacheKey)
+	_, err := ak.refreshLock.do(ctx, ak.cache, accessTokenCacheKey, func(val string) bool {
+		return val != current
+	}, func() (string, error) {
+		return ak.fetchAccessToken(ctx, accessTokenCacheKey, "", RefreshReasonBackground)
+	})
+	return cacheTTL(ak.ttl), err
+}
+
+// fetchAccessToken 从服务端获取access_token并写入cache，reason 为获取的原因，通过 RefreshHook 上报
+// 使用stable_token时，若获取到的仍是已失效的staleToken，则使用force_refresh模式强制刷新
+func (ak *DefaultAccessToken) fetchAccessToken(ctx context.Context, accessTokenCacheKey, staleToken, reason string) (accessToken string, err error) {
+	defer func() {
+		ak.refreshHook.emit(ctx, RefreshEvent{Kind: RefreshKindAccessToken, Reason: reason, AppID: ak.appID, CacheKey: accessTokenCacheKey, Err: err})
+	}()
+	ctx = withHTTPClient(ctx, ak.httpClient)
 	var resAccessToken ResAccessToken
-	resAccessToken, err = GetTokenFromServer(fmt.Sprintf("%s/cgi-bin/token?grant_type=client_credential&appid=%s&secret=%s", ak.server, ak.appID, ak.appSecret))
+	if ak.useStableToken {
+		stableTokenURL := fmt.Sprintf("%s/cgi-bin/stable_token", ak.server)
+		resAccessToken, err = GetStableTokenFromServerContext(ctx, stableTokenURL, ak.appID, ak.appSecret, false)
+		if err == nil && staleToken != "" && resAccessToken.AccessToken == staleToken {
+			resAccessToken, err = GetStableTokenFromServerContext(ctx, stableTokenURL, ak.appID, ak.appSecret, true)
+		}
+	} else {
+		resAccessToken, err = GetTokenFromServerContext(ctx, fmt.Sprintf("%s/cgi-bin/token?grant_type=client_credential&appid=%s&secret=%s", ak.server, ak.appID, ak.appSecret))
+	}
 	if err != nil {
 		return
 	}
 
-	expires := resAccessToken.ExpiresIn - 1500
-	err = ak.cache.Set(accessTokenCacheKey, resAccessToken.AccessToken, time.Duration(expires)*time.Second)
+	expires := time.Duration(resAccessToken.ExpiresIn-1500) * time.Second
+	err = ak.cache.SetStringContext(ctx, accessTokenCacheKey, resAccessToken.AccessToken, expires)
 	if err != nil {
 		return
 	}
+	ak.ttl = expires
 	accessToken = resAccessToken.AccessToken
 	return
 }
@@ -91,57 +196,149 @@ func (ak *DefaultAccessToken) GetAccessToken() (accessToken string, err error) {
 type WorkAccessToken struct {
 	CorpID          string
 	CorpSecret      string
+	server          string
 	cacheKeyPrefix  string
-	cache           cache.Cache
+	cache           cache.ContextCache
 	accessTokenLock *sync.Mutex
+	httpClient      util.Doer
+	refreshHook     RefreshHook
+	refreshLock     *refreshLock
+	// ttl 最近一次写入cache的有效期，供后台刷新计算下次刷新时间
+	ttl time.Duration
 }
 
 // NewWorkAccessToken new WorkAccessToken
-func NewWorkAccessToken(corpID, corpSecret, cacheKeyPrefix string, cache cache.Cache) AccessTokenHandle {
-	if cache == nil {
+func NewWorkAccessToken(corpID, corpSecret, cacheKeyPrefix string, c cache.Cache) *WorkAccessToken {
+	if c == nil {
 		panic("cache the not exist")
 	}
 	return &WorkAccessToken{
 		CorpID:          corpID,
 		CorpSecret:      corpSecret,
-		cache:           cache,
+		server:          defaultWorkServer,
+		cache:           cache.ToContextCache(c),
 		cacheKeyPrefix:  cacheKeyPrefix,
 		accessTokenLock: new(sync.Mutex),
+		refreshLock:     newRefreshLock(c),
 	}
 }
 
+// SetHTTPClient 设置从服务端获取access_token时使用的HTTP客户端
+func (ak *WorkAccessToken) SetHTTPClient(client util.Doer) {
+	ak.httpClient = client
+}
+
+// SetServer 设置获取access_token的接口域名，为空时使用 https://qyapi.weixin.qq.com
+func (ak *WorkAccessToken) SetServer(server string) {
+	if server == "" {
+		server = defaultWorkServer
+	}
+	ak.server = server
+}
+
+// SetRefreshHook 设置每次从服务端获取access_token后的回调
+func (ak *WorkAccessToken) SetRefreshHook(hook RefreshHook) {
+	ak.refreshHook = hook
+}
+
+// SetLocker 设置多实例部署时刷新access_token使用的分布式锁
+// 默认在cache实现了 cache.Locker 时使用cache，为nil时仅使用进程内的锁
+func (ak *WorkAccessToken) SetLocker(locker cache.Locker) {
+	ak.refreshLock.locker = locker
+}
+
+func (ak *WorkAccessToken) cacheKey() string {
+	return fmt.Sprintf("%s_access_token_%s", ak.cacheKeyPrefix, ak.CorpID)
+}
+
 // GetAccessToken 企业微信获取access_token,先从cache中获取，没有则从服务端获取
 func (ak *WorkAccessToken) GetAccessToken() (accessToken string, err error) {
+	return ak.GetAccessTokenContext(context.Background())
+}
+
+// GetAccessTokenContext 企业微信获取access_token,先从cache中获取，没有则从服务端获取
+func (ak *WorkAccessToken) GetAccessTokenContext(ctx context.Context) (accessToken string, err error) {
 	// 加上lock，是为了防止在并发获取token时，cache刚好失效，导致从微信服务器上获取到不同token
 	ak.accessTokenLock.Lock()
 	defer ak.accessTokenLock.Unlock()
-	accessTokenCacheKey := fmt.Sprintf("%s_access_token_%s", ak.cacheKeyPrefix, ak.CorpID)
-	val := ak.cache.Get(accessTokenCacheKey)
-	if val != nil {
-		accessToken = val.(string)
-		return
+	accessTokenCacheKey := ak.cacheKey()
+	if val, ok := cachedString(ctx, ak.cache, accessTokenCacheKey); ok {
+		return val, nil
+	}
+
+	// cache失效，从微信服务器获取，多实例时只有持有分布式锁的实例获取，其他实例等待新值
+	return ak.refreshLock.do(ctx, ak.cache, accessTokenCacheKey, func(string) bool {
+		return true
+	}, func() (string, error) {
+		return ak.fetchAccessToken(ctx, accessTokenCacheKey, RefreshReasonExpired)
+	})
+}
+
+// RefreshAccessTokenContext 作废失效的access_token并从服务端重新获取
+// cache中的access_token已不是staleToken时，说明已被其他请求刷新，直接返回
+func (ak *WorkAccessToken) RefreshAccessTokenContext(ctx context.Context, staleToken string) (accessToken string, err error) {
+	ak.accessTokenLock.Lock()
+	defer ak.accessTokenLock.Unlock()
+	accessTokenCacheKey := ak.cacheKey()
+	if val, ok := cachedString(ctx, ak.cache, accessTokenCacheKey); ok && val != staleToken {
+		return val, nil
 	}
+	accessToken, err = ak.refreshLock.do(ctx, ak.cache, accessTokenCacheKey, func(val string) bool {
+		return val != staleToken
+	}, func() (string, error) {
+		if err := ak.cache.DeleteContext(ctx, accessTokenCacheKey); err != nil {
+			return "", err
+		}
+		return ak.fetchAccessToken(ctx, accessTokenCacheKey, RefreshReasonForced)
+	})
+	return
+}
+
+// RenewContext 不论cache是否有效，主动从服务端获取新的access_token，返回写入cache的有效期
+// 多实例时若其他实例已完成刷新，直接使用其结果
+func (ak *WorkAccessToken) RenewContext(ctx context.Context) (time.Duration, error) {
+	ak.accessTokenLock.Lock()
+	defer ak.accessTokenLock.Unlock()
+	accessTokenCacheKey := ak.cacheKey()
+	current, _ := cachedString(ctx, ak.cache, accessTokenCacheKey)
+	_, err := ak.refreshLock.do(ctx, ak.cache, accessTokenCacheKey, func(val string) bool {
+		return val != current
+	}, func() (string, error) {
+		return ak.fetchAccessToken(ctx, accessTokenCacheKey, RefreshReasonBackground)
+	})
+	return cacheTTL(ak.ttl), err
+}
 
-	// cache失效，从微信服务器获取
+// fetchAccessToken 从服务端获取access_token并写入cache，reason 为获取的原因，通过 RefreshHook 上报
+func (ak *WorkAccessToken) fetchAccessToken(ctx context.Context, accessTokenCacheKey, reason string) (accessToken string, err error) {
+	defer func() {
+		ak.refreshHook.emit(ctx, RefreshEvent{Kind: RefreshKindAccessToken, Reason: reason, AppID: ak.CorpID, CacheKey: accessTokenCacheKey, Err: err})
+	}()
 	var resAccessToken ResAccessToken
-	resAccessToken, err = GetTokenFromServer(fmt.Sprintf(workAccessTokenURL, ak.CorpID, ak.CorpSecret))
+	resAccessToken, err = GetTokenFromServerContext(withHTTPClient(ctx, ak.httpClient), fmt.Sprintf(workAccessTokenURL, ak.server, ak.CorpID, ak.CorpSecret))
 	if err != nil {
 		return
 	}
 
-	expires := resAccessToken.ExpiresIn - 1500
-	err = ak.cache.Set(accessTokenCacheKey, resAccessToken.AccessToken, time.Duration(expires)*time.Second)
+	expires := time.Duration(resAccessToken.ExpiresIn-1500) * time.Second
+	err = ak.cache.SetStringContext(ctx, accessTokenCacheKey, resAccessToken.AccessToken, expires)
 	if err != nil {
 		return
 	}
+	ak.ttl = expires
 	accessToken = resAccessToken.AccessToken
 	return
 }
 
 // GetTokenFromServer 强制从微信服务器获取token
 func GetTokenFromServer(url string) (resAccessToken ResAccessToken, err error) {
+	return GetTokenFromServerContext(context.Background(), url)
+}
+
+// GetTokenFromServerContext 强制从微信服务器获取token
+func GetTokenFromServerContext(ctx context.Context, url string) (resAccessToken ResAccessToken, err error) {
 	var body []byte
-	body, err = util.HTTPGet(url)
+	body, err = util.HTTPGetContext(ctx, url)
 	if err != nil {
 		return
 	}
@@ -150,8 +347,49 @@ func GetTokenFromServer(url string) (resAccessToken ResAccessToken, err error) {
 		return
 	}
 	if resAccessToken.ErrCode != 0 {
-		err = fmt.Errorf("get access_token error : errcode=%v , errormsg=%v", resAccessToken.ErrCode, resAccessToken.ErrMsg)
+		err = util.NewError("get access_token", resAccessToken.ErrCode, resAccessToken.ErrMsg)
+		return
+	}
+	return
+}
+
+// stableTokenRequest 获取稳定版access_token的请求参数
+type stableTokenRequest struct {
+	GrantType    string `json:"grant_type"`
+	AppID        string `json:"appid"`
+	Secret       string `json:"secret"`
+	ForceRefresh bool   `json:"force_refresh"`
+}
+
+// GetStableTokenFromServer 从微信服务器获取稳定版access_token
+// forceRefresh 为true时强制刷新，之前获取的稳定版access_token立即失效，该模式每日调用次数有限
+func GetStableTokenFromServer(url, appID, appSecret string, forceRefresh bool) (resAccessToken ResAccessToken, err error) {
+	return GetStableTokenFromServerContext(context.Background(), url, appID, appSecret, forceRefresh)
+}
+
+// GetStableTokenFromServerContext 从微信服务器获取稳定版access_token
+func GetStableTokenFromServerContext(ctx context.Context, url, appID, appSecret string, forceRefresh bool) (resAccessToken ResAccessToken, err error) {
+	var body []byte
+	body, err = util.PostJSONContext(ctx, url, stableTokenRequest{
+		GrantType:    "client_credential",
+		AppID:        appID,
+		Secret:       appSecret,
+		ForceRefresh: forceRefresh,
+	})
+	if err != nil {
 		return
 	}
+	err = util.DecodeWithError(body, &resAccessToken, "GetStableAccessToken")
 	return
 }
+
+// withHTTPClient 为context设置HTTP客户端，client为空或ctx中已设置时原样返回
+func withHTTPClient(ctx context.Context, client util.Doer) context.Context {
+	if client == nil {
+		return ctx
+	}
+	if _, ok := util.HTTPClientFromContext(ctx); ok {
+		return ctx
+	}
+	return util.WithHTTPClient(ctx, client)
+}
@@ -0,0 +1,102 @@
+package credential
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/amazing-gao/wechat/v2/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultAccessTokenRefresh(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":7200}`, requests)
+	}))
+	defer ts.Close()
+
+	var events []RefreshEvent
+	ak := NewDefaultAccessToken(ts.URL, "appid", "secret", CacheKeyOfficialAccountPrefix, cache.NewMemory())
+	ak.SetRefreshHook(func(ctx context.Context, event RefreshEvent) {
+		events = append(events, event)
+	})
+
+	token, err := ak.GetAccessToken()
+	assert.Nil(t, err)
+	assert.Equal(t, "token-1", token)
+
+	token, err = ak.RefreshAccessTokenContext(context.Background(), "token-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "token-2", token)
+	// 每次从服务端获取都会回调
+	assert.Len(t, events, 2)
+	assert.Equal(t, RefreshKindAccessToken, events[0].Kind)
+	assert.Equal(t, RefreshReasonExpired, events[0].Reason)
+	assert.Equal(t, RefreshReasonForced, events[1].Reason)
+	assert.Equal(t, "appid", events[1].AppID)
+
+	// 已被其他请求刷新过，不再重复获取
+	token, err = ak.RefreshAccessTokenContext(context.Background(), "token-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "token-2", token)
+	assert.Equal(t, 2, requests)
+	assert.Len(t, events, 2)
+
+	token, err = ak.GetAccessToken()
+	assert.Nil(t, err)
+	assert.Equal(t, "token-2", token)
+
+	_, err = ak.RenewContext(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, events, 3)
+	assert.Equal(t, RefreshReasonBackground, events[2].Reason)
+}
+
+// bytesCache 以[]byte返回值的Cache，模拟部分缓存实现
+type bytesCache struct {
+	data map[string][]byte
+}
+
+func (c *bytesCache) Get(key string) interface{} {
+	if val, ok := c.data[key]; ok {
+		return val
+	}
+	return nil
+}
+
+func (c *bytesCache) Set(key string, val interface{}, timeout time.Duration) error {
+	c.data[key] = []byte(val.(string))
+	return nil
+}
+
+func (c *bytesCache) IsExist(key string) bool {
+	_, ok := c.data[key]
+	return ok
+}
+
+func (c *bytesCache) Delete(key string) error {
+	delete(c.data, key)
+	return nil
+}
+
+func TestDefaultAccessTokenBytesCache(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `{"access_token":"token","expires_in":7200}`)
+	}))
+	defer ts.Close()
+
+	ak := NewDefaultAccessToken(ts.URL, "appid", "secret", CacheKeyOfficialAccountPrefix, &bytesCache{data: map[string][]byte{}})
+	for i := 0; i < 2; i++ {
+		token, err := ak.GetAccessToken()
+		assert.Nil(t, err)
+		assert.Equal(t, "token", token)
+	}
+	assert.Equal(t, 1, requests)
+}
@@ -1,6 +1,7 @@
 package credential
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"sync"
@@ -15,19 +16,25 @@ type DefaultJsTicket struct {
 	server         string
 	appID          string
 	cacheKeyPrefix string
-	cache          cache.Cache
+	cache          cache.ContextCache
 	// jsAPITicket 读写锁 同一个AppID一个
 	jsAPITicketLock *sync.Mutex
+	httpClient      util.Doer
+	refreshHook     RefreshHook
+	refreshLock     *refreshLock
+	// ttl 最近一次写入cache的有效期，供后台刷新计算下次刷新时间
+	ttl time.Duration
 }
 
 // NewDefaultJsTicket new
-func NewDefaultJsTicket(server, appID string, cacheKeyPrefix string, cache cache.Cache) JsTicketHandle {
+func NewDefaultJsTicket(server, appID string, cacheKeyPrefix string, c cache.Cache) *DefaultJsTicket {
 	return &DefaultJsTicket{
 		server:          server,
 		appID:           appID,
-		cache:           cache,
+		cache:           cache.ToContextCache(c),
 		cacheKeyPrefix:  cacheKeyPrefix,
 		jsAPITicketLock: new(sync.Mutex),
+		refreshLock:     newRefreshLock(c),
 	}
 }
 
@@ -39,37 +46,118 @@ type ResTicket struct {
 	ExpiresIn int64  `json:"expires_in"`
 }
 
+// SetHTTPClient 设置从服务端获取ticket时使用的HTTP客户端
+func (js *DefaultJsTicket) SetHTTPClient(client util.Doer) {
+	js.httpClient = client
+}
+
+// SetRefreshHook 设置每次从服务端获取ticket后的回调
+func (js *DefaultJsTicket) SetRefreshHook(hook RefreshHook) {
+	js.refreshHook = hook
+}
+
+// SetLocker 设置多实例部署时刷新ticket使用的分布式锁
+// 默认在cache实现了 cache.Locker 时使用cache，为nil时仅使用进程内的锁
+func (js *DefaultJsTicket) SetLocker(locker cache.Locker) {
+	js.refreshLock.locker = locker
+}
+
+func (js *DefaultJsTicket) cacheKey() string {
+	return fmt.Sprintf("%s_jsapi_ticket_%s", js.cacheKeyPrefix, js.appID)
+}
+
 // GetTicket 获取jsapi_ticket
 func (js *DefaultJsTicket) GetTicket(accessToken string) (ticketStr string, err error) {
+	return js.GetTicketContext(context.Background(), accessToken)
+}
+
+// GetTicketContext 获取jsapi_ticket
+// ctx 中携带 util.TokenRefresher 时，accessToken失效会刷新后重试一次
+func (js *DefaultJsTicket) GetTicketContext(ctx context.Context, accessToken string) (ticketStr string, err error) {
 	// 先从cache中取
-	jsAPITicketCacheKey := fmt.Sprintf("%s_jsapi_ticket_%s", js.cacheKeyPrefix, js.appID)
-	if val := js.cache.Get(jsAPITicketCacheKey); val != nil {
-		return val.(string), nil
+	jsAPITicketCacheKey := js.cacheKey()
+	if val, ok := cachedString(ctx, js.cache, jsAPITicketCacheKey); ok {
+		return val, nil
 	}
 
 	js.jsAPITicketLock.Lock()
 	defer js.jsAPITicketLock.Unlock()
 
 	// 双检，防止重复从微信服务器获取
-	if val := js.cache.Get(jsAPITicketCacheKey); val != nil {
-		return val.(string), nil
+	if val, ok := cachedString(ctx, js.cache, jsAPITicketCacheKey); ok {
+		return val, nil
 	}
 
+	return js.refreshLock.do(ctx, js.cache, jsAPITicketCacheKey, func(string) bool {
+		return true
+	}, func() (string, error) {
+		return js.fetchTicket(ctx, jsAPITicketCacheKey, accessToken, RefreshReasonExpired)
+	})
+}
+
+// RefreshTicketContext 作废cache中的jsapi_ticket并从服务端重新获取
+func (js *DefaultJsTicket) RefreshTicketContext(ctx context.Context, accessToken string) (ticketStr string, err error) {
+	jsAPITicketCacheKey := js.cacheKey()
+
+	js.jsAPITicketLock.Lock()
+	defer js.jsAPITicketLock.Unlock()
+
+	staleTicket, _ := cachedString(ctx, js.cache, jsAPITicketCacheKey)
+	ticketStr, err = js.refreshLock.do(ctx, js.cache, jsAPITicketCacheKey, func(val string) bool {
+		return val != staleTicket
+	}, func() (string, error) {
+		if err := js.cache.DeleteContext(ctx, jsAPITicketCacheKey); err != nil {
+			return "", err
+		}
+		return js.fetchTicket(ctx, jsAPITicketCacheKey, accessToken, RefreshReasonForced)
+	})
+	return
+}
+
+// RenewTicketContext 不论cache是否有效，主动从服务端获取新的jsapi_ticket，返回写入cache的有效期
+func (js *DefaultJsTicket) RenewTicketContext(ctx context.Context, accessToken string) (time.Duration, error) {
+	jsAPITicketCacheKey := js.cacheKey()
+
+	js.jsAPITicketLock.Lock()
+	defer js.jsAPITicketLock.Unlock()
+
+	current, _ := cachedString(ctx, js.cache, jsAPITicketCacheKey)
+	_, err := js.refreshLock.do(ctx, js.cache, jsAPITicketCacheKey, func(val string) bool {
+		return val != current
+	}, func() (string, error) {
+		return js.fetchTicket(ctx, jsAPITicketCacheKey, accessToken, RefreshReasonBackground)
+	})
+	return cacheTTL(js.ttl), err
+}
+
+// fetchTicket 从服务端获取jsapi_ticket并写入cache，reason 为获取的原因，通过 RefreshHook 上报
+func (js *DefaultJsTicket) fetchTicket(ctx context.Context, jsAPITicketCacheKey, accessToken, reason string) (ticketStr string, err error) {
+	defer func() {
+		js.refreshHook.emit(ctx, RefreshEvent{Kind: RefreshKindJsTicket, Reason: reason, AppID: js.appID, CacheKey: jsAPITicketCacheKey, Err: err})
+	}()
 	var ticket ResTicket
-	ticket, err = GetTicketFromServer(fmt.Sprintf("%s/cgi-bin/ticket/getticket?access_token=%s&type=jsapi", js.server, accessToken))
+	ticket, err = GetTicketFromServerContext(withHTTPClient(ctx, js.httpClient), fmt.Sprintf("%s/cgi-bin/ticket/getticket?access_token=%s&type=jsapi", js.server, accessToken))
 	if err != nil {
 		return
 	}
-	expires := ticket.ExpiresIn - 1500
-	err = js.cache.Set(jsAPITicketCacheKey, ticket.Ticket, time.Duration(expires)*time.Second)
+	expires := time.Duration(ticket.ExpiresIn-1500) * time.Second
+	if err = js.cache.SetStringContext(ctx, jsAPITicketCacheKey, ticket.Ticket, expires); err != nil {
+		return
+	}
+	js.ttl = expires
 	ticketStr = ticket.Ticket
 	return
 }
 
 // GetTicketFromServer 从服务器中获取ticket
 func GetTicketFromServer(url string) (ticket ResTicket, err error) {
+	return GetTicketFromServerContext(context.Background(), url)
+}
+
+// GetTicketFromServerContext 从服务器中获取ticket
+func GetTicketFromServerContext(ctx context.Context, url string) (ticket ResTicket, err error) {
 	var response []byte
-	response, err = util.HTTPGet(url)
+	response, err = util.HTTPGetContext(ctx, url)
 	if err != nil {
 		return
 	}
@@ -78,7 +166,7 @@ func GetTicketFromServer(url string) (ticket ResTicket, err error) {
 		return
 	}
 	if ticket.ErrCode != 0 {
-		err = fmt.Errorf("getTicket Error : errcode=%d , errmsg=%s", ticket.ErrCode, ticket.ErrMsg)
+		err = util.NewError("getTicket", ticket.ErrCode, ticket.ErrMsg)
 		return
 	}
 	return
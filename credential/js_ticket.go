@@ -1,7 +1,23 @@
 package credential
 
+import "context"
+
 // JsTicketHandle js ticket获取
 type JsTicketHandle interface {
 	// GetTicket 获取ticket
 	GetTicket(accessToken string) (ticket string, err error)
 }
+
+// JsTicketContextHandle 支持context的js ticket获取
+type JsTicketContextHandle interface {
+	JsTicketHandle
+	// GetTicketContext 获取ticket
+	GetTicketContext(ctx context.Context, accessToken string) (ticket string, err error)
+}
+
+// JsTicketRefreshHandle 支持强制刷新的js ticket获取
+type JsTicketRefreshHandle interface {
+	JsTicketContextHandle
+	// RefreshTicketContext 作废缓存中的ticket并从服务端重新获取
+	RefreshTicketContext(ctx context.Context, accessToken string) (ticket string, err error)
+}
@@ -0,0 +1,68 @@
+package credential
+
+import (
+	"context"
+	"time"
+
+	"github.com/amazing-gao/wechat/v2/cache"
+)
+
+const (
+	// defaultLockTTL 刷新锁的租期，持有锁的实例异常退出时，其他实例最多等待该时长后接手刷新
+	defaultLockTTL = 10 * time.Second
+	// lockPollInterval 未获取到锁时，轮询cache等待新值的间隔
+	lockPollInterval = 50 * time.Millisecond
+)
+
+// refreshLock 多实例共享cache时，保证只有一个实例从微信服务器获取凭证，其他实例等待并读取新值
+type refreshLock struct {
+	locker cache.Locker
+	ttl    time.Duration
+}
+
+// newRefreshLock cache实现了 cache.Locker（如 cache.Redis）时默认使用其作为分布式锁
+func newRefreshLock(c cache.Cache) *refreshLock {
+	l := &refreshLock{ttl: defaultLockTTL}
+	if locker, ok := c.(cache.Locker); ok {
+		l.locker = locker
+	}
+	return l
+}
+
+// do 持有锁时调用fetch获取新值；未获取到锁时轮询cache，直到usable返回true或重新获取到锁
+// usable 判断cache中的值是否可以直接使用，例如刷新时需要与已失效的值不同
+func (l *refreshLock) do(ctx context.Context, c cache.ContextCache, cacheKey string, usable func(val string) bool, fetch func() (string, error)) (string, error) {
+	if l.locker == nil {
+		return fetch()
+	}
+	lockKey := cacheKey + "_lock"
+	for {
+		unlock, ok, err := l.locker.TryLock(ctx, lockKey, l.ttl)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			defer unlock()
+			// 获取锁期间其他实例可能已完成刷新
+			if val, ok := cachedString(ctx, c, cacheKey); ok && usable(val) {
+				return val, nil
+			}
+			return fetch()
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+		if val, ok := cachedString(ctx, c, cacheKey); ok && usable(val) {
+			return val, nil
+		}
+	}
+}
+
+// cachedString 从cache中读取字符串，key不存在、读取失败或值不是字符串时均视为未命中
+func cachedString(ctx context.Context, c cache.ContextCache, key string) (string, bool) {
+	val, err := c.GetStringContext(ctx, key)
+	return val, err == nil && val != ""
+}
@@ -0,0 +1,49 @@
+package credential
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amazing-gao/wechat/v2/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultAccessTokenDistributedLock(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		time.Sleep(100 * time.Millisecond)
+		fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":7200}`, n)
+	}))
+	defer ts.Close()
+
+	shared := cache.NewMemory()
+	defer shared.Close()
+	locker := cache.NewMemoryLocker()
+
+	// 多个实例共享cache及锁，只有一个实例从服务端获取
+	var wg sync.WaitGroup
+	tokens := make([]string, 5)
+	for i := range tokens {
+		ak := NewDefaultAccessToken(ts.URL, "appid", "secret", CacheKeyOfficialAccountPrefix, shared)
+		ak.SetLocker(locker)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			token, err := ak.GetAccessToken()
+			assert.Nil(t, err)
+			tokens[i] = token
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	for _, token := range tokens {
+		assert.Equal(t, "token-1", token)
+	}
+}
@@ -0,0 +1,52 @@
+package credential
+
+import (
+	"context"
+
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+const (
+	// RefreshKindAccessToken access_token
+	RefreshKindAccessToken = "access_token"
+	// RefreshKindJsTicket jsapi_ticket
+	RefreshKindJsTicket = "jsapi_ticket"
+)
+
+const (
+	// RefreshReasonExpired cache中不存在或已过期
+	RefreshReasonExpired = "expired"
+	// RefreshReasonForced 接口返回凭证失效后强制刷新
+	RefreshReasonForced = "forced"
+	// RefreshReasonBackground 后台在过期前主动刷新
+	RefreshReasonBackground = "background"
+)
+
+// RefreshEvent 从服务端获取access_token或jsapi_ticket的事件
+type RefreshEvent struct {
+	Kind     string // RefreshKindAccessToken 或 RefreshKindJsTicket
+	Reason   string // RefreshReasonExpired、RefreshReasonForced 或 RefreshReasonBackground
+	AppID    string // 公众号、小程序的AppID或企业微信的CorpID
+	CacheKey string // 写入的缓存key
+	Err      error  // 获取失败时的错误
+}
+
+// RefreshHook 每次从服务端获取凭证后的回调，可用于记录日志或上报监控
+// 多实例时由其他实例完成获取、本实例直接使用其结果的不会回调
+type RefreshHook func(ctx context.Context, event RefreshEvent)
+
+// emit hook不为空时调用
+func (hook RefreshHook) emit(ctx context.Context, event RefreshEvent) {
+	if hook != nil {
+		hook(ctx, event)
+	}
+}
+
+// TokenRefresher 将handle转换为 util.TokenRefresher，handle不支持强制刷新时返回nil
+func TokenRefresher(handle AccessTokenHandle) util.TokenRefresher {
+	refreshHandle, ok := handle.(AccessTokenRefreshHandle)
+	if !ok {
+		return nil
+	}
+	return refreshHandle.RefreshAccessTokenContext
+}
@@ -0,0 +1,146 @@
+package credential
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+const (
+	// defaultCacheTTL access_token、jsapi_ticket 写入cache的默认有效期，即 7200-1500 秒
+	defaultCacheTTL = 5700 * time.Second
+	// defaultRefreshAhead 在cache有效期过去该比例时刷新
+	defaultRefreshAhead = 0.8
+	// defaultMinBackoff 刷新失败后的首次重试间隔
+	defaultMinBackoff = time.Second
+	// defaultMaxBackoff 刷新失败后的最大重试间隔
+	defaultMaxBackoff = time.Minute
+)
+
+// Renewer 可以被后台主动刷新的凭证
+type Renewer interface {
+	// RenewContext 从服务端获取新的凭证并写入cache，返回写入cache的有效期
+	RenewContext(ctx context.Context) (time.Duration, error)
+}
+
+// RenewerFunc 将函数转换为Renewer
+type RenewerFunc func(ctx context.Context) (time.Duration, error)
+
+// RenewContext 调用f
+func (f RenewerFunc) RenewContext(ctx context.Context) (time.Duration, error) {
+	return f(ctx)
+}
+
+// JsTicketRenewer 返回刷新jsapi_ticket的Renewer，刷新时先通过akHandle获取access_token
+func JsTicketRenewer(js *DefaultJsTicket, akHandle AccessTokenContextHandle) Renewer {
+	return RenewerFunc(func(ctx context.Context) (time.Duration, error) {
+		accessToken, err := akHandle.GetAccessTokenContext(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return js.RenewTicketContext(ctx, accessToken)
+	})
+}
+
+// BackgroundRefresher 在凭证过期前于后台主动刷新，避免在cache过期时由业务请求同步获取带来的延迟
+type BackgroundRefresher struct {
+	Renewer Renewer
+	// Ahead 在cache有效期过去该比例时刷新，默认0.8
+	Ahead float64
+	// Jitter 刷新时间的随机抖动比例，避免多实例同时刷新，默认0.1
+	Jitter float64
+	// MinBackoff、MaxBackoff 刷新失败后按指数退避重试的间隔范围，默认1秒至1分钟
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// OnError 刷新失败时的回调
+	OnError func(err error)
+}
+
+// NewBackgroundRefresher new BackgroundRefresher
+func NewBackgroundRefresher(renewer Renewer) *BackgroundRefresher {
+	return &BackgroundRefresher{
+		Renewer:    renewer,
+		Ahead:      defaultRefreshAhead,
+		Jitter:     0.1,
+		MinBackoff: defaultMinBackoff,
+		MaxBackoff: defaultMaxBackoff,
+	}
+}
+
+// Start 在新的goroutine中运行，ctx取消后停止
+func (r *BackgroundRefresher) Start(ctx context.Context) {
+	go r.Run(ctx)
+}
+
+// Run 立即刷新一次，之后在每次过期前刷新，直到ctx取消
+func (r *BackgroundRefresher) Run(ctx context.Context) {
+	backoff := time.Duration(0)
+	for {
+		ttl, err := r.Renewer.RenewContext(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		var wait time.Duration
+		if err != nil {
+			if r.OnError != nil {
+				r.OnError(err)
+			}
+			backoff = r.nextBackoff(backoff)
+			wait = backoff
+		} else {
+			backoff = 0
+			wait = r.nextRefresh(ttl)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// nextRefresh 根据cache有效期计算下次刷新的等待时间
+func (r *BackgroundRefresher) nextRefresh(ttl time.Duration) time.Duration {
+	ahead := r.Ahead
+	if ahead <= 0 || ahead >= 1 {
+		ahead = defaultRefreshAhead
+	}
+	wait := time.Duration(float64(cacheTTL(ttl)) * ahead)
+	if r.Jitter > 0 {
+		// 在 [-Jitter, +Jitter] 范围内抖动
+		wait += time.Duration(float64(wait) * r.Jitter * (2*rand.Float64() - 1))
+	}
+	return wait
+}
+
+// nextBackoff 计算刷新失败后的下次重试间隔，在指数退避的基础上随机化
+func (r *BackgroundRefresher) nextBackoff(last time.Duration) time.Duration {
+	minBackoff, maxBackoff := r.MinBackoff, r.MaxBackoff
+	if minBackoff <= 0 {
+		minBackoff = defaultMinBackoff
+	}
+	if maxBackoff < minBackoff {
+		maxBackoff = minBackoff
+	}
+	next := last * 2
+	if next < minBackoff {
+		next = minBackoff
+	}
+	if next > maxBackoff {
+		next = maxBackoff
+	}
+	// 在 [next/2, next] 范围内随机
+	return next/2 + time.Duration(rand.Int63n(int64(next/2)+1))
+}
+
+// cacheTTL 未获取过凭证或有效期异常时使用默认有效期
+func cacheTTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return defaultCacheTTL
+	}
+	return ttl
+}
@@ -0,0 +1,82 @@
+package credential
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amazing-gao/wechat/v2/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackgroundRefresher(t *testing.T) {
+	var calls int32
+	renewer := RenewerFunc(func(ctx context.Context) (time.Duration, error) {
+		if atomic.AddInt32(&calls, 1) == 2 {
+			return 0, errors.New("system busy")
+		}
+		return 10 * time.Millisecond, nil
+	})
+
+	var errs int32
+	refresher := NewBackgroundRefresher(renewer)
+	refresher.MinBackoff = time.Millisecond
+	refresher.OnError = func(err error) {
+		atomic.AddInt32(&errs, 1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		refresher.Run(ctx)
+		close(done)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("refresher not stopped")
+	}
+	assert.True(t, atomic.LoadInt32(&calls) >= 3)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&errs))
+}
+
+func TestStableToken(t *testing.T) {
+	var forceRefresh []bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/cgi-bin/stable_token", r.URL.Path)
+		var req stableTokenRequest
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "appid", req.AppID)
+		forceRefresh = append(forceRefresh, req.ForceRefresh)
+		if req.ForceRefresh {
+			w.Write([]byte(`{"access_token":"token-2","expires_in":7200}`))
+			return
+		}
+		w.Write([]byte(`{"access_token":"token-1","expires_in":7200}`))
+	}))
+	defer ts.Close()
+
+	ak := NewDefaultAccessToken(ts.URL, "appid", "secret", CacheKeyMiniProgramPrefix, cache.NewMemory())
+	ak.SetUseStableToken(true)
+
+	ttl, err := ak.RenewContext(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 5700*time.Second, ttl)
+	token, err := ak.GetAccessToken()
+	assert.Nil(t, err)
+	assert.Equal(t, "token-1", token)
+
+	// 普通模式获取到的仍是失效的access_token时，使用force_refresh强制刷新
+	token, err = ak.RefreshAccessTokenContext(context.Background(), "token-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "token-2", token)
+	assert.Equal(t, []bool{false, false, true}, forceRefresh)
+}
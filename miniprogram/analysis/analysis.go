@@ -1,6 +1,7 @@
 package analysis
 
 import (
+	context2 "context"
 	"encoding/json"
 	"fmt"
 
@@ -43,14 +44,14 @@ func NewAnalysis(ctx *context.Context) *Analysis {
 }
 
 // fetchData 拉取统计数据
-func (analysis *Analysis) fetchData(urlStr string, body interface{}) (response []byte, err error) {
+func (analysis *Analysis) fetchData(ctx context2.Context, urlStr string, body interface{}) (response []byte, err error) {
 	var accessToken string
-	accessToken, err = analysis.GetAccessToken()
+	accessToken, err = analysis.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 	urlStr = fmt.Sprintf(analysis.Server+urlStr, accessToken)
-	response, err = util.PostJSON(urlStr, body)
+	response, err = util.PostJSONContext(util.WithIdempotent(analysis.RequestContext(ctx)), urlStr, body)
 	return
 }
 
@@ -69,12 +70,12 @@ type ResAnalysisRetain struct {
 }
 
 // getAnalysisRetain 获取用户访问小程序留存数据(日、月、周)
-func (analysis *Analysis) getAnalysisRetain(urlStr string, beginDate, endDate string) (result ResAnalysisRetain, err error) {
+func (analysis *Analysis) getAnalysisRetain(ctx context2.Context, urlStr string, beginDate, endDate string) (result ResAnalysisRetain, err error) {
 	body := map[string]string{
 		"begin_date": beginDate,
 		"end_date":   endDate,
 	}
-	response, err := analysis.fetchData(urlStr, body)
+	response, err := analysis.fetchData(ctx, urlStr, body)
 	if err != nil {
 		return
 	}
@@ -83,7 +84,7 @@ func (analysis *Analysis) getAnalysisRetain(urlStr string, beginDate, endDate st
 		return
 	}
 	if result.ErrCode != 0 {
-		err = fmt.Errorf("getAnalysisRetain error : errcode=%v , errmsg=%v", result.ErrCode, result.ErrMsg)
+		err = util.NewError("getAnalysisRetain", result.ErrCode, result.ErrMsg)
 		return
 	}
 	return
@@ -91,17 +92,32 @@ func (analysis *Analysis) getAnalysisRetain(urlStr string, beginDate, endDate st
 
 // GetAnalysisDailyRetain 获取用户访问小程序日留存
 func (analysis *Analysis) GetAnalysisDailyRetain(beginDate, endDate string) (result ResAnalysisRetain, err error) {
-	return analysis.getAnalysisRetain(getAnalysisDailyRetainURL, beginDate, endDate)
+	return analysis.GetAnalysisDailyRetainContext(context2.Background(), beginDate, endDate)
+}
+
+// GetAnalysisDailyRetainContext 获取用户访问小程序日留存
+func (analysis *Analysis) GetAnalysisDailyRetainContext(ctx context2.Context, beginDate, endDate string) (result ResAnalysisRetain, err error) {
+	return analysis.getAnalysisRetain(ctx, getAnalysisDailyRetainURL, beginDate, endDate)
 }
 
 // GetAnalysisMonthlyRetain 获取用户访问小程序月留存
 func (analysis *Analysis) GetAnalysisMonthlyRetain(beginDate, endDate string) (result ResAnalysisRetain, err error) {
-	return analysis.getAnalysisRetain(getAnalysisMonthlyRetainURL, beginDate, endDate)
+	return analysis.GetAnalysisMonthlyRetainContext(context2.Background(), beginDate, endDate)
+}
+
+// GetAnalysisMonthlyRetainContext 获取用户访问小程序月留存
+func (analysis *Analysis) GetAnalysisMonthlyRetainContext(ctx context2.Context, beginDate, endDate string) (result ResAnalysisRetain, err error) {
+	return analysis.getAnalysisRetain(ctx, getAnalysisMonthlyRetainURL, beginDate, endDate)
 }
 
 // GetAnalysisWeeklyRetain 获取用户访问小程序周留存
 func (analysis *Analysis) GetAnalysisWeeklyRetain(beginDate, endDate string) (result ResAnalysisRetain, err error) {
-	return analysis.getAnalysisRetain(getAnalysisWeeklyRetainURL, beginDate, endDate)
+	return analysis.GetAnalysisWeeklyRetainContext(context2.Background(), beginDate, endDate)
+}
+
+// GetAnalysisWeeklyRetainContext 获取用户访问小程序周留存
+func (analysis *Analysis) GetAnalysisWeeklyRetainContext(ctx context2.Context, beginDate, endDate string) (result ResAnalysisRetain, err error) {
+	return analysis.getAnalysisRetain(ctx, getAnalysisWeeklyRetainURL, beginDate, endDate)
 }
 
 // ResAnalysisDailySummary 小程序访问数据概况
@@ -117,11 +133,16 @@ type ResAnalysisDailySummary struct {
 
 // GetAnalysisDailySummary 获取用户访问小程序数据概况
 func (analysis *Analysis) GetAnalysisDailySummary(beginDate, endDate string) (result ResAnalysisDailySummary, err error) {
+	return analysis.GetAnalysisDailySummaryContext(context2.Background(), beginDate, endDate)
+}
+
+// GetAnalysisDailySummaryContext 获取用户访问小程序数据概况
+func (analysis *Analysis) GetAnalysisDailySummaryContext(ctx context2.Context, beginDate, endDate string) (result ResAnalysisDailySummary, err error) {
 	body := map[string]string{
 		"begin_date": beginDate,
 		"end_date":   endDate,
 	}
-	response, err := analysis.fetchData(getAnalysisDailySummaryURL, body)
+	response, err := analysis.fetchData(ctx, getAnalysisDailySummaryURL, body)
 	if err != nil {
 		return
 	}
@@ -131,7 +152,7 @@ func (analysis *Analysis) GetAnalysisDailySummary(beginDate, endDate string) (re
 		return
 	}
 	if result.ErrCode != 0 {
-		err = fmt.Errorf("GetAnalysisDailySummary error : errcode=%v , errmsg=%v", result.ErrCode, result.ErrMsg)
+		err = util.NewError("GetAnalysisDailySummary", result.ErrCode, result.ErrMsg)
 		return
 	}
 	return
@@ -153,12 +174,12 @@ type ResAnalysisVisitTrend struct {
 }
 
 // getAnalysisRetain 获取小程序访问数据趋势(日、月、周)
-func (analysis *Analysis) getAnalysisVisitTrend(urlStr string, beginDate, endDate string) (result ResAnalysisVisitTrend, err error) {
+func (analysis *Analysis) getAnalysisVisitTrend(ctx context2.Context, urlStr string, beginDate, endDate string) (result ResAnalysisVisitTrend, err error) {
 	body := map[string]string{
 		"begin_date": beginDate,
 		"end_date":   endDate,
 	}
-	response, err := analysis.fetchData(urlStr, body)
+	response, err := analysis.fetchData(ctx, urlStr, body)
 	if err != nil {
 		return
 	}
@@ -167,7 +188,7 @@ func (analysis *Analysis) getAnalysisVisitTrend(urlStr string, beginDate, endDat
 		return
 	}
 	if result.ErrCode != 0 {
-		err = fmt.Errorf("getAnalysisVisitTrend error : errcode=%v , errmsg=%v", result.ErrCode, result.ErrMsg)
+		err = util.NewError("getAnalysisVisitTrend", result.ErrCode, result.ErrMsg)
 		return
 	}
 	return
@@ -175,17 +196,32 @@ func (analysis *Analysis) getAnalysisVisitTrend(urlStr string, beginDate, endDat
 
 // GetAnalysisDailyVisitTrend 获取用户访问小程序数据日趋势
 func (analysis *Analysis) GetAnalysisDailyVisitTrend(beginDate, endDate string) (result ResAnalysisVisitTrend, err error) {
-	return analysis.getAnalysisVisitTrend(getAnalysisDailyVisitTrendURL, beginDate, endDate)
+	return analysis.GetAnalysisDailyVisitTrendContext(context2.Background(), beginDate, endDate)
+}
+
+// GetAnalysisDailyVisitTrendContext 获取用户访问小程序数据日趋势
+func (analysis *Analysis) GetAnalysisDailyVisitTrendContext(ctx context2.Context, beginDate, endDate string) (result ResAnalysisVisitTrend, err error) {
+	return analysis.getAnalysisVisitTrend(ctx, getAnalysisDailyVisitTrendURL, beginDate, endDate)
 }
 
 // GetAnalysisMonthlyVisitTrend 获取用户访问小程序数据月趋势
 func (analysis *Analysis) GetAnalysisMonthlyVisitTrend(beginDate, endDate string) (result ResAnalysisVisitTrend, err error) {
-	return analysis.getAnalysisVisitTrend(getAnalysisMonthlyVisitTrendURL, beginDate, endDate)
+	return analysis.GetAnalysisMonthlyVisitTrendContext(context2.Background(), beginDate, endDate)
+}
+
+// GetAnalysisMonthlyVisitTrendContext 获取用户访问小程序数据月趋势
+func (analysis *Analysis) GetAnalysisMonthlyVisitTrendContext(ctx context2.Context, beginDate, endDate string) (result ResAnalysisVisitTrend, err error) {
+	return analysis.getAnalysisVisitTrend(ctx, getAnalysisMonthlyVisitTrendURL, beginDate, endDate)
 }
 
 // GetAnalysisWeeklyVisitTrend 获取用户访问小程序数据周趋势
 func (analysis *Analysis) GetAnalysisWeeklyVisitTrend(beginDate, endDate string) (result ResAnalysisVisitTrend, err error) {
-	return analysis.getAnalysisVisitTrend(getAnalysisWeeklyVisitTrendURL, beginDate, endDate)
+	return analysis.GetAnalysisWeeklyVisitTrendContext(context2.Background(), beginDate, endDate)
+}
+
+// GetAnalysisWeeklyVisitTrendContext 获取用户访问小程序数据周趋势
+func (analysis *Analysis) GetAnalysisWeeklyVisitTrendContext(ctx context2.Context, beginDate, endDate string) (result ResAnalysisVisitTrend, err error) {
+	return analysis.getAnalysisVisitTrend(ctx, getAnalysisWeeklyVisitTrendURL, beginDate, endDate)
 }
 
 // UserPortraitItem 用户画像项目
@@ -216,11 +252,16 @@ type ResAnalysisUserPortrait struct {
 
 // GetAnalysisUserPortrait 获取小程序新增或活跃用户的画像分布数据
 func (analysis *Analysis) GetAnalysisUserPortrait(beginDate, endDate string) (result ResAnalysisUserPortrait, err error) {
+	return analysis.GetAnalysisUserPortraitContext(context2.Background(), beginDate, endDate)
+}
+
+// GetAnalysisUserPortraitContext 获取小程序新增或活跃用户的画像分布数据
+func (analysis *Analysis) GetAnalysisUserPortraitContext(ctx context2.Context, beginDate, endDate string) (result ResAnalysisUserPortrait, err error) {
 	body := map[string]string{
 		"begin_date": beginDate,
 		"end_date":   endDate,
 	}
-	response, err := analysis.fetchData(getAnalysisUserPortraitURL, body)
+	response, err := analysis.fetchData(ctx, getAnalysisUserPortraitURL, body)
 	if err != nil {
 		return
 	}
@@ -229,7 +270,7 @@ func (analysis *Analysis) GetAnalysisUserPortrait(beginDate, endDate string) (re
 		return
 	}
 	if result.ErrCode != 0 {
-		err = fmt.Errorf("GetAnalysisUserPortrait error : errcode=%v , errmsg=%v", result.ErrCode, result.ErrMsg)
+		err = util.NewError("GetAnalysisUserPortrait", result.ErrCode, result.ErrMsg)
 		return
 	}
 	return
@@ -257,11 +298,16 @@ type ResAnalysisVisitDistribution struct {
 
 // GetAnalysisVisitDistribution 获取用户小程序访问分布数据
 func (analysis *Analysis) GetAnalysisVisitDistribution(beginDate, endDate string) (result ResAnalysisVisitDistribution, err error) {
+	return analysis.GetAnalysisVisitDistributionContext(context2.Background(), beginDate, endDate)
+}
+
+// GetAnalysisVisitDistributionContext 获取用户小程序访问分布数据
+func (analysis *Analysis) GetAnalysisVisitDistributionContext(ctx context2.Context, beginDate, endDate string) (result ResAnalysisVisitDistribution, err error) {
 	body := map[string]string{
 		"begin_date": beginDate,
 		"end_date":   endDate,
 	}
-	response, err := analysis.fetchData(getAnalysisVisitDistributionURL, body)
+	response, err := analysis.fetchData(ctx, getAnalysisVisitDistributionURL, body)
 	if err != nil {
 		return
 	}
@@ -270,7 +316,7 @@ func (analysis *Analysis) GetAnalysisVisitDistribution(beginDate, endDate string
 		return
 	}
 	if result.ErrCode != 0 {
-		err = fmt.Errorf("GetAnalysisVisitDistribution error : errcode=%v , errmsg=%v", result.ErrCode, result.ErrMsg)
+		err = util.NewError("GetAnalysisVisitDistribution", result.ErrCode, result.ErrMsg)
 		return
 	}
 	return
@@ -297,11 +343,16 @@ type ResAnalysisVisitPage struct {
 
 // GetAnalysisVisitPage 获取小程序页面访问数据
 func (analysis *Analysis) GetAnalysisVisitPage(beginDate, endDate string) (result ResAnalysisVisitPage, err error) {
+	return analysis.GetAnalysisVisitPageContext(context2.Background(), beginDate, endDate)
+}
+
+// GetAnalysisVisitPageContext 获取小程序页面访问数据
+func (analysis *Analysis) GetAnalysisVisitPageContext(ctx context2.Context, beginDate, endDate string) (result ResAnalysisVisitPage, err error) {
 	body := map[string]string{
 		"begin_date": beginDate,
 		"end_date":   endDate,
 	}
-	response, err := analysis.fetchData(getAnalysisVisitPageURL, body)
+	response, err := analysis.fetchData(ctx, getAnalysisVisitPageURL, body)
 	if err != nil {
 		return
 	}
@@ -310,7 +361,7 @@ func (analysis *Analysis) GetAnalysisVisitPage(beginDate, endDate string) (resul
 		return
 	}
 	if result.ErrCode != 0 {
-		err = fmt.Errorf("GetAnalysisVisitPage error : errcode=%v , errmsg=%v", result.ErrCode, result.ErrMsg)
+		err = util.NewError("GetAnalysisVisitPage", result.ErrCode, result.ErrMsg)
 		return
 	}
 	return
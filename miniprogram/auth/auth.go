@@ -44,14 +44,14 @@ func (auth *Auth) Code2Session(jsCode string) (result ResCode2Session, err error
 // Code2SessionContext 登录凭证校验。
 func (auth *Auth) Code2SessionContext(ctx context2.Context, jsCode string) (result ResCode2Session, err error) {
 	var response []byte
-	if response, err = util.HTTPGetContext(ctx, fmt.Sprintf("%s/sns/jscode2session?appid=%s&secret=%s&js_code=%s&grant_type=authorization_code", auth.Server, auth.AppID, auth.AppSecret, jsCode)); err != nil {
+	if response, err = util.HTTPGetContext(auth.RequestContext(ctx), fmt.Sprintf("%s/sns/jscode2session?appid=%s&secret=%s&js_code=%s&grant_type=authorization_code", auth.Server, auth.AppID, auth.AppSecret, jsCode)); err != nil {
 		return
 	}
 	if err = json.Unmarshal(response, &result); err != nil {
 		return
 	}
 	if result.ErrCode != 0 {
-		err = fmt.Errorf("Code2Session error : errcode=%v , errmsg=%v", result.ErrCode, result.ErrMsg)
+		err = util.NewError("Code2Session", result.ErrCode, result.ErrMsg)
 		return
 	}
 	return
@@ -73,10 +73,10 @@ func (auth *Auth) CheckEncryptedDataContext(ctx context2.Context, encryptedMsgHa
 	var (
 		at string
 	)
-	if at, err = auth.GetAccessToken(); err != nil {
+	if at, err = auth.GetAccessTokenContext(ctx); err != nil {
 		return
 	}
-	if response, err = util.HTTPPostContext(ctx, fmt.Sprintf("%s/wxa/business/checkencryptedmsg?access_token=%s", auth.Server, at), "encrypted_msg_hash="+encryptedMsgHash); err != nil {
+	if response, err = util.HTTPPostContext(util.WithIdempotent(auth.RequestContext(ctx)), fmt.Sprintf("%s/wxa/business/checkencryptedmsg?access_token=%s", auth.Server, at), "encrypted_msg_hash="+encryptedMsgHash); err != nil {
 		return
 	}
 	if err = util.DecodeWithError(response, &result, "CheckEncryptedDataAuth"); err != nil {
@@ -1,6 +1,7 @@
 package content
 
 import (
+	context2 "context"
 	"fmt"
 
 	"github.com/amazing-gao/wechat/v2/miniprogram/context"
@@ -20,11 +21,18 @@ func NewContent(ctx *context.Context) *Content {
 // CheckText 检测文字
 // @text 需要检测的文字
 func (content *Content) CheckText(text string) error {
-	accessToken, err := content.GetAccessToken()
+	return content.CheckTextContext(context2.Background(), text)
+}
+
+// CheckTextContext 检测文字
+// @text 需要检测的文字
+func (content *Content) CheckTextContext(ctx context2.Context, text string) error {
+	accessToken, err := content.GetAccessTokenContext(ctx)
 	if err != nil {
 		return err
 	}
-	response, err := util.PostJSON(
+	response, err := util.PostJSONContext(
+		util.WithIdempotent(content.RequestContext(ctx)),
 		fmt.Sprintf("%s/wxa/msg_sec_check?access_token=%s", content.Server, accessToken),
 		map[string]string{
 			"content": text,
@@ -40,11 +48,19 @@ func (content *Content) CheckText(text string) error {
 // 所传参数为要检测的图片文件的绝对路径，图片格式支持PNG、JPEG、JPG、GIF, 像素不超过 750 x 1334，同时文件大小以不超过 300K 为宜，否则可能报错
 // @media 图片文件的绝对路径
 func (content *Content) CheckImage(media string) error {
-	accessToken, err := content.GetAccessToken()
+	return content.CheckImageContext(context2.Background(), media)
+}
+
+// CheckImageContext 检测图片
+// 所传参数为要检测的图片文件的绝对路径，图片格式支持PNG、JPEG、JPG、GIF, 像素不超过 750 x 1334，同时文件大小以不超过 300K 为宜，否则可能报错
+// @media 图片文件的绝对路径
+func (content *Content) CheckImageContext(ctx context2.Context, media string) error {
+	accessToken, err := content.GetAccessTokenContext(ctx)
 	if err != nil {
 		return err
 	}
-	response, err := util.PostFile(
+	response, err := util.PostFileContext(
+		util.WithIdempotent(content.RequestContext(ctx)),
 		"media",
 		media,
 		fmt.Sprintf("%s/wxa/img_sec_check?access_token=%s", content.Server, accessToken),
@@ -1,12 +1,84 @@
 package context
 
 import (
+	"context"
+	"sync"
+
+	"github.com/amazing-gao/wechat/v2/cache"
 	"github.com/amazing-gao/wechat/v2/credential"
 	"github.com/amazing-gao/wechat/v2/miniprogram/config"
+	"github.com/amazing-gao/wechat/v2/util"
 )
 
 // Context struct
 type Context struct {
 	*config.Config
 	credential.AccessTokenHandle
+
+	// APIFailover 当前账号使用的域名容灾，未启用时为nil
+	APIFailover *util.Failover
+
+	dedupOnce sync.Once
+	dedup     *cache.MsgDedup
+}
+
+// RequestContext 返回发起请求使用的context，携带当前账号配置的HTTP客户端、重试策略、限流
+// 以及access_token失效时用于刷新并重试的 util.TokenRefresher，parent 中已设置时以 parent 为准
+// 账号配置的拦截器追加在 parent 中已有的拦截器之后
+func (ctx *Context) RequestContext(parent context.Context) context.Context {
+	if _, ok := util.HTTPClientFromContext(parent); !ok {
+		if client := ctx.HTTPDoer(); client != nil {
+			parent = util.WithHTTPClient(parent, client)
+		}
+	}
+	if _, ok := util.RetryPolicyFromContext(parent); !ok && ctx.RetryPolicy != nil {
+		parent = util.WithRetryPolicy(parent, ctx.RetryPolicy)
+	}
+	if _, ok := util.LimiterFromContext(parent); !ok && ctx.RateLimiter != nil {
+		parent = util.WithLimiter(parent, ctx.RateLimiter.ForApp(ctx.AppID))
+	}
+	if util.AppIDFromContext(parent) == "" {
+		parent = util.WithAppID(parent, ctx.AppID)
+	}
+	parent = util.WithInterceptors(parent, ctx.Interceptors...)
+	if _, ok := util.TokenRefresherFromContext(parent); !ok {
+		if refresher := credential.TokenRefresher(ctx.AccessTokenHandle); refresher != nil {
+			parent = util.WithTokenRefresher(parent, refresher)
+		}
+	}
+	return parent
+}
+
+// HTTPDoer 返回调用接口使用的HTTP客户端，启用域名容灾时为 APIFailover，否则为 HTTPClient
+func (ctx *Context) HTTPDoer() util.Doer {
+	if ctx.APIFailover != nil {
+		return ctx.APIFailover
+	}
+	return ctx.HTTPClient
+}
+
+// GetAccessTokenContext 获取access_token
+// AccessTokenHandle 实现了 credential.AccessTokenContextHandle 时，parent 会传递给获取token的请求
+func (ctx *Context) GetAccessTokenContext(parent context.Context) (string, error) {
+	if handle, ok := ctx.AccessTokenHandle.(credential.AccessTokenContextHandle); ok {
+		return handle.GetAccessTokenContext(ctx.RequestContext(parent))
+	}
+	return ctx.GetAccessToken()
+}
+
+// GetLogger 获取当前账号使用的Logger，access_token、消息内容等敏感字段已脱敏
+func (ctx *Context) GetLogger() util.Logger {
+	return util.RedactLogger(ctx.Logger)
+}
+
+// GetMsgDedup 获取回调消息去重，未设置Cache或 MsgDedupTTL 不大于0时返回nil
+// 同一账号的所有请求共用首次获取时创建的 cache.MsgDedup
+func (ctx *Context) GetMsgDedup() *cache.MsgDedup {
+	if ctx.Cache == nil || ctx.MsgDedupTTL <= 0 {
+		return nil
+	}
+	ctx.dedupOnce.Do(func() {
+		ctx.dedup = cache.NewMsgDedup(ctx.Cache, ctx.MsgDedupTTL)
+	})
+	return ctx.dedup
 }
@@ -22,6 +22,8 @@ const (
 	MsgTypeMiniProgramPage = "miniprogrampage"
 	// MsgTypeEvent 表示事件推送消息
 	MsgTypeEvent = "event"
+	// MsgTypeTransfer 将消息转发到客服[限回复]
+	MsgTypeTransfer = "transfer_customer_service"
 )
 
 const (
@@ -40,13 +42,33 @@ const (
 
 // CommonToken 消息中通用的结构
 type CommonToken struct {
-	XMLName      xml.Name `xml:"xml"`
+	XMLName      xml.Name `xml:"xml" json:"-"`
 	ToUserName   string   `xml:"ToUserName"`
 	FromUserName string   `xml:"FromUserName"`
 	CreateTime   int64    `xml:"CreateTime"`
 	MsgType      MsgType  `xml:"MsgType"`
 }
 
+// SetToUserName set ToUserName
+func (msg *CommonToken) SetToUserName(toUserName string) {
+	msg.ToUserName = toUserName
+}
+
+// SetFromUserName set FromUserName
+func (msg *CommonToken) SetFromUserName(fromUserName string) {
+	msg.FromUserName = fromUserName
+}
+
+// SetCreateTime set createTime
+func (msg *CommonToken) SetCreateTime(createTime int64) {
+	msg.CreateTime = createTime
+}
+
+// SetMsgType set MsgType
+func (msg *CommonToken) SetMsgType(msgType MsgType) {
+	msg.MsgType = msgType
+}
+
 // MiniProgramMixMessage 小程序回调的消息结构
 type MiniProgramMixMessage struct {
 	CommonToken
@@ -87,3 +109,12 @@ type EncryptedMsg struct {
 	ToUserName   string   `xml:"ToUserName" json:"toUserName"`
 	EncryptedMsg string   `xml:"Encrypt" json:"Encrypt"`
 }
+
+// ResponseEncryptedMsg 安全模式下回复的消息体
+type ResponseEncryptedMsg struct {
+	XMLName      struct{} `xml:"xml" json:"-"`
+	EncryptedMsg string   `xml:"Encrypt" json:"Encrypt"`
+	MsgSignature string   `xml:"MsgSignature" json:"MsgSignature"`
+	Timestamp    int64    `xml:"TimeStamp" json:"TimeStamp"`
+	Nonce        string   `xml:"Nonce" json:"Nonce"`
+}
@@ -1,16 +1,13 @@
 package message
 
 import (
+	context2 "context"
 	"fmt"
 
 	"github.com/amazing-gao/wechat/v2/miniprogram/context"
 	"github.com/amazing-gao/wechat/v2/util"
 )
 
-const (
-	customerSendMessage = "https://api.weixin.qq.com"
-)
-
 // Manager 消息管理者，可以发送消息
 type Manager struct {
 	*context.Context
@@ -110,12 +107,17 @@ func NewCustomerMiniprogrampageMessage(toUser, title, pagepath, thumbMediaID str
 
 // Send 发送客服消息
 func (manager *Manager) Send(msg *CustomerMessage) error {
-	accessToken, err := manager.Context.GetAccessToken()
+	return manager.SendContext(context2.Background(), msg)
+}
+
+// SendContext 发送客服消息
+func (manager *Manager) SendContext(ctx context2.Context, msg *CustomerMessage) error {
+	accessToken, err := manager.Context.GetAccessTokenContext(ctx)
 	if err != nil {
 		return err
 	}
 	uri := fmt.Sprintf("%s/cgi-bin/message/custom/send?access_token=%s", manager.Server, accessToken)
-	response, err := util.PostJSON(uri, msg)
+	response, err := util.PostJSONContext(manager.RequestContext(ctx), uri, msg)
 	if err != nil {
 		return err
 	}
@@ -13,3 +13,24 @@ type Reply struct {
 	MsgType MsgType
 	MsgData interface{}
 }
+
+// TransferCustomer 将消息转发到客服，小程序仅支持该类型的被动回复，其他消息需通过客服消息接口发送
+type TransferCustomer struct {
+	CommonToken
+
+	TransInfo *TransInfo `xml:"TransInfo,omitempty" json:"TransInfo,omitempty"`
+}
+
+// TransInfo 转发到指定客服
+type TransInfo struct {
+	KfAccount string `xml:"KfAccount" json:"KfAccount"`
+}
+
+// NewTransferCustomer 转发到客服的回复，kfAccount为空时由微信分配客服
+func NewTransferCustomer(kfAccount string) *TransferCustomer {
+	tc := new(TransferCustomer)
+	if kfAccount != "" {
+		tc.TransInfo = &TransInfo{KfAccount: kfAccount}
+	}
+	return tc
+}
@@ -1,6 +1,9 @@
 package miniprogram
 
 import (
+	context2 "context"
+	"sync"
+
 	"github.com/amazing-gao/wechat/v2/credential"
 	"github.com/amazing-gao/wechat/v2/miniprogram/analysis"
 	"github.com/amazing-gao/wechat/v2/miniprogram/auth"
@@ -9,27 +12,39 @@ import (
 	"github.com/amazing-gao/wechat/v2/miniprogram/context"
 	"github.com/amazing-gao/wechat/v2/miniprogram/encryptor"
 	"github.com/amazing-gao/wechat/v2/miniprogram/message"
+	"github.com/amazing-gao/wechat/v2/miniprogram/openapi"
 	"github.com/amazing-gao/wechat/v2/miniprogram/qrcode"
 	"github.com/amazing-gao/wechat/v2/miniprogram/server"
 	"github.com/amazing-gao/wechat/v2/miniprogram/shortlink"
 	"github.com/amazing-gao/wechat/v2/miniprogram/subscribe"
 	"github.com/amazing-gao/wechat/v2/miniprogram/urllink"
 	"github.com/amazing-gao/wechat/v2/miniprogram/werun"
+	"github.com/amazing-gao/wechat/v2/util"
 )
 
 // MiniProgram 微信小程序相关API
 type MiniProgram struct {
 	ctx *context.Context
+
+	mu            sync.Mutex
+	stopRefresher context2.CancelFunc
 }
 
 // NewMiniProgram 实例化小程序API
 func NewMiniProgram(cfg *config.Config) *MiniProgram {
-	defaultAkHandle := credential.NewDefaultAccessToken(cfg.Server, cfg.AppID, cfg.AppSecret, credential.CacheKeyMiniProgramPrefix, cfg.Cache)
-	ctx := &context.Context{
-		Config:            cfg,
-		AccessTokenHandle: defaultAkHandle,
+	if cfg.Server == "" {
+		cfg.Server = "https://api.weixin.qq.com"
+	}
+	ctx := &context.Context{Config: cfg, APIFailover: cfg.Failover}
+	if ctx.APIFailover == nil && cfg.EnableFailover {
+		ctx.APIFailover = util.NewAPIFailover(cfg.Server, cfg.BackupServers, cfg.HTTPClient)
 	}
-	return &MiniProgram{ctx}
+	defaultAkHandle := credential.NewDefaultAccessToken(cfg.Server, cfg.AppID, cfg.AppSecret, credential.CacheKeyMiniProgramPrefix, cfg.Cache)
+	defaultAkHandle.SetHTTPClient(ctx.HTTPDoer())
+	defaultAkHandle.SetRefreshHook(cfg.RefreshHook)
+	defaultAkHandle.SetUseStableToken(cfg.UseStableToken)
+	ctx.AccessTokenHandle = defaultAkHandle
+	return &MiniProgram{ctx: ctx}
 }
 
 // SetAccessTokenHandle 自定义access_token获取方式
@@ -37,6 +52,34 @@ func (miniProgram *MiniProgram) SetAccessTokenHandle(accessTokenHandle credentia
 	miniProgram.ctx.AccessTokenHandle = accessTokenHandle
 }
 
+// StartTokenRefresher 在后台于过期前主动刷新access_token，ctx取消或调用 StopTokenRefresher 后停止
+// 重复调用时先停止之前启动的刷新；自定义的AccessTokenHandle未实现 credential.Renewer 时不生效
+func (miniProgram *MiniProgram) StartTokenRefresher(ctx context2.Context) {
+	renewer, ok := miniProgram.ctx.AccessTokenHandle.(credential.Renewer)
+	if !ok {
+		return
+	}
+	ctx, cancel := context2.WithCancel(ctx)
+	miniProgram.mu.Lock()
+	if miniProgram.stopRefresher != nil {
+		miniProgram.stopRefresher()
+	}
+	miniProgram.stopRefresher = cancel
+	miniProgram.mu.Unlock()
+
+	credential.NewBackgroundRefresher(renewer).Start(ctx)
+}
+
+// StopTokenRefresher 停止 StartTokenRefresher 启动的后台刷新，未启动时不处理
+func (miniProgram *MiniProgram) StopTokenRefresher() {
+	miniProgram.mu.Lock()
+	defer miniProgram.mu.Unlock()
+	if miniProgram.stopRefresher != nil {
+		miniProgram.stopRefresher()
+		miniProgram.stopRefresher = nil
+	}
+}
+
 // GetContext get Context
 func (miniProgram *MiniProgram) GetContext() *context.Context {
 	return miniProgram.ctx
@@ -97,3 +140,8 @@ func (miniProgram *MiniProgram) GetURLLink() *urllink.URLLink {
 func (miniProgram *MiniProgram) GetShortLink() *shortlink.ShortLink {
 	return shortlink.NewShortLink(miniProgram.ctx)
 }
+
+// GetOpenAPI openApi管理接口，查询及重置接口调用次数
+func (miniProgram *MiniProgram) GetOpenAPI() *openapi.OpenAPI {
+	return openapi.NewOpenAPI(miniProgram.ctx)
+}
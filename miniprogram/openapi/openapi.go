@@ -0,0 +1,77 @@
+package openapi
+
+import (
+	context2 "context"
+	"fmt"
+
+	"github.com/amazing-gao/wechat/v2/miniprogram/context"
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+// OpenAPI openApi管理
+type OpenAPI struct {
+	*context.Context
+}
+
+// NewOpenAPI 实例化
+func NewOpenAPI(ctx *context.Context) *OpenAPI {
+	return &OpenAPI{ctx}
+}
+
+// Quota 接口调用次数
+type Quota struct {
+	DailyLimit int64 `json:"daily_limit"` // 当天该账号可调用该接口的次数
+	Used       int64 `json:"used"`        // 当天已经调用的次数
+	Remain     int64 `json:"remain"`      // 当天剩余调用次数
+}
+
+// resQuota 查询接口调用次数的返回结果
+type resQuota struct {
+	util.CommonError
+	Quota Quota `json:"quota"`
+}
+
+// ClearQuota 重置小程序所有接口的调用次数
+func (openAPI *OpenAPI) ClearQuota() error {
+	return openAPI.ClearQuotaContext(context2.Background())
+}
+
+// ClearQuotaContext 重置小程序所有接口的调用次数
+func (openAPI *OpenAPI) ClearQuotaContext(ctx context2.Context) error {
+	ak, err := openAPI.GetAccessTokenContext(ctx)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/cgi-bin/clear_quota?access_token=%s", openAPI.Server, ak)
+	data, err := util.PostJSONContext(openAPI.RequestContext(ctx), url, map[string]string{
+		"appid": openAPI.AppID,
+	})
+	if err != nil {
+		return err
+	}
+	return util.DecodeWithCommonError(data, "ClearQuota")
+}
+
+// GetQuota 查询接口调用次数，cgiPath 为接口路径，如 /wxa/msg_sec_check
+// 可配合 ratelimit.Limiter.SetCount 校准本地计数
+func (openAPI *OpenAPI) GetQuota(cgiPath string) (Quota, error) {
+	return openAPI.GetQuotaContext(context2.Background(), cgiPath)
+}
+
+// GetQuotaContext 查询接口调用次数
+func (openAPI *OpenAPI) GetQuotaContext(ctx context2.Context, cgiPath string) (quota Quota, err error) {
+	ak, err := openAPI.GetAccessTokenContext(ctx)
+	if err != nil {
+		return
+	}
+	url := fmt.Sprintf("%s/cgi-bin/openapi/quota/get?access_token=%s", openAPI.Server, ak)
+	data, err := util.PostJSONContext(util.WithIdempotent(openAPI.RequestContext(ctx)), url, map[string]string{
+		"cgi_path": cgiPath,
+	})
+	if err != nil {
+		return
+	}
+	var res resQuota
+	err = util.DecodeWithError(data, &res, "GetQuota")
+	return res.Quota, err
+}
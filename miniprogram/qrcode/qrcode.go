@@ -1,6 +1,7 @@
 package qrcode
 
 import (
+	context2 "context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -53,16 +54,16 @@ type QRCoder struct {
 }
 
 // fetchCode 请求并返回二维码二进制数据
-func (qrCode *QRCode) fetchCode(urlStr string, body interface{}) (response []byte, err error) {
+func (qrCode *QRCode) fetchCode(ctx context2.Context, urlStr string, body interface{}) (response []byte, err error) {
 	var accessToken string
-	accessToken, err = qrCode.GetAccessToken()
+	accessToken, err = qrCode.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 
 	urlStr = fmt.Sprintf(qrCode.Server+urlStr, accessToken)
 	var contentType string
-	response, contentType, err = util.PostJSONWithRespContentType(urlStr, body)
+	response, contentType, err = util.PostJSONWithRespContentTypeContext(qrCode.RequestContext(ctx), urlStr, body)
 	if err != nil {
 		return
 	}
@@ -71,7 +72,7 @@ func (qrCode *QRCode) fetchCode(urlStr string, body interface{}) (response []byt
 		var result util.CommonError
 		err = json.Unmarshal(response, &result)
 		if err == nil && result.ErrCode != 0 {
-			err = fmt.Errorf("fetchCode error : errcode=%v , errmsg=%v", result.ErrCode, result.ErrMsg)
+			err = util.NewError("fetchCode", result.ErrCode, result.ErrMsg)
 			return nil, err
 		}
 	}
@@ -86,17 +87,35 @@ func (qrCode *QRCode) fetchCode(urlStr string, body interface{}) (response []byt
 // CreateWXAQRCode 获取小程序二维码，适用于需要的码数量较少的业务场景
 // 文档地址： https://developers.weixin.qq.com/miniprogram/dev/api/createWXAQRCode.html
 func (qrCode *QRCode) CreateWXAQRCode(coderParams QRCoder) (response []byte, err error) {
-	return qrCode.fetchCode(createWXAQRCodeURL, coderParams)
+	return qrCode.CreateWXAQRCodeContext(context2.Background(), coderParams)
+}
+
+// CreateWXAQRCodeContext 获取小程序二维码，适用于需要的码数量较少的业务场景
+// 文档地址： https://developers.weixin.qq.com/miniprogram/dev/api/createWXAQRCode.html
+func (qrCode *QRCode) CreateWXAQRCodeContext(ctx context2.Context, coderParams QRCoder) (response []byte, err error) {
+	return qrCode.fetchCode(ctx, createWXAQRCodeURL, coderParams)
 }
 
 // GetWXACode 获取小程序码，适用于需要的码数量较少的业务场景
 // 文档地址： https://developers.weixin.qq.com/miniprogram/dev/api/getWXACode.html
 func (qrCode *QRCode) GetWXACode(coderParams QRCoder) (response []byte, err error) {
-	return qrCode.fetchCode(getWXACodeURL, coderParams)
+	return qrCode.GetWXACodeContext(context2.Background(), coderParams)
+}
+
+// GetWXACodeContext 获取小程序码，适用于需要的码数量较少的业务场景
+// 文档地址： https://developers.weixin.qq.com/miniprogram/dev/api/getWXACode.html
+func (qrCode *QRCode) GetWXACodeContext(ctx context2.Context, coderParams QRCoder) (response []byte, err error) {
+	return qrCode.fetchCode(ctx, getWXACodeURL, coderParams)
 }
 
 // GetWXACodeUnlimit 获取小程序码，适用于需要的码数量极多的业务场景
 // 文档地址： https://developers.weixin.qq.com/miniprogram/dev/api/getWXACodeUnlimit.html
 func (qrCode *QRCode) GetWXACodeUnlimit(coderParams QRCoder) (response []byte, err error) {
-	return qrCode.fetchCode(getWXACodeUnlimitURL, coderParams)
+	return qrCode.GetWXACodeUnlimitContext(context2.Background(), coderParams)
+}
+
+// GetWXACodeUnlimitContext 获取小程序码，适用于需要的码数量极多的业务场景
+// 文档地址： https://developers.weixin.qq.com/miniprogram/dev/api/getWXACodeUnlimit.html
+func (qrCode *QRCode) GetWXACodeUnlimitContext(ctx context2.Context, coderParams QRCoder) (response []byte, err error) {
+	return qrCode.fetchCode(ctx, getWXACodeUnlimitURL, coderParams)
 }
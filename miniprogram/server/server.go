@@ -2,26 +2,43 @@ package server
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
-	"io"
-	"log"
+	"io/ioutil"
 	"net/http"
+	"strconv"
 
+	"github.com/amazing-gao/wechat/v2/cache"
 	"github.com/amazing-gao/wechat/v2/miniprogram/context"
 	"github.com/amazing-gao/wechat/v2/miniprogram/message"
 	"github.com/amazing-gao/wechat/v2/util"
 )
 
+// ErrInvalidSignature 请求签名校验失败
+var ErrInvalidSignature = errors.New("invalid signature")
+
 // Server struct
+// 实现 http.Handler，请求相关的状态不保存在 Server 中，设置处理方法后可并发处理所有回调请求
 type (
 	Server struct {
 		*context.Context
 		messageHandler MessageHandler
+		skipValidate   bool
+		errorHandler   func(w http.ResponseWriter, r *http.Request, err error)
 	}
 
+	// MessageHandler 消息处理方法，返回nil时回复success
 	MessageHandler func(mixMessage *message.MiniProgramMixMessage) *message.Reply
 )
 
+// replyMessage 被动回复的消息，如 message.TransferCustomer
+type replyMessage interface {
+	SetToUserName(toUserName string)
+	SetFromUserName(fromUserName string)
+	SetCreateTime(createTime int64)
+	SetMsgType(msgType message.MsgType)
+}
+
 // miniProgramMixMessage 小程序回调的消息结构
 type miniProgramMixMessage struct {
 	message.CommonToken
@@ -118,21 +135,41 @@ func (srv *Server) SetMessageHandler(messageHandler MessageHandler) {
 	srv.messageHandler = messageHandler
 }
 
-// ServeHTTP 小程序消息处理中间件
+// SkipValidate 是否跳过签名校验
+func (srv *Server) SkipValidate(skip bool) {
+	srv.skipValidate = skip
+}
+
+// SetErrorHandler 设置处理失败时的回调，未设置时签名校验失败返回403，消息解析或解密失败等返回400
+func (srv *Server) SetErrorHandler(errorHandler func(w http.ResponseWriter, r *http.Request, err error)) {
+	srv.errorHandler = errorHandler
+}
+
+// ServeHTTP 小程序消息处理，实现 http.Handler
 // GET 验证消息的确来自微信服务器
-// POST 处理客服消息
-func (srv *Server) ServeHTTP(request *http.Request, writer http.ResponseWriter) {
-	if request.Method == "GET" {
-		srv.messageHandleValid(request, writer)
-	} else if request.Method == "POST" {
-		srv.messageHandle(request, writer)
-	} else {
-		srv.messageHandleNotSupport(request, writer)
+// POST 处理客服消息及事件推送，并被动回复
+func (srv *Server) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	var err error
+	switch request.Method {
+	case http.MethodGet:
+		err = srv.messageHandleValid(writer, request)
+	case http.MethodPost:
+		err = srv.messageHandle(writer, request)
+	default:
+		srv.messageHandleNotSupport(writer, request)
 	}
+	if err != nil {
+		srv.handleError(writer, request, err)
+	}
+}
+
+// validate 校验请求签名
+func (srv *Server) validate(signature string, params ...string) bool {
+	return srv.skipValidate || signature == util.Signature(append(params, srv.Token)...)
 }
 
 // messageHandleValid 消息校验
-func (srv *Server) messageHandleValid(request *http.Request, writer http.ResponseWriter) {
+func (srv *Server) messageHandleValid(writer http.ResponseWriter, request *http.Request) error {
 	var (
 		query     = request.URL.Query()
 		nonce     = query.Get("nonce")
@@ -141,109 +178,231 @@ func (srv *Server) messageHandleValid(request *http.Request, writer http.Respons
 		timestamp = query.Get("timestamp")
 	)
 
-	if signature == util.Signature(srv.Token, timestamp, nonce) {
-		writer.Write([]byte(echostr))
-	} else {
-		writer.Write([]byte("invalid"))
+	if !srv.validate(signature, timestamp, nonce) {
+		return ErrInvalidSignature
 	}
+	_, err := writer.Write([]byte(echostr))
+	return err
 }
 
-func (srv *Server) messageHandle(request *http.Request, writer http.ResponseWriter) {
+func (srv *Server) messageHandle(writer http.ResponseWriter, request *http.Request) (err error) {
 	var (
-		err          error
 		contentType  = request.Header.Get("Content-Type")
 		query        = request.URL.Query()
 		nonce        = query.Get("nonce")
 		timestamp    = query.Get("timestamp")
 		encryptType  = query.Get("encrypt_type")
 		msgSignature = query.Get("msg_signature")
+		callback     = &util.Callback{AppID: srv.AppID}
+		ctx          = util.StartCallback(request.Context(), srv.CallbackHooks, callback)
 	)
+	defer func() {
+		util.FinishCallback(ctx, srv.CallbackHooks, callback, err)
+	}()
+
+	body, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		return err
+	}
 
-	for ok := true; ok; ok = !ok {
-		var (
-			encryptData          []byte
-			encryptMsg           message.EncryptedMsg
-			mixMessage           miniProgramMixMessage
-			mixMessage1          miniProgramMixMessage1
-			mixMessageReader     io.Reader
-			subscribeMessageList []message.SubscribeMessageList
-		)
-
-		if encryptType == "aes" {
-			// 解析密文消息
-			// 校验消息是否合法
-			// 解密密文消息
-			if err = Decode(contentType, request.Body, &encryptMsg); err != nil {
-				break
-			} else if util.Signature(srv.Token, timestamp, nonce, encryptMsg.EncryptedMsg) != msgSignature {
-				err = fmt.Errorf("invalid message")
-				break
-			} else if _, encryptData, err = util.DecryptMsg(srv.AppID, encryptMsg.EncryptedMsg, srv.EncodingAESKey); err != nil {
-				break
-			}
-
-			mixMessageReader = bytes.NewBuffer(encryptData)
-		} else {
-			mixMessageReader = request.Body
+	var random, data []byte
+	if encryptType == "aes" {
+		// 解析密文消息
+		// 校验消息是否合法
+		// 解密密文消息
+		var encryptMsg message.EncryptedMsg
+		if err = Decode(contentType, bytes.NewReader(body), &encryptMsg); err != nil {
+			return fmt.Errorf("decode encrypted message: %w", err)
 		}
+		if !srv.validate(msgSignature, timestamp, nonce, encryptMsg.EncryptedMsg) {
+			return ErrInvalidSignature
+		}
+		if random, data, err = util.DecryptMsg(srv.AppID, encryptMsg.EncryptedMsg, srv.EncodingAESKey); err != nil {
+			return fmt.Errorf("decrypt message: %w", err)
+		}
+	} else {
+		if !srv.validate(query.Get("signature"), timestamp, nonce) {
+			return ErrInvalidSignature
+		}
+		data = body
+	}
 
-		// 解析到明文结构
-		if err = Decode(contentType, mixMessageReader, &mixMessage); err != nil {
-			if IsXML(contentType) {
-				break
-			}
+	mixMessage, err := parseMessage(contentType, data)
+	if err != nil {
+		return err
+	}
+	callback.MsgType = string(mixMessage.MsgType)
+	callback.Event = string(mixMessage.Event)
 
-			if IsJSON(contentType) {
-				// 若 "List" 只有一个对象，则只返回对象本身；若 "List" 多于一个对象，则返回一个包含所有对象的数组。
-				if err = Decode(contentType, bytes.NewBuffer(encryptData), &mixMessage1); err != nil {
-					break
-				}
+	// 微信在5秒内未收到回复时会重试，重复投递的消息不再处理，回复首次处理的结果
+	var (
+		response  []byte
+		duplicate bool
+		dedupKey  string
+		dedup     = srv.GetMsgDedup()
+	)
+	if dedup != nil {
+		dedupKey = cache.MsgDedupKey(srv.AppID, mixMessage.MsgID, mixMessage.FromUserName, mixMessage.CreateTime, string(mixMessage.Event))
+		var first bool
+		if response, first = dedup.Begin(dedupKey); !first {
+			duplicate = true
+			srv.GetLogger().Info("duplicate msg", "appid", srv.AppID, "key", dedupKey)
+		}
+	}
+	if !duplicate {
+		if response, err = srv.handleMessage(contentType, mixMessage, dedup, dedupKey); err != nil {
+			return err
+		}
+	}
+
+	return srv.send(writer, contentType, encryptType == "aes", random, timestamp, nonce, response)
+}
 
-				mixMessage.List = []message.SubscribeMessageList{mixMessage1.List}
-			}
+// handleMessage 调用处理方法并构建回复，处理方法panic或构建回复失败时释放去重认领，微信重试时重新处理该消息
+func (srv *Server) handleMessage(contentType string, mixMessage *message.MiniProgramMixMessage, dedup *cache.MsgDedup, dedupKey string) (response []byte, err error) {
+	finished := false
+	defer func() {
+		if dedup != nil && !finished {
+			dedup.Abort(dedupKey)
 		}
+	}()
 
-		// 处理订阅消息json和xml格式不同的情况
-		if IsXML(contentType) {
-			switch mixMessage.Event {
-			case message.EventSubscribeSent:
-				subscribeMessageList = mixMessage.SubscribeMsgSentEvent.List
-			case message.EventSubscribePopup:
-				subscribeMessageList = mixMessage.SubscribeMsgPopupEvent.List
-			case message.EventSubscribeChange:
-				subscribeMessageList = mixMessage.SubscribeMsgChangeEvent.List
-			}
-		} else {
-			subscribeMessageList = mixMessage.List
+	var reply *message.Reply
+	if srv.messageHandler != nil {
+		reply = srv.messageHandler(mixMessage)
+	}
+	if response, err = buildResponse(contentType, mixMessage, reply); err != nil {
+		return nil, err
+	}
+	if dedup != nil {
+		dedup.Finish(dedupKey, response)
+	}
+	finished = true
+	return response, nil
+}
+
+// parseMessage 解析明文消息
+func parseMessage(contentType string, data []byte) (*message.MiniProgramMixMessage, error) {
+	var (
+		mixMessage           miniProgramMixMessage
+		mixMessage1          miniProgramMixMessage1
+		subscribeMessageList []message.SubscribeMessageList
+	)
+
+	// 解析到明文结构
+	if err := Decode(contentType, bytes.NewReader(data), &mixMessage); err != nil {
+		if !IsJSON(contentType) {
+			return nil, err
 		}
+		// 若 "List" 只有一个对象，则只返回对象本身；若 "List" 多于一个对象，则返回一个包含所有对象的数组。
+		if err = Decode(contentType, bytes.NewReader(data), &mixMessage1); err != nil {
+			return nil, err
+		}
+		mixMessage.List = []message.SubscribeMessageList{mixMessage1.List}
+	}
+
+	// 处理订阅消息json和xml格式不同的情况
+	if IsXML(contentType) {
+		switch mixMessage.Event {
+		case message.EventSubscribeSent:
+			subscribeMessageList = mixMessage.SubscribeMsgSentEvent.List
+		case message.EventSubscribePopup:
+			subscribeMessageList = mixMessage.SubscribeMsgPopupEvent.List
+		case message.EventSubscribeChange:
+			subscribeMessageList = mixMessage.SubscribeMsgChangeEvent.List
+		}
+	} else {
+		subscribeMessageList = mixMessage.List
+	}
+
+	return &message.MiniProgramMixMessage{
+		CommonToken:  mixMessage.CommonToken,
+		MsgID:        mixMessage.MsgID,
+		Content:      mixMessage.Content,
+		PicURL:       mixMessage.PicURL,
+		MediaID:      mixMessage.MediaID,
+		Title:        mixMessage.Title,
+		AppID:        mixMessage.AppID,
+		PagePath:     mixMessage.PagePath,
+		ThumbURL:     mixMessage.ThumbURL,
+		ThumbMediaID: mixMessage.ThumbMediaID,
+		Event:        mixMessage.Event,
+		SessionFrom:  mixMessage.SessionFrom,
+		List:         subscribeMessageList,
+	}, nil
+}
+
+// buildResponse 按请求的格式序列化被动回复，reply为nil时返回nil
+func buildResponse(contentType string, mixMessage *message.MiniProgramMixMessage, reply *message.Reply) ([]byte, error) {
+	if reply == nil {
+		return nil, nil
+	}
+	msgData, ok := reply.MsgData.(replyMessage)
+	if !ok {
+		return nil, message.ErrUnsupportedReply
+	}
+	msgData.SetToUserName(mixMessage.FromUserName)
+	msgData.SetFromUserName(mixMessage.ToUserName)
+	msgData.SetCreateTime(util.GetCurrTS())
+	msgData.SetMsgType(reply.MsgType)
+	return Encode(contentType, msgData)
+}
+
+// send 回复消息，没有回复时回复success，安全模式下对回复加密
+func (srv *Server) send(writer http.ResponseWriter, contentType string, encrypted bool, random []byte, timestamp, nonce string, response []byte) error {
+	if len(response) == 0 {
+		_, err := writer.Write([]byte("success"))
+		return err
+	}
+	srv.GetLogger().Debug("response msg", "appid", srv.AppID, "body", response)
 
-		// 处理消息
-		srv.messageHandler(&message.MiniProgramMixMessage{
-			CommonToken:  mixMessage.CommonToken,
-			MsgID:        mixMessage.MsgID,
-			Content:      mixMessage.Content,
-			PicURL:       mixMessage.PicURL,
-			MediaID:      mixMessage.MediaID,
-			Title:        mixMessage.Title,
-			AppID:        mixMessage.AppID,
-			PagePath:     mixMessage.PagePath,
-			ThumbURL:     mixMessage.ThumbURL,
-			ThumbMediaID: mixMessage.ThumbMediaID,
-			Event:        mixMessage.Event,
-			SessionFrom:  mixMessage.SessionFrom,
-			List:         subscribeMessageList,
+	if encrypted {
+		encryptedMsg, err := util.EncryptMsg(random, response, srv.AppID, srv.EncodingAESKey)
+		if err != nil {
+			return err
+		}
+		ts, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			ts = util.GetCurrTS()
+			timestamp = strconv.FormatInt(ts, 10)
+		}
+		response, err = Encode(contentType, &message.ResponseEncryptedMsg{
+			EncryptedMsg: string(encryptedMsg),
+			MsgSignature: util.Signature(srv.Token, timestamp, nonce, string(encryptedMsg)),
+			Timestamp:    ts,
+			Nonce:        nonce,
 		})
+		if err != nil {
+			return err
+		}
 	}
 
-	if err != nil {
-		log.Printf("miniprogram.Message.Handle.Error: %s\n", err)
+	if IsJSON(contentType) {
+		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	} else {
+		writer.Header().Set("Content-Type", "application/xml; charset=utf-8")
 	}
+	_, err := writer.Write(response)
+	return err
+}
 
-	writer.Write([]byte("success"))
+// handleError 处理失败时调用 SetErrorHandler 设置的回调，或记录日志并返回错误状态码
+func (srv *Server) handleError(writer http.ResponseWriter, request *http.Request, err error) {
+	if srv.errorHandler != nil {
+		srv.errorHandler(writer, request, err)
+		return
+	}
+	if errors.Is(err, ErrInvalidSignature) {
+		srv.GetLogger().Warn("validate signature failed", "appid", srv.AppID)
+		http.Error(writer, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	srv.GetLogger().Error("handle message failed", "appid", srv.AppID, "error", err)
+	http.Error(writer, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 }
 
 // messageHandleNotSupport 不支持的消息
-func (srv *Server) messageHandleNotSupport(request *http.Request, writer http.ResponseWriter) {
+func (srv *Server) messageHandleNotSupport(writer http.ResponseWriter, request *http.Request) {
 	writer.WriteHeader(http.StatusMethodNotAllowed)
 	writer.Write([]byte("Method Not Allowed"))
 }
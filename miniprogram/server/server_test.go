@@ -0,0 +1,78 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amazing-gao/wechat/v2/miniprogram/config"
+	"github.com/amazing-gao/wechat/v2/miniprogram/context"
+	"github.com/amazing-gao/wechat/v2/miniprogram/message"
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+var _ http.Handler = (*Server)(nil)
+
+const testAESKey = "abcdefghijklmnopqrstuvwxyz0123456789ABCDEFG"
+
+func TestServeHTTP(t *testing.T) {
+	ctx := &context.Context{Config: &config.Config{AppID: "wx1", Token: "token", EncodingAESKey: testAESKey}}
+	srv := NewServer(ctx)
+	srv.SetMessageHandler(func(msg *message.MiniProgramMixMessage) *message.Reply {
+		if msg.Content != "kf" {
+			return nil
+		}
+		return &message.Reply{MsgType: message.MsgTypeTransfer, MsgData: message.NewTransferCustomer("kf1@test")}
+	})
+	query := "?timestamp=1&nonce=2&signature=" + util.Signature("token", "1", "2")
+	serve := func(method, target, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodGet, "/"+query+"&echostr=hello", "", "")
+	assert.Equal(t, "hello", rec.Body.String())
+
+	// 明文模式被动回复转发客服消息
+	body := `<xml><ToUserName>gh</ToUserName><FromUserName>o1</FromUserName><CreateTime>1</CreateTime><MsgType>text</MsgType><Content>kf</Content></xml>`
+	rec = serve(http.MethodPost, "/"+query, "text/xml", body)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "<ToUserName>o1</ToUserName><FromUserName>gh</FromUserName>")
+	assert.Contains(t, rec.Body.String(), "<MsgType>transfer_customer_service</MsgType><TransInfo><KfAccount>kf1@test</KfAccount></TransInfo>")
+
+	rec = serve(http.MethodPost, "/"+query, "application/json", `{"ToUserName":"gh","FromUserName":"o1","CreateTime":1,"MsgType":"text","Content":"hi"}`)
+	assert.Equal(t, "success", rec.Body.String())
+
+	// 安全模式解密请求并加密回复
+	encrypted, err := util.EncryptMsg([]byte("1234567890123456"), []byte(`{"ToUserName":"gh","FromUserName":"o1","CreateTime":1,"MsgType":"text","Content":"kf"}`), "wx1", testAESKey)
+	assert.Nil(t, err)
+	aesQuery := query + "&encrypt_type=aes&msg_signature=" + util.Signature("token", "1", "2", string(encrypted))
+	rec = serve(http.MethodPost, "/"+aesQuery, "application/json", `{"ToUserName":"gh","Encrypt":"`+string(encrypted)+`"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp message.ResponseEncryptedMsg
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, util.Signature("token", "1", "2", resp.EncryptedMsg), resp.MsgSignature)
+	_, plain, err := util.DecryptMsg("wx1", resp.EncryptedMsg, testAESKey)
+	assert.Nil(t, err)
+	assert.Contains(t, string(plain), `"MsgType":"transfer_customer_service","TransInfo":{"KfAccount":"kf1@test"}`)
+
+	// 签名或解密失败时返回错误状态码
+	rec = serve(http.MethodPost, "/?timestamp=1&nonce=2&signature=bad", "text/xml", body)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	rec = serve(http.MethodPost, "/"+query+"&encrypt_type=aes&msg_signature="+util.Signature("token", "1", "2", "bad"), "application/json", `{"Encrypt":"bad"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var handled error
+	srv.SetErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		handled = err
+	})
+	serve(http.MethodGet, "/?timestamp=1&nonce=2&signature=bad&echostr=hello", "", "")
+	assert.Equal(t, ErrInvalidSignature, handled)
+}
@@ -24,3 +24,11 @@ func Decode(contentType string, reader io.Reader, msg interface{}) error {
 
 	return fmt.Errorf("unsupport content type: %s", contentType)
 }
+
+// Encode 按contentType将消息序列化为json或xml，非json时使用xml
+func Encode(contentType string, msg interface{}) ([]byte, error) {
+	if IsJSON(contentType) {
+		return json.Marshal(msg)
+	}
+	return xml.Marshal(msg)
+}
@@ -1,6 +1,7 @@
 package shortlink
 
 import (
+	context2 "context"
 	"fmt"
 
 	"github.com/amazing-gao/wechat/v2/miniprogram/context"
@@ -40,15 +41,15 @@ type resShortLinker struct {
 }
 
 // Generate 生成 shortLink
-func (shortLink *ShortLink) generate(shortLinkParams ShortLinker) (string, error) {
+func (shortLink *ShortLink) generate(ctx context2.Context, shortLinkParams ShortLinker) (string, error) {
 	var accessToken string
-	accessToken, err := shortLink.GetAccessToken()
+	accessToken, err := shortLink.GetAccessTokenContext(ctx)
 	if err != nil {
 		return "", err
 	}
 
 	urlStr := fmt.Sprintf("%s/wxa/genwxashortlink?access_token=%s", shortLink.Server, accessToken)
-	response, err := util.PostJSON(urlStr, shortLinkParams)
+	response, err := util.PostJSONContext(shortLink.RequestContext(ctx), urlStr, shortLinkParams)
 	if err != nil {
 		return "", err
 	}
@@ -65,7 +66,12 @@ func (shortLink *ShortLink) generate(shortLinkParams ShortLinker) (string, error
 
 // GenerateShortLinkPermanent 生成永久shortLink
 func (shortLink *ShortLink) GenerateShortLinkPermanent(PageURL, pageTitle string) (string, error) {
-	return shortLink.generate(ShortLinker{
+	return shortLink.GenerateShortLinkPermanentContext(context2.Background(), PageURL, pageTitle)
+}
+
+// GenerateShortLinkPermanentContext 生成永久shortLink
+func (shortLink *ShortLink) GenerateShortLinkPermanentContext(ctx context2.Context, PageURL, pageTitle string) (string, error) {
+	return shortLink.generate(ctx, ShortLinker{
 		PageURL:     PageURL,
 		PageTitle:   pageTitle,
 		IsPermanent: true,
@@ -74,7 +80,12 @@ func (shortLink *ShortLink) GenerateShortLinkPermanent(PageURL, pageTitle string
 
 // GenerateShortLinkTemp 生成临时shortLink
 func (shortLink *ShortLink) GenerateShortLinkTemp(PageURL, pageTitle string) (string, error) {
-	return shortLink.generate(ShortLinker{
+	return shortLink.GenerateShortLinkTempContext(context2.Background(), PageURL, pageTitle)
+}
+
+// GenerateShortLinkTempContext 生成临时shortLink
+func (shortLink *ShortLink) GenerateShortLinkTempContext(ctx context2.Context, PageURL, pageTitle string) (string, error) {
+	return shortLink.generate(ctx, ShortLinker{
 		PageURL:     PageURL,
 		PageTitle:   pageTitle,
 		IsPermanent: false,
@@ -1,6 +1,7 @@
 package subscribe
 
 import (
+	context2 "context"
 	"fmt"
 
 	"github.com/amazing-gao/wechat/v2/miniprogram/context"
@@ -50,13 +51,18 @@ type TemplateList struct {
 
 // Send 发送订阅消息
 func (s *Subscribe) Send(msg *Message) (err error) {
+	return s.SendContext(context2.Background(), msg)
+}
+
+// SendContext 发送订阅消息
+func (s *Subscribe) SendContext(ctx context2.Context, msg *Message) (err error) {
 	var accessToken string
-	accessToken, err = s.GetAccessToken()
+	accessToken, err = s.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 	uri := fmt.Sprintf("%s/cgi-bin/message/subscribe/send?access_token=%s", s.Server, accessToken)
-	response, err := util.PostJSON(uri, msg)
+	response, err := util.PostJSONContext(s.RequestContext(ctx), uri, msg)
 	if err != nil {
 		return
 	}
@@ -66,12 +72,18 @@ func (s *Subscribe) Send(msg *Message) (err error) {
 // ListTemplates 获取当前帐号下的个人模板列表
 // https://developers.weixin.qq.com/miniprogram/dev/api-backend/open-api/subscribe-message/subscribeMessage.getTemplateList.html
 func (s *Subscribe) ListTemplates() (*TemplateList, error) {
-	accessToken, err := s.GetAccessToken()
+	return s.ListTemplatesContext(context2.Background())
+}
+
+// ListTemplatesContext 获取当前帐号下的个人模板列表
+// https://developers.weixin.qq.com/miniprogram/dev/api-backend/open-api/subscribe-message/subscribeMessage.getTemplateList.html
+func (s *Subscribe) ListTemplatesContext(ctx context2.Context) (*TemplateList, error) {
+	accessToken, err := s.GetAccessTokenContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	uri := fmt.Sprintf("%s/wxaapi/newtmpl/gettemplate?access_token=%s", s.Server, accessToken)
-	response, err := util.HTTPGet(uri)
+	response, err := util.HTTPGetContext(s.RequestContext(ctx), uri)
 	if err != nil {
 		return nil, err
 	}
@@ -107,13 +119,18 @@ type UniformMessage struct {
 
 // UniformSend 发送统一服务消息
 func (s *Subscribe) UniformSend(msg *UniformMessage) (err error) {
+	return s.UniformSendContext(context2.Background(), msg)
+}
+
+// UniformSendContext 发送统一服务消息
+func (s *Subscribe) UniformSendContext(ctx context2.Context, msg *UniformMessage) (err error) {
 	var accessToken string
-	accessToken, err = s.GetAccessToken()
+	accessToken, err = s.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 	uri := fmt.Sprintf("%s/cgi-bin/message/wxopen/template/uniform_send?access_token=%s", s.Server, accessToken)
-	response, err := util.PostJSON(uri, msg)
+	response, err := util.PostJSONContext(s.RequestContext(ctx), uri, msg)
 	if err != nil {
 		return
 	}
@@ -128,8 +145,13 @@ type resSubscribeAdd struct {
 
 // Add 添加订阅消息模板
 func (s *Subscribe) Add(ShortID string, kidList []int, sceneDesc string) (templateID string, err error) {
+	return s.AddContext(context2.Background(), ShortID, kidList, sceneDesc)
+}
+
+// AddContext 添加订阅消息模板
+func (s *Subscribe) AddContext(ctx context2.Context, ShortID string, kidList []int, sceneDesc string) (templateID string, err error) {
 	var accessToken string
-	accessToken, err = s.GetAccessToken()
+	accessToken, err = s.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -140,7 +162,7 @@ func (s *Subscribe) Add(ShortID string, kidList []int, sceneDesc string) (templa
 	}{TemplateIDShort: ShortID, SceneDesc: sceneDesc, KidList: kidList}
 	uri := fmt.Sprintf("%s/wxaapi/newtmpl/addtemplate?access_token=%s", s.Server, accessToken)
 	var response []byte
-	response, err = util.PostJSON(uri, msg)
+	response, err = util.PostJSONContext(s.RequestContext(ctx), uri, msg)
 	if err != nil {
 		return
 	}
@@ -155,8 +177,13 @@ func (s *Subscribe) Add(ShortID string, kidList []int, sceneDesc string) (templa
 
 // Delete 删除私有模板
 func (s *Subscribe) Delete(templateID string) (err error) {
+	return s.DeleteContext(context2.Background(), templateID)
+}
+
+// DeleteContext 删除私有模板
+func (s *Subscribe) DeleteContext(ctx context2.Context, templateID string) (err error) {
 	var accessToken string
-	accessToken, err = s.GetAccessToken()
+	accessToken, err = s.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -165,7 +192,7 @@ func (s *Subscribe) Delete(templateID string) (err error) {
 	}{TemplateID: templateID}
 	uri := fmt.Sprintf("%s/wxaapi/newtmpl/deltemplate?access_token=%s", s.Server, accessToken)
 	var response []byte
-	response, err = util.PostJSON(uri, msg)
+	response, err = util.PostJSONContext(s.RequestContext(ctx), uri, msg)
 	if err != nil {
 		return
 	}
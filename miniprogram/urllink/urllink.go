@@ -1,6 +1,7 @@
 package urllink
 
 import (
+	context2 "context"
 	"fmt"
 
 	"github.com/amazing-gao/wechat/v2/miniprogram/context"
@@ -49,13 +50,18 @@ type ULResult struct {
 
 // Generate 生成url link
 func (u *URLLink) Generate(params *ULParams) (string, error) {
-	accessToken, err := u.GetAccessToken()
+	return u.GenerateContext(context2.Background(), params)
+}
+
+// GenerateContext 生成url link
+func (u *URLLink) GenerateContext(ctx context2.Context, params *ULParams) (string, error) {
+	accessToken, err := u.GetAccessTokenContext(ctx)
 	if err != nil {
 		return "", err
 	}
 
 	uri := fmt.Sprintf("%s/wxa/generate_urllink?access_token=%s", u.Server, accessToken)
-	response, err := util.PostJSON(uri, params)
+	response, err := util.PostJSONContext(u.RequestContext(ctx), uri, params)
 	if err != nil {
 		return "", err
 	}
@@ -1,6 +1,7 @@
 package basic
 
 import (
+	context2 "context"
 	"fmt"
 
 	"github.com/amazing-gao/wechat/v2/officialaccount/context"
@@ -27,12 +28,17 @@ type IPListRes struct {
 
 // GetCallbackIP 获取微信callback IP地址
 func (basic *Basic) GetCallbackIP() ([]string, error) {
-	ak, err := basic.GetAccessToken()
+	return basic.GetCallbackIPContext(context2.Background())
+}
+
+// GetCallbackIPContext 获取微信callback IP地址
+func (basic *Basic) GetCallbackIPContext(ctx context2.Context) ([]string, error) {
+	ak, err := basic.GetAccessTokenContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	url := fmt.Sprintf("%s/cgi-bin/getcallbackip?access_token=%s", basic.Server, ak)
-	data, err := util.HTTPGet(url)
+	data, err := util.HTTPGetContext(basic.RequestContext(ctx), url)
 	if err != nil {
 		return nil, err
 	}
@@ -43,12 +49,17 @@ func (basic *Basic) GetCallbackIP() ([]string, error) {
 
 // GetAPIDomainIP 获取微信API接口 IP地址
 func (basic *Basic) GetAPIDomainIP() ([]string, error) {
-	ak, err := basic.GetAccessToken()
+	return basic.GetAPIDomainIPContext(context2.Background())
+}
+
+// GetAPIDomainIPContext 获取微信API接口 IP地址
+func (basic *Basic) GetAPIDomainIPContext(ctx context2.Context) ([]string, error) {
+	ak, err := basic.GetAccessTokenContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	url := fmt.Sprintf("%s/cgi-bin/get_api_domain_ip?access_token=%s", basic.Server, ak)
-	data, err := util.HTTPGet(url)
+	data, err := util.HTTPGetContext(basic.RequestContext(ctx), url)
 	if err != nil {
 		return nil, err
 	}
@@ -59,12 +70,17 @@ func (basic *Basic) GetAPIDomainIP() ([]string, error) {
 
 // ClearQuota 清理接口调用次数
 func (basic *Basic) ClearQuota() error {
-	ak, err := basic.GetAccessToken()
+	return basic.ClearQuotaContext(context2.Background())
+}
+
+// ClearQuotaContext 清理接口调用次数
+func (basic *Basic) ClearQuotaContext(ctx context2.Context) error {
+	ak, err := basic.GetAccessTokenContext(ctx)
 	if err != nil {
 		return err
 	}
 	url := fmt.Sprintf("%s/cgi-bin/clear_quota?access_token=%s", basic.Server, ak)
-	data, err := util.PostJSON(url, map[string]string{
+	data, err := util.PostJSONContext(basic.RequestContext(ctx), url, map[string]string{
 		"appid": basic.AppID,
 	})
 	if err != nil {
@@ -72,3 +88,40 @@ func (basic *Basic) ClearQuota() error {
 	}
 	return util.DecodeWithCommonError(data, "ClearQuota")
 }
+
+// Quota 接口调用次数
+type Quota struct {
+	DailyLimit int64 `json:"daily_limit"` // 当天该账号可调用该接口的次数
+	Used       int64 `json:"used"`        // 当天已经调用的次数
+	Remain     int64 `json:"remain"`      // 当天剩余调用次数
+}
+
+// resQuota 查询接口调用次数的返回结果
+type resQuota struct {
+	util.CommonError
+	Quota Quota `json:"quota"`
+}
+
+// GetQuota 查询接口调用次数，cgiPath 为接口路径，如 /cgi-bin/message/custom/send
+// 可配合 ratelimit.Limiter.SetCount 校准本地计数
+func (basic *Basic) GetQuota(cgiPath string) (Quota, error) {
+	return basic.GetQuotaContext(context2.Background(), cgiPath)
+}
+
+// GetQuotaContext 查询接口调用次数
+func (basic *Basic) GetQuotaContext(ctx context2.Context, cgiPath string) (quota Quota, err error) {
+	ak, err := basic.GetAccessTokenContext(ctx)
+	if err != nil {
+		return
+	}
+	url := fmt.Sprintf("%s/cgi-bin/openapi/quota/get?access_token=%s", basic.Server, ak)
+	data, err := util.PostJSONContext(util.WithIdempotent(basic.RequestContext(ctx)), url, map[string]string{
+		"cgi_path": cgiPath,
+	})
+	if err != nil {
+		return
+	}
+	var res resQuota
+	err = util.DecodeWithError(data, &res, "GetQuota")
+	return res.Quota, err
+}
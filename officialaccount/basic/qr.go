@@ -1,6 +1,7 @@
 package basic
 
 import (
+	context2 "context"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -43,15 +44,20 @@ type Ticket struct {
 
 // GetQRTicket 获取二维码 Ticket
 func (basic *Basic) GetQRTicket(tq *Request) (t *Ticket, err error) {
-	accessToken, err := basic.GetAccessToken()
+	return basic.GetQRTicketContext(context2.Background(), tq)
+}
+
+// GetQRTicketContext 获取二维码 Ticket
+func (basic *Basic) GetQRTicketContext(ctx context2.Context, tq *Request) (t *Ticket, err error) {
+	accessToken, err := basic.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 
 	uri := fmt.Sprintf("%s/cgi-bin/qrcode/create?access_token=%s", basic.Server, accessToken)
-	response, err := util.PostJSON(uri, tq)
+	response, err := util.PostJSONContext(basic.RequestContext(ctx), uri, tq)
 	if err != nil {
-		err = fmt.Errorf("get qr ticket failed, %s", err)
+		err = fmt.Errorf("get qr ticket failed, %w", err)
 		return
 	}
 
@@ -62,7 +68,7 @@ func (basic *Basic) GetQRTicket(tq *Request) (t *Ticket, err error) {
 	}
 
 	if t.ErrMsg != "" {
-		err = fmt.Errorf("get qr_ticket error : errcode=%v , errormsg=%v", t.ErrCode, t.ErrMsg)
+		err = util.NewError("get qr_ticket", t.ErrCode, t.ErrMsg)
 		return
 	}
 
@@ -1,6 +1,7 @@
 package basic
 
 import (
+	context2 "context"
 	"fmt"
 
 	"github.com/amazing-gao/wechat/v2/util"
@@ -25,6 +26,11 @@ type (
 
 // Long2ShortURL 将一条长链接转成短链接
 func (basic *Basic) Long2ShortURL(longURL string) (shortURL string, err error) {
+	return basic.Long2ShortURLContext(context2.Background(), longURL)
+}
+
+// Long2ShortURLContext 将一条长链接转成短链接
+func (basic *Basic) Long2ShortURLContext(ctx context2.Context, longURL string) (shortURL string, err error) {
 	var (
 		req = &reqLong2ShortURL{
 			Action:  long2shortAction,
@@ -34,12 +40,12 @@ func (basic *Basic) Long2ShortURL(longURL string) (shortURL string, err error) {
 		ac, uri       string
 		responseBytes []byte
 	)
-	ac, err = basic.GetAccessToken()
+	ac, err = basic.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 	uri = fmt.Sprintf("%s/cgi-bin/shorturl?access_token=%s", basic.Server, ac)
-	responseBytes, err = util.PostJSON(uri, req)
+	responseBytes, err = util.PostJSONContext(basic.RequestContext(ctx), uri, req)
 	if err != nil {
 		return
 	}
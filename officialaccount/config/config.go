@@ -1,15 +1,33 @@
 package config
 
 import (
+	"time"
+
 	"github.com/amazing-gao/wechat/v2/cache"
+	"github.com/amazing-gao/wechat/v2/credential"
+	"github.com/amazing-gao/wechat/v2/ratelimit"
+	"github.com/amazing-gao/wechat/v2/util"
 )
 
 // Config .config for 微信公众号
 type Config struct {
 	Server         string `json:"server"`           // server
+	OpenServer     string `json:"open_server"`      // 网页授权域名，默认 https://open.weixin.qq.com
 	AppID          string `json:"app_id"`           // appid
 	AppSecret      string `json:"app_secret"`       // appsecret
 	Token          string `json:"token"`            // token
 	EncodingAESKey string `json:"encoding_aes_key"` // EncodingAESKey
+	UseStableToken bool   `json:"use_stable_token"` // 使用 /cgi-bin/stable_token 获取access_token
 	Cache          cache.Cache
+	HTTPClient     util.Doer              `json:"-"`               // 自定义HTTP客户端，为空时使用 util.DefaultHTTPClient
+	BackupServers  []string               `json:"backup_servers"`  // 容灾域名，启用域名容灾时 Server 出现连续连接错误或5xx后切换，为空时使用 util.DefaultAPIDomains 中的其他域名
+	EnableFailover bool                   `json:"enable_failover"` // 启用域名容灾，在 Server 与 BackupServers 间自动切换
+	Failover       *util.Failover         `json:"-"`               // 自定义域名容灾，设置后即启用，可在多个账号间共享
+	RetryPolicy    *util.RetryPolicy      `json:"retry_policy"`    // 请求失败时的重试策略，为空时不重试
+	RateLimiter    *ratelimit.Limiter     `json:"-"`               // 客户端限流及调用次数统计，为空时不限流
+	Interceptors   []util.Interceptor     `json:"-"`               // 接口调用拦截器，在通过 Wechat.Use 注册的拦截器之后执行
+	CallbackHooks  []util.CallbackHook    `json:"-"`               // 接收消息与事件时的钩子
+	Logger         util.Logger            `json:"-"`               // 日志，为空时使用 util.DefaultLogger，敏感字段默认脱敏
+	MsgDedupTTL    time.Duration          `json:"msg_dedup_ttl"`   // 回调消息去重记录的保存时间，大于0时启用去重，可使用 cache.DefaultMsgDedupTTL
+	RefreshHook    credential.RefreshHook `json:"-"`               // 每次从服务端获取access_token或jsapi_ticket后的回调
 }
@@ -1,6 +1,7 @@
 package datacube
 
 import (
+	context2 "context"
 	"fmt"
 
 	"github.com/amazing-gao/wechat/v2/util"
@@ -131,7 +132,12 @@ type ResUserShareHour struct {
 
 // GetArticleSummary 获取图文群发每日数据
 func (cube *DataCube) GetArticleSummary(s string, e string) (resArticleSummary ResArticleSummary, err error) {
-	accessToken, err := cube.GetAccessToken()
+	return cube.GetArticleSummaryContext(context2.Background(), s, e)
+}
+
+// GetArticleSummaryContext 获取图文群发每日数据
+func (cube *DataCube) GetArticleSummaryContext(ctx context2.Context, s string, e string) (resArticleSummary ResArticleSummary, err error) {
+	accessToken, err := cube.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -142,7 +148,7 @@ func (cube *DataCube) GetArticleSummary(s string, e string) (resArticleSummary R
 		EndDate:   e,
 	}
 
-	response, err := util.PostJSON(uri, reqDate)
+	response, err := util.PostJSONContext(util.WithIdempotent(cube.RequestContext(ctx)), uri, reqDate)
 	if err != nil {
 		return
 	}
@@ -153,7 +159,12 @@ func (cube *DataCube) GetArticleSummary(s string, e string) (resArticleSummary R
 
 // GetArticleTotal 获取图文群发总数据
 func (cube *DataCube) GetArticleTotal(s string, e string) (resArticleTotal ResArticleTotal, err error) {
-	accessToken, err := cube.GetAccessToken()
+	return cube.GetArticleTotalContext(context2.Background(), s, e)
+}
+
+// GetArticleTotalContext 获取图文群发总数据
+func (cube *DataCube) GetArticleTotalContext(ctx context2.Context, s string, e string) (resArticleTotal ResArticleTotal, err error) {
+	accessToken, err := cube.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -164,7 +175,7 @@ func (cube *DataCube) GetArticleTotal(s string, e string) (resArticleTotal ResAr
 		EndDate:   e,
 	}
 
-	response, err := util.PostJSON(uri, reqDate)
+	response, err := util.PostJSONContext(util.WithIdempotent(cube.RequestContext(ctx)), uri, reqDate)
 	if err != nil {
 		return
 	}
@@ -175,7 +186,12 @@ func (cube *DataCube) GetArticleTotal(s string, e string) (resArticleTotal ResAr
 
 // GetUserRead 获取图文统计数据
 func (cube *DataCube) GetUserRead(s string, e string) (resUserRead ResUserRead, err error) {
-	accessToken, err := cube.GetAccessToken()
+	return cube.GetUserReadContext(context2.Background(), s, e)
+}
+
+// GetUserReadContext 获取图文统计数据
+func (cube *DataCube) GetUserReadContext(ctx context2.Context, s string, e string) (resUserRead ResUserRead, err error) {
+	accessToken, err := cube.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -186,7 +202,7 @@ func (cube *DataCube) GetUserRead(s string, e string) (resUserRead ResUserRead,
 		EndDate:   e,
 	}
 
-	response, err := util.PostJSON(uri, reqDate)
+	response, err := util.PostJSONContext(util.WithIdempotent(cube.RequestContext(ctx)), uri, reqDate)
 	if err != nil {
 		return
 	}
@@ -197,7 +213,12 @@ func (cube *DataCube) GetUserRead(s string, e string) (resUserRead ResUserRead,
 
 // GetUserReadHour 获取图文统计分时数据
 func (cube *DataCube) GetUserReadHour(s string, e string) (resUserReadHour ResUserReadHour, err error) {
-	accessToken, err := cube.GetAccessToken()
+	return cube.GetUserReadHourContext(context2.Background(), s, e)
+}
+
+// GetUserReadHourContext 获取图文统计分时数据
+func (cube *DataCube) GetUserReadHourContext(ctx context2.Context, s string, e string) (resUserReadHour ResUserReadHour, err error) {
+	accessToken, err := cube.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -208,7 +229,7 @@ func (cube *DataCube) GetUserReadHour(s string, e string) (resUserReadHour ResUs
 		EndDate:   e,
 	}
 
-	response, err := util.PostJSON(uri, reqDate)
+	response, err := util.PostJSONContext(util.WithIdempotent(cube.RequestContext(ctx)), uri, reqDate)
 	if err != nil {
 		return
 	}
@@ -219,7 +240,12 @@ func (cube *DataCube) GetUserReadHour(s string, e string) (resUserReadHour ResUs
 
 // GetUserShare 获取图文分享转发数据
 func (cube *DataCube) GetUserShare(s string, e string) (resUserShare ResUserShare, err error) {
-	accessToken, err := cube.GetAccessToken()
+	return cube.GetUserShareContext(context2.Background(), s, e)
+}
+
+// GetUserShareContext 获取图文分享转发数据
+func (cube *DataCube) GetUserShareContext(ctx context2.Context, s string, e string) (resUserShare ResUserShare, err error) {
+	accessToken, err := cube.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -230,7 +256,7 @@ func (cube *DataCube) GetUserShare(s string, e string) (resUserShare ResUserShar
 		EndDate:   e,
 	}
 
-	response, err := util.PostJSON(uri, reqDate)
+	response, err := util.PostJSONContext(util.WithIdempotent(cube.RequestContext(ctx)), uri, reqDate)
 	if err != nil {
 		return
 	}
@@ -241,7 +267,12 @@ func (cube *DataCube) GetUserShare(s string, e string) (resUserShare ResUserShar
 
 // GetUserShareHour 获取图文分享转发分时数据
 func (cube *DataCube) GetUserShareHour(s string, e string) (resUserShareHour ResUserShareHour, err error) {
-	accessToken, err := cube.GetAccessToken()
+	return cube.GetUserShareHourContext(context2.Background(), s, e)
+}
+
+// GetUserShareHourContext 获取图文分享转发分时数据
+func (cube *DataCube) GetUserShareHourContext(ctx context2.Context, s string, e string) (resUserShareHour ResUserShareHour, err error) {
+	accessToken, err := cube.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -252,7 +283,7 @@ func (cube *DataCube) GetUserShareHour(s string, e string) (resUserShareHour Res
 		EndDate:   e,
 	}
 
-	response, err := util.PostJSON(uri, reqDate)
+	response, err := util.PostJSONContext(util.WithIdempotent(cube.RequestContext(ctx)), uri, reqDate)
 	if err != nil {
 		return
 	}
@@ -1,6 +1,7 @@
 package datacube
 
 import (
+	context2 "context"
 	"fmt"
 
 	"github.com/amazing-gao/wechat/v2/util"
@@ -35,7 +36,12 @@ type ResInterfaceSummaryHour struct {
 
 // GetInterfaceSummary 获取接口分析数据
 func (cube *DataCube) GetInterfaceSummary(s string, e string) (resInterfaceSummary ResInterfaceSummary, err error) {
-	accessToken, err := cube.GetAccessToken()
+	return cube.GetInterfaceSummaryContext(context2.Background(), s, e)
+}
+
+// GetInterfaceSummaryContext 获取接口分析数据
+func (cube *DataCube) GetInterfaceSummaryContext(ctx context2.Context, s string, e string) (resInterfaceSummary ResInterfaceSummary, err error) {
+	accessToken, err := cube.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -46,7 +52,7 @@ func (cube *DataCube) GetInterfaceSummary(s string, e string) (resInterfaceSumma
 		EndDate:   e,
 	}
 
-	response, err := util.PostJSON(uri, reqDate)
+	response, err := util.PostJSONContext(util.WithIdempotent(cube.RequestContext(ctx)), uri, reqDate)
 	if err != nil {
 		return
 	}
@@ -57,7 +63,12 @@ func (cube *DataCube) GetInterfaceSummary(s string, e string) (resInterfaceSumma
 
 // GetInterfaceSummaryHour 获取接口分析分时数据
 func (cube *DataCube) GetInterfaceSummaryHour(s string, e string) (resInterfaceSummaryHour ResInterfaceSummaryHour, err error) {
-	accessToken, err := cube.GetAccessToken()
+	return cube.GetInterfaceSummaryHourContext(context2.Background(), s, e)
+}
+
+// GetInterfaceSummaryHourContext 获取接口分析分时数据
+func (cube *DataCube) GetInterfaceSummaryHourContext(ctx context2.Context, s string, e string) (resInterfaceSummaryHour ResInterfaceSummaryHour, err error) {
+	accessToken, err := cube.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -68,7 +79,7 @@ func (cube *DataCube) GetInterfaceSummaryHour(s string, e string) (resInterfaceS
 		EndDate:   e,
 	}
 
-	response, err := util.PostJSON(uri, reqDate)
+	response, err := util.PostJSONContext(util.WithIdempotent(cube.RequestContext(ctx)), uri, reqDate)
 	if err != nil {
 		return
 	}
@@ -1,6 +1,7 @@
 package datacube
 
 import (
+	context2 "context"
 	"fmt"
 
 	"github.com/amazing-gao/wechat/v2/util"
@@ -90,7 +91,12 @@ type ResUpstreamMsgDistMonth struct {
 
 // GetUpstreamMsg 获取消息发送概况数据
 func (cube *DataCube) GetUpstreamMsg(s string, e string) (resUpstreamMsg ResUpstreamMsg, err error) {
-	accessToken, err := cube.GetAccessToken()
+	return cube.GetUpstreamMsgContext(context2.Background(), s, e)
+}
+
+// GetUpstreamMsgContext 获取消息发送概况数据
+func (cube *DataCube) GetUpstreamMsgContext(ctx context2.Context, s string, e string) (resUpstreamMsg ResUpstreamMsg, err error) {
+	accessToken, err := cube.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -101,7 +107,7 @@ func (cube *DataCube) GetUpstreamMsg(s string, e string) (resUpstreamMsg ResUpst
 		EndDate:   e,
 	}
 
-	response, err := util.PostJSON(uri, reqDate)
+	response, err := util.PostJSONContext(util.WithIdempotent(cube.RequestContext(ctx)), uri, reqDate)
 	if err != nil {
 		return
 	}
@@ -112,7 +118,12 @@ func (cube *DataCube) GetUpstreamMsg(s string, e string) (resUpstreamMsg ResUpst
 
 // GetUpstreamMsgHour 获取消息分送分时数据
 func (cube *DataCube) GetUpstreamMsgHour(s string, e string) (resUpstreamMsgHour ResUpstreamMsgHour, err error) {
-	accessToken, err := cube.GetAccessToken()
+	return cube.GetUpstreamMsgHourContext(context2.Background(), s, e)
+}
+
+// GetUpstreamMsgHourContext 获取消息分送分时数据
+func (cube *DataCube) GetUpstreamMsgHourContext(ctx context2.Context, s string, e string) (resUpstreamMsgHour ResUpstreamMsgHour, err error) {
+	accessToken, err := cube.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -123,7 +134,7 @@ func (cube *DataCube) GetUpstreamMsgHour(s string, e string) (resUpstreamMsgHour
 		EndDate:   e,
 	}
 
-	response, err := util.PostJSON(uri, reqDate)
+	response, err := util.PostJSONContext(util.WithIdempotent(cube.RequestContext(ctx)), uri, reqDate)
 	if err != nil {
 		return
 	}
@@ -134,7 +145,12 @@ func (cube *DataCube) GetUpstreamMsgHour(s string, e string) (resUpstreamMsgHour
 
 // GetUpstreamMsgWeek 获取消息发送周数据
 func (cube *DataCube) GetUpstreamMsgWeek(s string, e string) (resUpstreamMsgWeek ResUpstreamMsgWeek, err error) {
-	accessToken, err := cube.GetAccessToken()
+	return cube.GetUpstreamMsgWeekContext(context2.Background(), s, e)
+}
+
+// GetUpstreamMsgWeekContext 获取消息发送周数据
+func (cube *DataCube) GetUpstreamMsgWeekContext(ctx context2.Context, s string, e string) (resUpstreamMsgWeek ResUpstreamMsgWeek, err error) {
+	accessToken, err := cube.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -145,7 +161,7 @@ func (cube *DataCube) GetUpstreamMsgWeek(s string, e string) (resUpstreamMsgWeek
 		EndDate:   e,
 	}
 
-	response, err := util.PostJSON(uri, reqDate)
+	response, err := util.PostJSONContext(util.WithIdempotent(cube.RequestContext(ctx)), uri, reqDate)
 	if err != nil {
 		return
 	}
@@ -156,7 +172,12 @@ func (cube *DataCube) GetUpstreamMsgWeek(s string, e string) (resUpstreamMsgWeek
 
 // GetUpstreamMsgMonth 获取消息发送月数据
 func (cube *DataCube) GetUpstreamMsgMonth(s string, e string) (resUpstreamMsgMonth ResUpstreamMsgMonth, err error) {
-	accessToken, err := cube.GetAccessToken()
+	return cube.GetUpstreamMsgMonthContext(context2.Background(), s, e)
+}
+
+// GetUpstreamMsgMonthContext 获取消息发送月数据
+func (cube *DataCube) GetUpstreamMsgMonthContext(ctx context2.Context, s string, e string) (resUpstreamMsgMonth ResUpstreamMsgMonth, err error) {
+	accessToken, err := cube.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -167,7 +188,7 @@ func (cube *DataCube) GetUpstreamMsgMonth(s string, e string) (resUpstreamMsgMon
 		EndDate:   e,
 	}
 
-	response, err := util.PostJSON(uri, reqDate)
+	response, err := util.PostJSONContext(util.WithIdempotent(cube.RequestContext(ctx)), uri, reqDate)
 	if err != nil {
 		return
 	}
@@ -178,7 +199,12 @@ func (cube *DataCube) GetUpstreamMsgMonth(s string, e string) (resUpstreamMsgMon
 
 // GetUpstreamMsgDist 获取消息发送分布数据
 func (cube *DataCube) GetUpstreamMsgDist(s string, e string) (resUpstreamMsgDist ResUpstreamMsgDist, err error) {
-	accessToken, err := cube.GetAccessToken()
+	return cube.GetUpstreamMsgDistContext(context2.Background(), s, e)
+}
+
+// GetUpstreamMsgDistContext 获取消息发送分布数据
+func (cube *DataCube) GetUpstreamMsgDistContext(ctx context2.Context, s string, e string) (resUpstreamMsgDist ResUpstreamMsgDist, err error) {
+	accessToken, err := cube.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -189,7 +215,7 @@ func (cube *DataCube) GetUpstreamMsgDist(s string, e string) (resUpstreamMsgDist
 		EndDate:   e,
 	}
 
-	response, err := util.PostJSON(uri, reqDate)
+	response, err := util.PostJSONContext(util.WithIdempotent(cube.RequestContext(ctx)), uri, reqDate)
 	if err != nil {
 		return
 	}
@@ -200,7 +226,12 @@ func (cube *DataCube) GetUpstreamMsgDist(s string, e string) (resUpstreamMsgDist
 
 // GetUpstreamMsgDistWeek 获取消息发送分布周数据
 func (cube *DataCube) GetUpstreamMsgDistWeek(s string, e string) (resUpstreamMsgDistWeek ResUpstreamMsgDistWeek, err error) {
-	accessToken, err := cube.GetAccessToken()
+	return cube.GetUpstreamMsgDistWeekContext(context2.Background(), s, e)
+}
+
+// GetUpstreamMsgDistWeekContext 获取消息发送分布周数据
+func (cube *DataCube) GetUpstreamMsgDistWeekContext(ctx context2.Context, s string, e string) (resUpstreamMsgDistWeek ResUpstreamMsgDistWeek, err error) {
+	accessToken, err := cube.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -211,7 +242,7 @@ func (cube *DataCube) GetUpstreamMsgDistWeek(s string, e string) (resUpstreamMsg
 		EndDate:   e,
 	}
 
-	response, err := util.PostJSON(uri, reqDate)
+	response, err := util.PostJSONContext(util.WithIdempotent(cube.RequestContext(ctx)), uri, reqDate)
 	if err != nil {
 		return
 	}
@@ -222,7 +253,12 @@ func (cube *DataCube) GetUpstreamMsgDistWeek(s string, e string) (resUpstreamMsg
 
 // GetUpstreamMsgDistMonth 获取消息发送分布月数据
 func (cube *DataCube) GetUpstreamMsgDistMonth(s string, e string) (resUpstreamMsgDistMonth ResUpstreamMsgDistMonth, err error) {
-	accessToken, err := cube.GetAccessToken()
+	return cube.GetUpstreamMsgDistMonthContext(context2.Background(), s, e)
+}
+
+// GetUpstreamMsgDistMonthContext 获取消息发送分布月数据
+func (cube *DataCube) GetUpstreamMsgDistMonthContext(ctx context2.Context, s string, e string) (resUpstreamMsgDistMonth ResUpstreamMsgDistMonth, err error) {
+	accessToken, err := cube.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -233,7 +269,7 @@ func (cube *DataCube) GetUpstreamMsgDistMonth(s string, e string) (resUpstreamMs
 		EndDate:   e,
 	}
 
-	response, err := util.PostJSON(uri, reqDate)
+	response, err := util.PostJSONContext(util.WithIdempotent(cube.RequestContext(ctx)), uri, reqDate)
 	if err != nil {
 		return
 	}
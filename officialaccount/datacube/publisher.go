@@ -1,6 +1,7 @@
 package datacube
 
 import (
+	context2 "context"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -159,8 +160,8 @@ type ParamsPublisher struct {
 }
 
 // fetchData 拉取统计数据
-func (cube *DataCube) fetchData(params ParamsPublisher) (response []byte, err error) {
-	accessToken, err := cube.GetAccessToken()
+func (cube *DataCube) fetchData(ctx context2.Context, params ParamsPublisher) (response []byte, err error) {
+	accessToken, err := cube.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -178,7 +179,7 @@ func (cube *DataCube) fetchData(params ParamsPublisher) (response []byte, err er
 
 	uri := fmt.Sprintf("%s/publisher/stat?%s", cube.Server, v.Encode())
 
-	response, err = util.HTTPGet(uri)
+	response, err = util.HTTPGetContext(cube.RequestContext(ctx), uri)
 	if err != nil {
 		return
 	}
@@ -187,6 +188,11 @@ func (cube *DataCube) fetchData(params ParamsPublisher) (response []byte, err er
 
 // GetPublisherAdPosGeneral 获取公众号分广告位数据
 func (cube *DataCube) GetPublisherAdPosGeneral(startDate, endDate string, page, pageSize int, adSlot AdSlot) (resPublisherAdPos ResPublisherAdPos, err error) {
+	return cube.GetPublisherAdPosGeneralContext(context2.Background(), startDate, endDate, page, pageSize, adSlot)
+}
+
+// GetPublisherAdPosGeneralContext 获取公众号分广告位数据
+func (cube *DataCube) GetPublisherAdPosGeneralContext(ctx context2.Context, startDate, endDate string, page, pageSize int, adSlot AdSlot) (resPublisherAdPos ResPublisherAdPos, err error) {
 	params := ParamsPublisher{
 		Action:    actionPublisherAdPosGeneral,
 		StartDate: startDate,
@@ -196,7 +202,7 @@ func (cube *DataCube) GetPublisherAdPosGeneral(startDate, endDate string, page,
 		AdSlot:    adSlot,
 	}
 
-	response, err := cube.fetchData(params)
+	response, err := cube.fetchData(ctx, params)
 	if err != nil {
 		return
 	}
@@ -207,7 +213,7 @@ func (cube *DataCube) GetPublisherAdPosGeneral(startDate, endDate string, page,
 	}
 
 	if resPublisherAdPos.BaseResp.Ret != 0 {
-		err = fmt.Errorf("GetPublisherAdPosGeneral Error , errcode=%d , errmsg=%s", resPublisherAdPos.BaseResp.Ret, resPublisherAdPos.BaseResp.ErrMsg)
+		err = util.NewError("GetPublisherAdPosGeneral", int64(resPublisherAdPos.BaseResp.Ret), resPublisherAdPos.BaseResp.ErrMsg)
 		return
 	}
 	return
@@ -215,6 +221,11 @@ func (cube *DataCube) GetPublisherAdPosGeneral(startDate, endDate string, page,
 
 // GetPublisherCpsGeneral 获取公众号返佣商品数据
 func (cube *DataCube) GetPublisherCpsGeneral(startDate, endDate string, page, pageSize int) (resPublisherCps ResPublisherCps, err error) {
+	return cube.GetPublisherCpsGeneralContext(context2.Background(), startDate, endDate, page, pageSize)
+}
+
+// GetPublisherCpsGeneralContext 获取公众号返佣商品数据
+func (cube *DataCube) GetPublisherCpsGeneralContext(ctx context2.Context, startDate, endDate string, page, pageSize int) (resPublisherCps ResPublisherCps, err error) {
 	params := ParamsPublisher{
 		Action:    actionPublisherCpsGeneral,
 		StartDate: startDate,
@@ -223,7 +234,7 @@ func (cube *DataCube) GetPublisherCpsGeneral(startDate, endDate string, page, pa
 		PageSize:  pageSize,
 	}
 
-	response, err := cube.fetchData(params)
+	response, err := cube.fetchData(ctx, params)
 	if err != nil {
 		return
 	}
@@ -234,7 +245,7 @@ func (cube *DataCube) GetPublisherCpsGeneral(startDate, endDate string, page, pa
 	}
 
 	if resPublisherCps.BaseResp.Ret != 0 {
-		err = fmt.Errorf("GetPublisherCpsGeneral Error , errcode=%d , errmsg=%s", resPublisherCps.BaseResp.Ret, resPublisherCps.BaseResp.ErrMsg)
+		err = util.NewError("GetPublisherCpsGeneral", int64(resPublisherCps.BaseResp.Ret), resPublisherCps.BaseResp.ErrMsg)
 		return
 	}
 	return
@@ -242,6 +253,11 @@ func (cube *DataCube) GetPublisherCpsGeneral(startDate, endDate string, page, pa
 
 // GetPublisherSettlement 获取公众号结算收入数据及结算主体信息
 func (cube *DataCube) GetPublisherSettlement(startDate, endDate string, page, pageSize int) (resPublisherSettlement ResPublisherSettlement, err error) {
+	return cube.GetPublisherSettlementContext(context2.Background(), startDate, endDate, page, pageSize)
+}
+
+// GetPublisherSettlementContext 获取公众号结算收入数据及结算主体信息
+func (cube *DataCube) GetPublisherSettlementContext(ctx context2.Context, startDate, endDate string, page, pageSize int) (resPublisherSettlement ResPublisherSettlement, err error) {
 	params := ParamsPublisher{
 		Action:    actionPublisherSettlement,
 		StartDate: startDate,
@@ -250,7 +266,7 @@ func (cube *DataCube) GetPublisherSettlement(startDate, endDate string, page, pa
 		PageSize:  pageSize,
 	}
 
-	response, err := cube.fetchData(params)
+	response, err := cube.fetchData(ctx, params)
 	if err != nil {
 		return
 	}
@@ -261,7 +277,7 @@ func (cube *DataCube) GetPublisherSettlement(startDate, endDate string, page, pa
 	}
 
 	if resPublisherSettlement.BaseResp.Ret != 0 {
-		err = fmt.Errorf("GetPublisherSettlement Error , errcode=%d , errmsg=%s", resPublisherSettlement.BaseResp.Ret, resPublisherSettlement.BaseResp.ErrMsg)
+		err = util.NewError("GetPublisherSettlement", int64(resPublisherSettlement.BaseResp.Ret), resPublisherSettlement.BaseResp.ErrMsg)
 		return
 	}
 	return
@@ -1,6 +1,7 @@
 package datacube
 
 import (
+	context2 "context"
 	"fmt"
 
 	"github.com/amazing-gao/wechat/v2/util"
@@ -30,7 +31,12 @@ type ResUserAccumulate struct {
 
 // GetUserSummary 获取用户增减数据
 func (cube *DataCube) GetUserSummary(s string, e string) (resUserSummary ResUserSummary, err error) {
-	accessToken, err := cube.GetAccessToken()
+	return cube.GetUserSummaryContext(context2.Background(), s, e)
+}
+
+// GetUserSummaryContext 获取用户增减数据
+func (cube *DataCube) GetUserSummaryContext(ctx context2.Context, s string, e string) (resUserSummary ResUserSummary, err error) {
+	accessToken, err := cube.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -41,7 +47,7 @@ func (cube *DataCube) GetUserSummary(s string, e string) (resUserSummary ResUser
 		EndDate:   e,
 	}
 
-	response, err := util.PostJSON(uri, reqDate)
+	response, err := util.PostJSONContext(util.WithIdempotent(cube.RequestContext(ctx)), uri, reqDate)
 	if err != nil {
 		return
 	}
@@ -52,7 +58,12 @@ func (cube *DataCube) GetUserSummary(s string, e string) (resUserSummary ResUser
 
 // GetUserAccumulate 获取累计用户数据
 func (cube *DataCube) GetUserAccumulate(s string, e string) (resUserAccumulate ResUserAccumulate, err error) {
-	accessToken, err := cube.GetAccessToken()
+	return cube.GetUserAccumulateContext(context2.Background(), s, e)
+}
+
+// GetUserAccumulateContext 获取累计用户数据
+func (cube *DataCube) GetUserAccumulateContext(ctx context2.Context, s string, e string) (resUserAccumulate ResUserAccumulate, err error) {
+	accessToken, err := cube.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -63,7 +74,7 @@ func (cube *DataCube) GetUserAccumulate(s string, e string) (resUserAccumulate R
 		EndDate:   e,
 	}
 
-	response, err := util.PostJSON(uri, reqDate)
+	response, err := util.PostJSONContext(util.WithIdempotent(cube.RequestContext(ctx)), uri, reqDate)
 	if err != nil {
 		return
 	}
@@ -2,6 +2,7 @@
 package device
 
 import (
+	context2 "context"
 	"encoding/json"
 	"fmt"
 
@@ -85,8 +86,13 @@ type resDeviceAuthorize struct {
 
 // DeviceAuthorize 设备授权
 func (d *Device) DeviceAuthorize(devices []ReqDevice, opType int, product string) (res []ResBaseInfo, err error) {
+	return d.DeviceAuthorizeContext(context2.Background(), devices, opType, product)
+}
+
+// DeviceAuthorizeContext 设备授权
+func (d *Device) DeviceAuthorizeContext(ctx context2.Context, devices []ReqDevice, opType int, product string) (res []ResBaseInfo, err error) {
 	var accessToken string
-	accessToken, err = d.GetAccessToken()
+	accessToken, err = d.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -99,7 +105,7 @@ func (d *Device) DeviceAuthorize(devices []ReqDevice, opType int, product string
 		ProductID:  product,
 	}
 	var response []byte
-	response, err = util.PostJSON(uri, req)
+	response, err = util.PostJSONContext(d.RequestContext(ctx), uri, req)
 	if err != nil {
 		return nil, err
 	}
@@ -109,7 +115,7 @@ func (d *Device) DeviceAuthorize(devices []ReqDevice, opType int, product string
 		return
 	}
 	if result.ErrCode != 0 {
-		err = fmt.Errorf("DeviceAuthorize Error , errcode=%d , errmsg=%s", result.ErrCode, result.ErrMsg)
+		err = util.NewError("DeviceAuthorize", result.ErrCode, result.ErrMsg)
 		return
 	}
 	res = result.Resp
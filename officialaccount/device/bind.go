@@ -1,6 +1,7 @@
 package device
 
 import (
+	context2 "context"
 	"encoding/json"
 	"fmt"
 
@@ -19,13 +20,18 @@ type resBind struct {
 
 // Bind 设备绑定
 func (d *Device) Bind(req ReqBind) (err error) {
+	return d.BindContext(context2.Background(), req)
+}
+
+// BindContext 设备绑定
+func (d *Device) BindContext(ctx context2.Context, req ReqBind) (err error) {
 	var accessToken string
-	if accessToken, err = d.GetAccessToken(); err != nil {
+	if accessToken, err = d.GetAccessTokenContext(ctx); err != nil {
 		return
 	}
 	uri := fmt.Sprintf("%s/device/bind?access_token=%s", d.Server, accessToken)
 	var response []byte
-	if response, err = util.PostJSON(uri, req); err != nil {
+	if response, err = util.PostJSONContext(d.RequestContext(ctx), uri, req); err != nil {
 		return
 	}
 	var result resBind
@@ -33,7 +39,7 @@ func (d *Device) Bind(req ReqBind) (err error) {
 		return
 	}
 	if result.BaseResp.ErrCode != 0 {
-		err = fmt.Errorf("DeviceBind Error , errcode=%d , errmsg=%s", result.BaseResp.ErrCode, result.BaseResp.ErrMsg)
+		err = util.NewError("DeviceBind", result.BaseResp.ErrCode, result.BaseResp.ErrMsg)
 		return
 	}
 	return
@@ -41,13 +47,18 @@ func (d *Device) Bind(req ReqBind) (err error) {
 
 // Unbind 设备解绑
 func (d *Device) Unbind(req ReqBind) (err error) {
+	return d.UnbindContext(context2.Background(), req)
+}
+
+// UnbindContext 设备解绑
+func (d *Device) UnbindContext(ctx context2.Context, req ReqBind) (err error) {
 	var accessToken string
-	if accessToken, err = d.GetAccessToken(); err != nil {
+	if accessToken, err = d.GetAccessTokenContext(ctx); err != nil {
 		return
 	}
 	uri := fmt.Sprintf("%s/device/unbind?access_token=%s", d.Server, accessToken)
 	var response []byte
-	if response, err = util.PostJSON(uri, req); err != nil {
+	if response, err = util.PostJSONContext(d.RequestContext(ctx), uri, req); err != nil {
 		return
 	}
 	var result resBind
@@ -55,7 +66,7 @@ func (d *Device) Unbind(req ReqBind) (err error) {
 		return
 	}
 	if result.BaseResp.ErrCode != 0 {
-		err = fmt.Errorf("DeviceBind Error , errcode=%d , errmsg=%s", result.BaseResp.ErrCode, result.BaseResp.ErrMsg)
+		err = util.NewError("DeviceBind", result.BaseResp.ErrCode, result.BaseResp.ErrMsg)
 		return
 	}
 	return
@@ -63,13 +74,18 @@ func (d *Device) Unbind(req ReqBind) (err error) {
 
 // CompelBind 强制绑定用户和设备
 func (d *Device) CompelBind(req ReqBind) (err error) {
+	return d.CompelBindContext(context2.Background(), req)
+}
+
+// CompelBindContext 强制绑定用户和设备
+func (d *Device) CompelBindContext(ctx context2.Context, req ReqBind) (err error) {
 	var accessToken string
-	if accessToken, err = d.GetAccessToken(); err != nil {
+	if accessToken, err = d.GetAccessTokenContext(ctx); err != nil {
 		return
 	}
 	uri := fmt.Sprintf("%s/device/compel_bind?access_token=%s", d.Server, accessToken)
 	var response []byte
-	if response, err = util.PostJSON(uri, req); err != nil {
+	if response, err = util.PostJSONContext(d.RequestContext(ctx), uri, req); err != nil {
 		return
 	}
 	var result resBind
@@ -77,7 +93,7 @@ func (d *Device) CompelBind(req ReqBind) (err error) {
 		return
 	}
 	if result.BaseResp.ErrCode != 0 {
-		err = fmt.Errorf("DeviceBind Error , errcode=%d , errmsg=%s", result.BaseResp.ErrCode, result.BaseResp.ErrMsg)
+		err = util.NewError("DeviceBind", result.BaseResp.ErrCode, result.BaseResp.ErrMsg)
 		return
 	}
 	return
@@ -85,13 +101,18 @@ func (d *Device) CompelBind(req ReqBind) (err error) {
 
 // CompelUnbind 强制解绑用户和设备
 func (d *Device) CompelUnbind(req ReqBind) (err error) {
+	return d.CompelUnbindContext(context2.Background(), req)
+}
+
+// CompelUnbindContext 强制解绑用户和设备
+func (d *Device) CompelUnbindContext(ctx context2.Context, req ReqBind) (err error) {
 	var accessToken string
-	if accessToken, err = d.GetAccessToken(); err != nil {
+	if accessToken, err = d.GetAccessTokenContext(ctx); err != nil {
 		return
 	}
 	uri := fmt.Sprintf("%s/device/compel_unbind?access_token=%s", d.Server, accessToken)
 	var response []byte
-	if response, err = util.PostJSON(uri, req); err != nil {
+	if response, err = util.PostJSONContext(d.RequestContext(ctx), uri, req); err != nil {
 		return
 	}
 	var result resBind
@@ -99,7 +120,7 @@ func (d *Device) CompelUnbind(req ReqBind) (err error) {
 		return
 	}
 	if result.BaseResp.ErrCode != 0 {
-		err = fmt.Errorf("DeviceBind Error , errcode=%d , errmsg=%s", result.BaseResp.ErrCode, result.BaseResp.ErrMsg)
+		err = util.NewError("DeviceBind", result.BaseResp.ErrCode, result.BaseResp.ErrMsg)
 		return
 	}
 	return
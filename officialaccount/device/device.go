@@ -1,6 +1,7 @@
 package device
 
 import (
+	context2 "context"
 	"encoding/json"
 	"fmt"
 
@@ -29,20 +30,25 @@ type ResDeviceState struct {
 
 // State 设备状态查询
 func (d *Device) State(device string) (res ResDeviceState, err error) {
+	return d.StateContext(context2.Background(), device)
+}
+
+// StateContext 设备状态查询
+func (d *Device) StateContext(ctx context2.Context, device string) (res ResDeviceState, err error) {
 	var accessToken string
-	if accessToken, err = d.GetAccessToken(); err != nil {
+	if accessToken, err = d.GetAccessTokenContext(ctx); err != nil {
 		return
 	}
 	uri := fmt.Sprintf("%s/device/get_stat?access_token=%s&device_id=%s", d.Server, accessToken, device)
 	var response []byte
-	if response, err = util.HTTPGet(uri); err != nil {
+	if response, err = util.HTTPGetContext(d.RequestContext(ctx), uri); err != nil {
 		return
 	}
 	if err = json.Unmarshal(response, &res); err != nil {
 		return
 	}
 	if res.ErrCode != 0 {
-		err = fmt.Errorf("DeviceState Error , errcode=%d , errmsg=%s", res.ErrCode, res.ErrMsg)
+		err = util.NewError("DeviceState", res.ErrCode, res.ErrMsg)
 		return
 	}
 	return
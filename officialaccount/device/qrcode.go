@@ -1,6 +1,7 @@
 package device
 
 import (
+	context2 "context"
 	"encoding/json"
 	"fmt"
 
@@ -19,8 +20,13 @@ type ResCreateQRCode struct {
 
 // CreateQRCode 获取设备二维码
 func (d *Device) CreateQRCode(devices []string) (res ResCreateQRCode, err error) {
+	return d.CreateQRCodeContext(context2.Background(), devices)
+}
+
+// CreateQRCodeContext 获取设备二维码
+func (d *Device) CreateQRCodeContext(ctx context2.Context, devices []string) (res ResCreateQRCode, err error) {
 	var accessToken string
-	if accessToken, err = d.GetAccessToken(); err != nil {
+	if accessToken, err = d.GetAccessTokenContext(ctx); err != nil {
 		return
 	}
 	uri := fmt.Sprintf("%s/device/create_qrcode?access_token=%s", d.Server, accessToken)
@@ -29,14 +35,14 @@ func (d *Device) CreateQRCode(devices []string) (res ResCreateQRCode, err error)
 		"device_id_list": devices,
 	}
 	var response []byte
-	if response, err = util.PostJSON(uri, req); err != nil {
+	if response, err = util.PostJSONContext(d.RequestContext(ctx), uri, req); err != nil {
 		return
 	}
 	if err = json.Unmarshal(response, &res); err != nil {
 		return
 	}
 	if res.ErrCode != 0 {
-		err = fmt.Errorf("DeviceCreateQRCode Error , errcode=%d , errmsg=%s", res.ErrCode, res.ErrMsg)
+		err = util.NewError("DeviceCreateQRCode", res.ErrCode, res.ErrMsg)
 		return
 	}
 	return
@@ -52,8 +58,13 @@ type ResVerifyQRCode struct {
 
 // VerifyQRCode 验证设备二维码
 func (d *Device) VerifyQRCode(ticket string) (res ResVerifyQRCode, err error) {
+	return d.VerifyQRCodeContext(context2.Background(), ticket)
+}
+
+// VerifyQRCodeContext 验证设备二维码
+func (d *Device) VerifyQRCodeContext(ctx context2.Context, ticket string) (res ResVerifyQRCode, err error) {
 	var accessToken string
-	if accessToken, err = d.GetAccessToken(); err != nil {
+	if accessToken, err = d.GetAccessTokenContext(ctx); err != nil {
 		return
 	}
 	uri := fmt.Sprintf("%s/device/verify_qrcode?access_token=%s", d.Server, accessToken)
@@ -62,14 +73,14 @@ func (d *Device) VerifyQRCode(ticket string) (res ResVerifyQRCode, err error) {
 	}
 
 	var response []byte
-	if response, err = util.PostJSON(uri, req); err != nil {
+	if response, err = util.PostJSONContext(util.WithIdempotent(d.RequestContext(ctx)), uri, req); err != nil {
 		return
 	}
 	if err = json.Unmarshal(response, &res); err != nil {
 		return
 	}
 	if res.ErrCode != 0 {
-		err = fmt.Errorf("DeviceCreateQRCode Error , errcode=%d , errmsg=%s", res.ErrCode, res.ErrMsg)
+		err = util.NewError("DeviceCreateQRCode", res.ErrCode, res.ErrMsg)
 		return
 	}
 	return
@@ -1,6 +1,7 @@
 package draft
 
 import (
+	context2 "context"
 	"fmt"
 
 	"github.com/amazing-gao/wechat/v2/officialaccount/context"
@@ -34,7 +35,12 @@ type Article struct {
 
 // AddDraft 新建草稿
 func (draft *Draft) AddDraft(articles []*Article) (mediaID string, err error) {
-	accessToken, err := draft.GetAccessToken()
+	return draft.AddDraftContext(context2.Background(), articles)
+}
+
+// AddDraftContext 新建草稿
+func (draft *Draft) AddDraftContext(ctx context2.Context, articles []*Article) (mediaID string, err error) {
+	accessToken, err := draft.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -45,7 +51,7 @@ func (draft *Draft) AddDraft(articles []*Article) (mediaID string, err error) {
 	req.Articles = articles
 
 	uri := fmt.Sprintf("%s/cgi-bin/draft/add?access_token=%s", draft.Server, accessToken)
-	response, err := util.PostJSON(uri, req)
+	response, err := util.PostJSONContext(draft.RequestContext(ctx), uri, req)
 	if err != nil {
 		return
 	}
@@ -64,7 +70,12 @@ func (draft *Draft) AddDraft(articles []*Article) (mediaID string, err error) {
 
 // GetDraft 获取草稿
 func (draft *Draft) GetDraft(mediaID string) (articles []*Article, err error) {
-	accessToken, err := draft.GetAccessToken()
+	return draft.GetDraftContext(context2.Background(), mediaID)
+}
+
+// GetDraftContext 获取草稿
+func (draft *Draft) GetDraftContext(ctx context2.Context, mediaID string) (articles []*Article, err error) {
+	accessToken, err := draft.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -75,7 +86,7 @@ func (draft *Draft) GetDraft(mediaID string) (articles []*Article, err error) {
 	req.MediaID = mediaID
 
 	uri := fmt.Sprintf("%s/cgi-bin/draft/get?access_token=%s", draft.Server, accessToken)
-	response, err := util.PostJSON(uri, req)
+	response, err := util.PostJSONContext(util.WithIdempotent(draft.RequestContext(ctx)), uri, req)
 	if err != nil {
 		return
 	}
@@ -95,7 +106,12 @@ func (draft *Draft) GetDraft(mediaID string) (articles []*Article, err error) {
 
 // DeleteDraft 删除草稿
 func (draft *Draft) DeleteDraft(mediaID string) (err error) {
-	accessToken, err := draft.GetAccessToken()
+	return draft.DeleteDraftContext(context2.Background(), mediaID)
+}
+
+// DeleteDraftContext 删除草稿
+func (draft *Draft) DeleteDraftContext(ctx context2.Context, mediaID string) (err error) {
+	accessToken, err := draft.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -107,7 +123,7 @@ func (draft *Draft) DeleteDraft(mediaID string) (err error) {
 
 	var response []byte
 	uri := fmt.Sprintf("%s/cgi-bin/draft/delete?access_token=%s", draft.Server, accessToken)
-	response, err = util.PostJSON(uri, req)
+	response, err = util.PostJSONContext(draft.RequestContext(ctx), uri, req)
 	if err != nil {
 		return
 	}
@@ -119,7 +135,13 @@ func (draft *Draft) DeleteDraft(mediaID string) (err error) {
 // UpdateDraft 修改草稿
 // index 要更新的文章在图文消息中的位置（多图文消息时，此字段才有意义），第一篇为0
 func (draft *Draft) UpdateDraft(article *Article, mediaID string, index uint) (err error) {
-	accessToken, err := draft.GetAccessToken()
+	return draft.UpdateDraftContext(context2.Background(), article, mediaID, index)
+}
+
+// UpdateDraftContext 修改草稿
+// index 要更新的文章在图文消息中的位置（多图文消息时，此字段才有意义），第一篇为0
+func (draft *Draft) UpdateDraftContext(ctx context2.Context, article *Article, mediaID string, index uint) (err error) {
+	accessToken, err := draft.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -135,7 +157,7 @@ func (draft *Draft) UpdateDraft(article *Article, mediaID string, index uint) (e
 
 	uri := fmt.Sprintf("%s/cgi-bin/draft/update?access_token=%s", draft.Server, accessToken)
 	var response []byte
-	response, err = util.PostJSON(uri, req)
+	response, err = util.PostJSONContext(draft.RequestContext(ctx), uri, req)
 	if err != nil {
 		return
 	}
@@ -146,14 +168,19 @@ func (draft *Draft) UpdateDraft(article *Article, mediaID string, index uint) (e
 
 // CountDraft 获取草稿总数
 func (draft *Draft) CountDraft() (total uint, err error) {
-	accessToken, err := draft.GetAccessToken()
+	return draft.CountDraftContext(context2.Background())
+}
+
+// CountDraftContext 获取草稿总数
+func (draft *Draft) CountDraftContext(ctx context2.Context) (total uint, err error) {
+	accessToken, err := draft.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 
 	var response []byte
 	uri := fmt.Sprintf("%s/cgi-bin/draft/count?access_token=%s", draft.Server, accessToken)
-	response, err = util.HTTPGet(uri)
+	response, err = util.HTTPGetContext(draft.RequestContext(ctx), uri)
 	if err != nil {
 		return
 	}
@@ -193,7 +220,12 @@ type ArticleListContent struct {
 
 // PaginateDraft 获取草稿列表
 func (draft *Draft) PaginateDraft(offset, count int64, noReturnContent bool) (list ArticleList, err error) {
-	accessToken, err := draft.GetAccessToken()
+	return draft.PaginateDraftContext(context2.Background(), offset, count, noReturnContent)
+}
+
+// PaginateDraftContext 获取草稿列表
+func (draft *Draft) PaginateDraftContext(ctx context2.Context, offset, count int64, noReturnContent bool) (list ArticleList, err error) {
+	accessToken, err := draft.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -209,7 +241,7 @@ func (draft *Draft) PaginateDraft(offset, count int64, noReturnContent bool) (li
 
 	var response []byte
 	uri := fmt.Sprintf("%s/cgi-bin/draft/batchget?access_token=%s", draft.Server, accessToken)
-	response, err = util.PostJSON(uri, req)
+	response, err = util.PostJSONContext(util.WithIdempotent(draft.RequestContext(ctx)), uri, req)
 	if err != nil {
 		return
 	}
@@ -1,6 +1,7 @@
 package js
 
 import (
+	context2 "context"
 	"fmt"
 
 	"github.com/amazing-gao/wechat/v2/credential"
@@ -27,6 +28,8 @@ func NewJs(context *context.Context) *Js {
 	js := new(Js)
 	js.Context = context
 	jsTicketHandle := credential.NewDefaultJsTicket(context.Server, context.AppID, credential.CacheKeyOfficialAccountPrefix, context.Cache)
+	jsTicketHandle.SetHTTPClient(context.HTTPDoer())
+	jsTicketHandle.SetRefreshHook(context.RefreshHook)
 	js.SetJsTicketHandle(jsTicketHandle)
 	return js
 }
@@ -39,14 +42,20 @@ func (js *Js) SetJsTicketHandle(ticketHandle credential.JsTicketHandle) {
 // GetConfig 获取jssdk需要的配置参数
 // uri 为当前网页地址
 func (js *Js) GetConfig(uri string) (config *Config, err error) {
+	return js.GetConfigContext(context2.Background(), uri)
+}
+
+// GetConfigContext 获取jssdk需要的配置参数
+// uri 为当前网页地址
+func (js *Js) GetConfigContext(ctx context2.Context, uri string) (config *Config, err error) {
 	config = new(Config)
 	var accessToken string
-	accessToken, err = js.GetAccessToken()
+	accessToken, err = js.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 	var ticketStr string
-	ticketStr, err = js.GetTicket(accessToken)
+	ticketStr, err = js.getTicket(ctx, accessToken)
 	if err != nil {
 		return
 	}
@@ -62,3 +71,11 @@ func (js *Js) GetConfig(uri string) (config *Config, err error) {
 	config.Signature = sigStr
 	return
 }
+
+// getTicket 优先使用支持context的ticket获取方式
+func (js *Js) getTicket(ctx context2.Context, accessToken string) (string, error) {
+	if handle, ok := js.JsTicketHandle.(credential.JsTicketContextHandle); ok {
+		return handle.GetTicketContext(js.RequestContext(ctx), accessToken)
+	}
+	return js.GetTicket(accessToken)
+}
@@ -1,6 +1,7 @@
 package material
 
 import (
+	context2 "context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -51,7 +52,12 @@ type Article struct {
 
 // GetNews 获取/下载永久素材
 func (material *Material) GetNews(id string) ([]*Article, error) {
-	accessToken, err := material.GetAccessToken()
+	return material.GetNewsContext(context2.Background(), id)
+}
+
+// GetNewsContext 获取/下载永久素材
+func (material *Material) GetNewsContext(ctx context2.Context, id string) ([]*Article, error) {
+	accessToken, err := material.GetAccessTokenContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +67,7 @@ func (material *Material) GetNews(id string) ([]*Article, error) {
 		MediaID string `json:"media_id"`
 	}
 	req.MediaID = id
-	responseBytes, err := util.PostJSON(uri, req)
+	responseBytes, err := util.PostJSONContext(util.WithIdempotent(material.RequestContext(ctx)), uri, req)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +85,12 @@ func (material *Material) GetNews(id string) ([]*Article, error) {
 
 // GetMaterial 获取/下载永久素材
 func (material *Material) GetMaterial(id string) ([]byte, error) {
-	accessToken, err := material.GetAccessToken()
+	return material.GetMaterialContext(context2.Background(), id)
+}
+
+// GetMaterialContext 获取/下载永久素材
+func (material *Material) GetMaterialContext(ctx context2.Context, id string) ([]byte, error) {
+	accessToken, err := material.GetAccessTokenContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +100,7 @@ func (material *Material) GetMaterial(id string) ([]byte, error) {
 		MediaID string `json:"media_id"`
 	}
 	req.MediaID = id
-	responseBytes, err := util.PostJSON(uri, req)
+	responseBytes, err := util.PostJSONContext(util.WithIdempotent(material.RequestContext(ctx)), uri, req)
 	if err != nil {
 		return nil, err
 	}
@@ -111,16 +122,21 @@ type resArticles struct {
 
 // AddNews 新增永久图文素材
 func (material *Material) AddNews(articles []*Article) (mediaID string, err error) {
+	return material.AddNewsContext(context2.Background(), articles)
+}
+
+// AddNewsContext 新增永久图文素材
+func (material *Material) AddNewsContext(ctx context2.Context, articles []*Article) (mediaID string, err error) {
 	req := &reqArticles{articles}
 
 	var accessToken string
-	accessToken, err = material.GetAccessToken()
+	accessToken, err = material.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 
 	uri := fmt.Sprintf("%s/cgi-bin/material/add_news?access_token=%s", material.Server, accessToken)
-	responseBytes, err := util.PostJSON(uri, req)
+	responseBytes, err := util.PostJSONContext(material.RequestContext(ctx), uri, req)
 	if err != nil {
 		return
 	}
@@ -130,7 +146,7 @@ func (material *Material) AddNews(articles []*Article) (mediaID string, err erro
 		return
 	}
 	if res.ErrCode != 0 {
-		return "", fmt.Errorf("errcode=%d,errmsg=%s", res.ErrCode, res.ErrMsg)
+		return "", util.NewError("AddNews", res.ErrCode, res.ErrMsg)
 	}
 	mediaID = res.MediaID
 	return
@@ -145,17 +161,22 @@ type reqUpdateArticle struct {
 
 // UpdateNews 更新永久图文素材
 func (material *Material) UpdateNews(article *Article, mediaID string, index int64) (err error) {
+	return material.UpdateNewsContext(context2.Background(), article, mediaID, index)
+}
+
+// UpdateNewsContext 更新永久图文素材
+func (material *Material) UpdateNewsContext(ctx context2.Context, article *Article, mediaID string, index int64) (err error) {
 	req := &reqUpdateArticle{mediaID, index, article}
 
 	var accessToken string
-	accessToken, err = material.GetAccessToken()
+	accessToken, err = material.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 
 	uri := fmt.Sprintf("%s/cgi-bin/material/update_news?access_token=%s", material.Server, accessToken)
 	var response []byte
-	response, err = util.PostJSON(uri, req)
+	response, err = util.PostJSONContext(material.RequestContext(ctx), uri, req)
 	if err != nil {
 		return
 	}
@@ -172,19 +193,24 @@ type resAddMaterial struct {
 
 // AddMaterial 上传永久性素材（处理视频需要单独上传）
 func (material *Material) AddMaterial(mediaType MediaType, filename string) (mediaID string, url string, err error) {
+	return material.AddMaterialContext(context2.Background(), mediaType, filename)
+}
+
+// AddMaterialContext 上传永久性素材（处理视频需要单独上传）
+func (material *Material) AddMaterialContext(ctx context2.Context, mediaType MediaType, filename string) (mediaID string, url string, err error) {
 	if mediaType == MediaTypeVideo {
 		err = errors.New("永久视频素材上传使用 AddVideo 方法")
 		return
 	}
 	var accessToken string
-	accessToken, err = material.GetAccessToken()
+	accessToken, err = material.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 
 	uri := fmt.Sprintf("%s/cgi-bin/material/add_material?access_token=%s&type=%s", material.Server, accessToken, mediaType)
 	var response []byte
-	response, err = util.PostFile("media", filename, uri)
+	response, err = util.PostFileContext(material.RequestContext(ctx), "media", filename, uri)
 	if err != nil {
 		return
 	}
@@ -194,7 +220,7 @@ func (material *Material) AddMaterial(mediaType MediaType, filename string) (med
 		return
 	}
 	if resMaterial.ErrCode != 0 {
-		err = fmt.Errorf("AddMaterial error : errcode=%v , errmsg=%v", resMaterial.ErrCode, resMaterial.ErrMsg)
+		err = util.NewError("AddMaterial", resMaterial.ErrCode, resMaterial.ErrMsg)
 		return
 	}
 	mediaID = resMaterial.MediaID
@@ -209,8 +235,13 @@ type reqVideo struct {
 
 // AddVideo 永久视频素材文件上传
 func (material *Material) AddVideo(filename, title, introduction string) (mediaID string, url string, err error) {
+	return material.AddVideoContext(context2.Background(), filename, title, introduction)
+}
+
+// AddVideoContext 永久视频素材文件上传
+func (material *Material) AddVideoContext(ctx context2.Context, filename, title, introduction string) (mediaID string, url string, err error) {
 	var accessToken string
-	accessToken, err = material.GetAccessToken()
+	accessToken, err = material.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -241,7 +272,7 @@ func (material *Material) AddVideo(filename, title, introduction string) (mediaI
 	}
 
 	var response []byte
-	response, err = util.PostMultipartForm(fields, uri)
+	response, err = util.PostMultipartFormContext(material.RequestContext(ctx), fields, uri)
 	if err != nil {
 		return
 	}
@@ -252,7 +283,7 @@ func (material *Material) AddVideo(filename, title, introduction string) (mediaI
 		return
 	}
 	if resMaterial.ErrCode != 0 {
-		err = fmt.Errorf("AddMaterial error : errcode=%v , errmsg=%v", resMaterial.ErrCode, resMaterial.ErrMsg)
+		err = util.NewError("AddMaterial", resMaterial.ErrCode, resMaterial.ErrMsg)
 		return
 	}
 	mediaID = resMaterial.MediaID
@@ -266,13 +297,18 @@ type reqDeleteMaterial struct {
 
 // DeleteMaterial 删除永久素材
 func (material *Material) DeleteMaterial(mediaID string) error {
-	accessToken, err := material.GetAccessToken()
+	return material.DeleteMaterialContext(context2.Background(), mediaID)
+}
+
+// DeleteMaterialContext 删除永久素材
+func (material *Material) DeleteMaterialContext(ctx context2.Context, mediaID string) error {
+	accessToken, err := material.GetAccessTokenContext(ctx)
 	if err != nil {
 		return err
 	}
 
 	uri := fmt.Sprintf("%s/cgi-bin/material/del_material?access_token=%s", material.Server, accessToken)
-	response, err := util.PostJSON(uri, reqDeleteMaterial{mediaID})
+	response, err := util.PostJSONContext(material.RequestContext(ctx), uri, reqDeleteMaterial{mediaID})
 	if err != nil {
 		return err
 	}
@@ -312,10 +348,18 @@ type reqBatchGetMaterial struct {
 }
 
 // BatchGetMaterial 批量获取永久素材
+//
 //reference:https://developers.weixin.qq.com/doc/offiaccount/Asset_Management/Get_materials_list.html
 func (material *Material) BatchGetMaterial(permanentMaterialType PermanentMaterialType, offset, count int64) (list ArticleList, err error) {
+	return material.BatchGetMaterialContext(context2.Background(), permanentMaterialType, offset, count)
+}
+
+// BatchGetMaterialContext 批量获取永久素材
+//
+//reference:https://developers.weixin.qq.com/doc/offiaccount/Asset_Management/Get_materials_list.html
+func (material *Material) BatchGetMaterialContext(ctx context2.Context, permanentMaterialType PermanentMaterialType, offset, count int64) (list ArticleList, err error) {
 	var accessToken string
-	accessToken, err = material.GetAccessToken()
+	accessToken, err = material.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -328,7 +372,7 @@ func (material *Material) BatchGetMaterial(permanentMaterialType PermanentMateri
 	}
 
 	var response []byte
-	response, err = util.PostJSON(uri, req)
+	response, err = util.PostJSONContext(util.WithIdempotent(material.RequestContext(ctx)), uri, req)
 	if err != nil {
 		return
 	}
@@ -348,14 +392,19 @@ type ResMaterialCount struct {
 
 // GetMaterialCount 获取素材总数.
 func (material *Material) GetMaterialCount() (res ResMaterialCount, err error) {
+	return material.GetMaterialCountContext(context2.Background())
+}
+
+// GetMaterialCountContext 获取素材总数.
+func (material *Material) GetMaterialCountContext(ctx context2.Context) (res ResMaterialCount, err error) {
 	var accessToken string
-	accessToken, err = material.GetAccessToken()
+	accessToken, err = material.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 	uri := fmt.Sprintf("%s/cgi-bin/material/get_materialcount?access_token=%s", material.Server, accessToken)
 	var response []byte
-	response, err = util.HTTPGet(uri)
+	response, err = util.HTTPGetContext(material.RequestContext(ctx), uri)
 	if err != nil {
 		return
 	}
@@ -1,6 +1,7 @@
 package material
 
 import (
+	context2 "context"
 	"encoding/json"
 	"fmt"
 
@@ -33,15 +34,20 @@ type Media struct {
 
 // MediaUpload 临时素材上传
 func (material *Material) MediaUpload(mediaType MediaType, filename string) (media Media, err error) {
+	return material.MediaUploadContext(context2.Background(), mediaType, filename)
+}
+
+// MediaUploadContext 临时素材上传
+func (material *Material) MediaUploadContext(ctx context2.Context, mediaType MediaType, filename string) (media Media, err error) {
 	var accessToken string
-	accessToken, err = material.GetAccessToken()
+	accessToken, err = material.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 
 	uri := fmt.Sprintf("%s/cgi-bin/media/upload?access_token=%s&type=%s", material.Server, accessToken, mediaType)
 	var response []byte
-	response, err = util.PostFile("media", filename, uri)
+	response, err = util.PostFileContext(material.RequestContext(ctx), "media", filename, uri)
 	if err != nil {
 		return
 	}
@@ -50,7 +56,7 @@ func (material *Material) MediaUpload(mediaType MediaType, filename string) (med
 		return
 	}
 	if media.ErrCode != 0 {
-		err = fmt.Errorf("MediaUpload error : errcode=%v , errmsg=%v", media.ErrCode, media.ErrMsg)
+		err = util.NewError("MediaUpload", media.ErrCode, media.ErrMsg)
 		return
 	}
 	return
@@ -59,8 +65,14 @@ func (material *Material) MediaUpload(mediaType MediaType, filename string) (med
 // GetMediaURL 返回临时素材的下载地址供用户自己处理
 // NOTICE: URL 不可公开，因为含access_token 需要立即另存文件
 func (material *Material) GetMediaURL(mediaID string) (mediaURL string, err error) {
+	return material.GetMediaURLContext(context2.Background(), mediaID)
+}
+
+// GetMediaURLContext 返回临时素材的下载地址供用户自己处理
+// NOTICE: URL 不可公开，因为含access_token 需要立即另存文件
+func (material *Material) GetMediaURLContext(ctx context2.Context, mediaID string) (mediaURL string, err error) {
 	var accessToken string
-	accessToken, err = material.GetAccessToken()
+	accessToken, err = material.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -77,15 +89,20 @@ type resMediaImage struct {
 
 // ImageUpload 图片上传
 func (material *Material) ImageUpload(filename string) (url string, err error) {
+	return material.ImageUploadContext(context2.Background(), filename)
+}
+
+// ImageUploadContext 图片上传
+func (material *Material) ImageUploadContext(ctx context2.Context, filename string) (url string, err error) {
 	var accessToken string
-	accessToken, err = material.GetAccessToken()
+	accessToken, err = material.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 
 	uri := fmt.Sprintf("%s/cgi-bin/media/uploadimg?access_token=%s", material.Server, accessToken)
 	var response []byte
-	response, err = util.PostFile("media", filename, uri)
+	response, err = util.PostFileContext(material.RequestContext(ctx), "media", filename, uri)
 	if err != nil {
 		return
 	}
@@ -95,7 +112,7 @@ func (material *Material) ImageUpload(filename string) (url string, err error) {
 		return
 	}
 	if image.ErrCode != 0 {
-		err = fmt.Errorf("UploadImage error : errcode=%v , errmsg=%v", image.ErrCode, image.ErrMsg)
+		err = util.NewError("UploadImage", image.ErrCode, image.ErrMsg)
 		return
 	}
 	url = image.URL
@@ -1,6 +1,7 @@
 package menu
 
 import (
+	context2 "context"
 	"encoding/json"
 	"fmt"
 
@@ -110,7 +111,12 @@ func NewMenu(context *context.Context) *Menu {
 
 // SetMenu 设置按钮
 func (menu *Menu) SetMenu(buttons []*Button) error {
-	accessToken, err := menu.GetAccessToken()
+	return menu.SetMenuContext(context2.Background(), buttons)
+}
+
+// SetMenuContext 设置按钮
+func (menu *Menu) SetMenuContext(ctx context2.Context, buttons []*Button) error {
+	accessToken, err := menu.GetAccessTokenContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -120,7 +126,7 @@ func (menu *Menu) SetMenu(buttons []*Button) error {
 		Button: buttons,
 	}
 
-	response, err := util.PostJSON(uri, reqMenu)
+	response, err := util.PostJSONContext(menu.RequestContext(ctx), uri, reqMenu)
 	if err != nil {
 		return err
 	}
@@ -130,14 +136,19 @@ func (menu *Menu) SetMenu(buttons []*Button) error {
 
 // SetMenuByJSON 设置按钮
 func (menu *Menu) SetMenuByJSON(jsonInfo string) error {
-	accessToken, err := menu.GetAccessToken()
+	return menu.SetMenuByJSONContext(context2.Background(), jsonInfo)
+}
+
+// SetMenuByJSONContext 设置按钮
+func (menu *Menu) SetMenuByJSONContext(ctx context2.Context, jsonInfo string) error {
+	accessToken, err := menu.GetAccessTokenContext(ctx)
 	if err != nil {
 		return err
 	}
 
 	uri := fmt.Sprintf("%s/cgi-bin/menu/create?access_token=%s", menu.Server, accessToken)
 
-	response, err := util.HTTPPost(uri, jsonInfo)
+	response, err := util.HTTPPostContext(menu.RequestContext(ctx), uri, jsonInfo)
 	if err != nil {
 		return err
 	}
@@ -147,14 +158,19 @@ func (menu *Menu) SetMenuByJSON(jsonInfo string) error {
 
 // GetMenu 获取菜单配置
 func (menu *Menu) GetMenu() (resMenu ResMenu, err error) {
+	return menu.GetMenuContext(context2.Background())
+}
+
+// GetMenuContext 获取菜单配置
+func (menu *Menu) GetMenuContext(ctx context2.Context) (resMenu ResMenu, err error) {
 	var accessToken string
-	accessToken, err = menu.GetAccessToken()
+	accessToken, err = menu.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 	uri := fmt.Sprintf("%s/cgi-bin/menu/get?access_token=%s", menu.Server, accessToken)
 	var response []byte
-	response, err = util.HTTPGet(uri)
+	response, err = util.HTTPGetContext(menu.RequestContext(ctx), uri)
 	if err != nil {
 		return
 	}
@@ -163,7 +179,7 @@ func (menu *Menu) GetMenu() (resMenu ResMenu, err error) {
 		return
 	}
 	if resMenu.ErrCode != 0 {
-		err = fmt.Errorf("GetMenu Error , errcode=%d , errmsg=%s", resMenu.ErrCode, resMenu.ErrMsg)
+		err = util.NewError("GetMenu", resMenu.ErrCode, resMenu.ErrMsg)
 		return
 	}
 	return
@@ -171,12 +187,17 @@ func (menu *Menu) GetMenu() (resMenu ResMenu, err error) {
 
 // DeleteMenu 删除菜单
 func (menu *Menu) DeleteMenu() error {
-	accessToken, err := menu.GetAccessToken()
+	return menu.DeleteMenuContext(context2.Background())
+}
+
+// DeleteMenuContext 删除菜单
+func (menu *Menu) DeleteMenuContext(ctx context2.Context) error {
+	accessToken, err := menu.GetAccessTokenContext(ctx)
 	if err != nil {
 		return err
 	}
 	uri := fmt.Sprintf("%s/cgi-bin/menu/delete?access_token=%s", menu.Server, accessToken)
-	response, err := util.HTTPGet(uri)
+	response, err := util.HTTPGetContext(menu.RequestContext(ctx), uri)
 	if err != nil {
 		return err
 	}
@@ -186,7 +207,12 @@ func (menu *Menu) DeleteMenu() error {
 
 // AddConditional 添加个性化菜单
 func (menu *Menu) AddConditional(buttons []*Button, matchRule *MatchRule) error {
-	accessToken, err := menu.GetAccessToken()
+	return menu.AddConditionalContext(context2.Background(), buttons, matchRule)
+}
+
+// AddConditionalContext 添加个性化菜单
+func (menu *Menu) AddConditionalContext(ctx context2.Context, buttons []*Button, matchRule *MatchRule) error {
+	accessToken, err := menu.GetAccessTokenContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -197,7 +223,7 @@ func (menu *Menu) AddConditional(buttons []*Button, matchRule *MatchRule) error
 		MatchRule: matchRule,
 	}
 
-	response, err := util.PostJSON(uri, reqMenu)
+	response, err := util.PostJSONContext(menu.RequestContext(ctx), uri, reqMenu)
 	if err != nil {
 		return err
 	}
@@ -207,13 +233,18 @@ func (menu *Menu) AddConditional(buttons []*Button, matchRule *MatchRule) error
 
 // AddConditionalByJSON 添加个性化菜单
 func (menu *Menu) AddConditionalByJSON(jsonInfo string) error {
-	accessToken, err := menu.GetAccessToken()
+	return menu.AddConditionalByJSONContext(context2.Background(), jsonInfo)
+}
+
+// AddConditionalByJSONContext 添加个性化菜单
+func (menu *Menu) AddConditionalByJSONContext(ctx context2.Context, jsonInfo string) error {
+	accessToken, err := menu.GetAccessTokenContext(ctx)
 	if err != nil {
 		return err
 	}
 
 	uri := fmt.Sprintf("%s/cgi-bin/menu/addconditional?access_token=%s", menu.Server, accessToken)
-	response, err := util.HTTPPost(uri, jsonInfo)
+	response, err := util.HTTPPostContext(menu.RequestContext(ctx), uri, jsonInfo)
 	if err != nil {
 		return err
 	}
@@ -223,7 +254,12 @@ func (menu *Menu) AddConditionalByJSON(jsonInfo string) error {
 
 // DeleteConditional 删除个性化菜单
 func (menu *Menu) DeleteConditional(menuID int64) error {
-	accessToken, err := menu.GetAccessToken()
+	return menu.DeleteConditionalContext(context2.Background(), menuID)
+}
+
+// DeleteConditionalContext 删除个性化菜单
+func (menu *Menu) DeleteConditionalContext(ctx context2.Context, menuID int64) error {
+	accessToken, err := menu.GetAccessTokenContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -233,7 +269,7 @@ func (menu *Menu) DeleteConditional(menuID int64) error {
 		MenuID: menuID,
 	}
 
-	response, err := util.PostJSON(uri, reqDeleteConditional)
+	response, err := util.PostJSONContext(menu.RequestContext(ctx), uri, reqDeleteConditional)
 	if err != nil {
 		return err
 	}
@@ -243,15 +279,20 @@ func (menu *Menu) DeleteConditional(menuID int64) error {
 
 // MenuTryMatch 菜单匹配
 func (menu *Menu) MenuTryMatch(userID string) (buttons []Button, err error) {
+	return menu.MenuTryMatchContext(context2.Background(), userID)
+}
+
+// MenuTryMatchContext 菜单匹配
+func (menu *Menu) MenuTryMatchContext(ctx context2.Context, userID string) (buttons []Button, err error) {
 	var accessToken string
-	accessToken, err = menu.GetAccessToken()
+	accessToken, err = menu.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 	uri := fmt.Sprintf("%s/cgi-bin/menu/trymatch?access_token=%s", menu.Server, accessToken)
 	reqMenuTryMatch := &reqMenuTryMatch{userID}
 	var response []byte
-	response, err = util.PostJSON(uri, reqMenuTryMatch)
+	response, err = util.PostJSONContext(util.WithIdempotent(menu.RequestContext(ctx)), uri, reqMenuTryMatch)
 	if err != nil {
 		return
 	}
@@ -261,7 +302,7 @@ func (menu *Menu) MenuTryMatch(userID string) (buttons []Button, err error) {
 		return
 	}
 	if resMenuTryMatch.ErrCode != 0 {
-		err = fmt.Errorf("MenuTryMatch Error , errcode=%d , errmsg=%s", resMenuTryMatch.ErrCode, resMenuTryMatch.ErrMsg)
+		err = util.NewError("MenuTryMatch", resMenuTryMatch.ErrCode, resMenuTryMatch.ErrMsg)
 		return
 	}
 	buttons = resMenuTryMatch.Button
@@ -270,14 +311,19 @@ func (menu *Menu) MenuTryMatch(userID string) (buttons []Button, err error) {
 
 // GetCurrentSelfMenuInfo 获取自定义菜单配置接口
 func (menu *Menu) GetCurrentSelfMenuInfo() (resSelfMenuInfo ResSelfMenuInfo, err error) {
+	return menu.GetCurrentSelfMenuInfoContext(context2.Background())
+}
+
+// GetCurrentSelfMenuInfoContext 获取自定义菜单配置接口
+func (menu *Menu) GetCurrentSelfMenuInfoContext(ctx context2.Context) (resSelfMenuInfo ResSelfMenuInfo, err error) {
 	var accessToken string
-	accessToken, err = menu.GetAccessToken()
+	accessToken, err = menu.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 	uri := fmt.Sprintf("%s/cgi-bin/get_current_selfmenu_info?access_token=%s", menu.Server, accessToken)
 	var response []byte
-	response, err = util.HTTPGet(uri)
+	response, err = util.HTTPGetContext(menu.RequestContext(ctx), uri)
 	if err != nil {
 		return
 	}
@@ -286,7 +332,7 @@ func (menu *Menu) GetCurrentSelfMenuInfo() (resSelfMenuInfo ResSelfMenuInfo, err
 		return
 	}
 	if resSelfMenuInfo.ErrCode != 0 {
-		err = fmt.Errorf("GetCurrentSelfMenuInfo Error , errcode=%d , errmsg=%s", resSelfMenuInfo.ErrCode, resSelfMenuInfo.ErrMsg)
+		err = util.NewError("GetCurrentSelfMenuInfo", resSelfMenuInfo.ErrCode, resSelfMenuInfo.ErrMsg)
 		return
 	}
 	return
@@ -1,6 +1,7 @@
 package message
 
 import (
+	context2 "context"
 	"encoding/json"
 	"fmt"
 
@@ -151,12 +152,17 @@ type MediaMiniprogrampage struct {
 
 // Send 发送客服消息
 func (manager *Manager) Send(msg *CustomerMessage) error {
-	accessToken, err := manager.Context.GetAccessToken()
+	return manager.SendContext(context2.Background(), msg)
+}
+
+// SendContext 发送客服消息
+func (manager *Manager) SendContext(ctx context2.Context, msg *CustomerMessage) error {
+	accessToken, err := manager.Context.GetAccessTokenContext(ctx)
 	if err != nil {
 		return err
 	}
 	uri := fmt.Sprintf("%s/cgi-bin/message/custom/send?access_token=%s", manager.Server, accessToken)
-	response, err := util.PostJSON(uri, msg)
+	response, err := util.PostJSONContext(manager.RequestContext(ctx), uri, msg)
 	if err != nil {
 		return err
 	}
@@ -166,9 +172,55 @@ func (manager *Manager) Send(msg *CustomerMessage) error {
 		return err
 	}
 	if result.ErrCode != 0 {
-		err = fmt.Errorf("customer msg send error : errcode=%v , errmsg=%v", result.ErrCode, result.ErrMsg)
+		err = util.NewError("customer msg send", result.ErrCode, result.ErrMsg)
 		return err
 	}
 
 	return nil
 }
+
+// NewCustomerMessageFromReply 将被动回复的消息转换为发送给toUser的客服消息
+// 支持文本、图片、语音、视频、音乐及图文消息，其他类型返回 ErrUnsupportReply
+func NewCustomerMessageFromReply(toUser string, reply *Reply) (*CustomerMessage, error) {
+	if reply == nil || reply.MsgData == nil {
+		return nil, ErrInvalidReply
+	}
+	msg := &CustomerMessage{ToUser: toUser}
+	switch data := reply.MsgData.(type) {
+	case *Text:
+		msg.Msgtype = MsgTypeText
+		msg.Text = &MediaText{Content: string(data.Content)}
+	case *Image:
+		msg.Msgtype = MsgTypeImage
+		msg.Image = &MediaResource{MediaID: data.Image.MediaID}
+	case *Voice:
+		msg.Msgtype = MsgTypeVoice
+		msg.Voice = &MediaResource{MediaID: data.Voice.MediaID}
+	case *Video:
+		msg.Msgtype = MsgTypeVideo
+		msg.Video = &MediaVideo{MediaID: data.Video.MediaID, Title: data.Video.Title, Description: data.Video.Description}
+	case *Music:
+		msg.Msgtype = MsgTypeMusic
+		msg.Music = &MediaMusic{
+			Title:        data.Music.Title,
+			Description:  data.Music.Description,
+			Musicurl:     data.Music.MusicURL,
+			Hqmusicurl:   data.Music.HQMusicURL,
+			ThumbMediaID: data.Music.ThumbMediaID,
+		}
+	case *News:
+		msg.Msgtype = MsgTypeNews
+		msg.News = &MediaNews{Articles: make([]MediaArticles, 0, len(data.Articles))}
+		for _, article := range data.Articles {
+			msg.News.Articles = append(msg.News.Articles, MediaArticles{
+				Title:       article.Title,
+				Description: article.Description,
+				URL:         article.URL,
+				Picurl:      article.PicURL,
+			})
+		}
+	default:
+		return nil, ErrUnsupportReply
+	}
+	return msg, nil
+}
@@ -1,6 +1,7 @@
 package message
 
 import (
+	context2 "context"
 	"fmt"
 
 	"github.com/amazing-gao/wechat/v2/officialaccount/context"
@@ -38,13 +39,18 @@ type SubscribeDataItem struct {
 
 // Send 发送订阅消息
 func (tpl *Subscribe) Send(msg *SubscribeMessage) (err error) {
+	return tpl.SendContext(context2.Background(), msg)
+}
+
+// SendContext 发送订阅消息
+func (tpl *Subscribe) SendContext(ctx context2.Context, msg *SubscribeMessage) (err error) {
 	var accessToken string
-	accessToken, err = tpl.GetAccessToken()
+	accessToken, err = tpl.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 	uri := fmt.Sprintf("%s/cgi-bin/message/subscribe/bizsend?access_token=%s", tpl.Server, accessToken)
-	response, err := util.PostJSON(uri, msg)
+	response, err := util.PostJSONContext(tpl.RequestContext(ctx), uri, msg)
 	if err != nil {
 		return
 	}
@@ -67,14 +73,19 @@ type resPrivateSubscribeList struct {
 
 // List 获取私有订阅消息模板列表
 func (tpl *Subscribe) List() (templateList []*PrivateSubscribeItem, err error) {
+	return tpl.ListContext(context2.Background())
+}
+
+// ListContext 获取私有订阅消息模板列表
+func (tpl *Subscribe) ListContext(ctx context2.Context) (templateList []*PrivateSubscribeItem, err error) {
 	var accessToken string
-	accessToken, err = tpl.GetAccessToken()
+	accessToken, err = tpl.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 	uri := fmt.Sprintf("%s/wxaapi/newtmpl/gettemplate?access_token=%s", tpl.Server, accessToken)
 	var response []byte
-	response, err = util.HTTPGet(uri)
+	response, err = util.HTTPGetContext(tpl.RequestContext(ctx), uri)
 	if err != nil {
 		return
 	}
@@ -95,8 +106,13 @@ type resSubscribeAdd struct {
 
 // Add 添加订阅消息模板
 func (tpl *Subscribe) Add(ShortID string, kidList []int, sceneDesc string) (templateID string, err error) {
+	return tpl.AddContext(context2.Background(), ShortID, kidList, sceneDesc)
+}
+
+// AddContext 添加订阅消息模板
+func (tpl *Subscribe) AddContext(ctx context2.Context, ShortID string, kidList []int, sceneDesc string) (templateID string, err error) {
 	var accessToken string
-	accessToken, err = tpl.GetAccessToken()
+	accessToken, err = tpl.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -107,7 +123,7 @@ func (tpl *Subscribe) Add(ShortID string, kidList []int, sceneDesc string) (temp
 	}{TemplateIDShort: ShortID, SceneDesc: sceneDesc, KidList: kidList}
 	uri := fmt.Sprintf("%s/wxaapi/newtmpl/addtemplate?access_token=%s", tpl.Server, accessToken)
 	var response []byte
-	response, err = util.PostJSON(uri, msg)
+	response, err = util.PostJSONContext(tpl.RequestContext(ctx), uri, msg)
 	if err != nil {
 		return
 	}
@@ -122,8 +138,13 @@ func (tpl *Subscribe) Add(ShortID string, kidList []int, sceneDesc string) (temp
 
 // Delete 删除私有模板
 func (tpl *Subscribe) Delete(templateID string) (err error) {
+	return tpl.DeleteContext(context2.Background(), templateID)
+}
+
+// DeleteContext 删除私有模板
+func (tpl *Subscribe) DeleteContext(ctx context2.Context, templateID string) (err error) {
 	var accessToken string
-	accessToken, err = tpl.GetAccessToken()
+	accessToken, err = tpl.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -132,7 +153,7 @@ func (tpl *Subscribe) Delete(templateID string) (err error) {
 	}{TemplateID: templateID}
 	uri := fmt.Sprintf("%s/wxaapi/newtmpl/deltemplate?access_token=%s", tpl.Server, accessToken)
 	var response []byte
-	response, err = util.PostJSON(uri, msg)
+	response, err = util.PostJSONContext(tpl.RequestContext(ctx), uri, msg)
 	if err != nil {
 		return
 	}
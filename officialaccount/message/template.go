@@ -1,6 +1,7 @@
 package message
 
 import (
+	context2 "context"
 	"encoding/json"
 	"fmt"
 
@@ -48,14 +49,19 @@ type resTemplateSend struct {
 
 // Send 发送模板消息
 func (tpl *Template) Send(msg *TemplateMessage) (msgID int64, err error) {
+	return tpl.SendContext(context2.Background(), msg)
+}
+
+// SendContext 发送模板消息
+func (tpl *Template) SendContext(ctx context2.Context, msg *TemplateMessage) (msgID int64, err error) {
 	var accessToken string
-	accessToken, err = tpl.GetAccessToken()
+	accessToken, err = tpl.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 	uri := fmt.Sprintf("%s/cgi-bin/message/template/send?access_token=%s", tpl.Server, accessToken)
 	var response []byte
-	response, err = util.PostJSON(uri, msg)
+	response, err = util.PostJSONContext(tpl.RequestContext(ctx), uri, msg)
 	if err != nil {
 		return
 	}
@@ -65,7 +71,7 @@ func (tpl *Template) Send(msg *TemplateMessage) (msgID int64, err error) {
 		return
 	}
 	if result.ErrCode != 0 {
-		err = fmt.Errorf("template msg send error : errcode=%v , errmsg=%v", result.ErrCode, result.ErrMsg)
+		err = util.NewError("template msg send", result.ErrCode, result.ErrMsg)
 		return
 	}
 	msgID = result.MsgID
@@ -90,14 +96,19 @@ type resTemplateList struct {
 
 // List 获取模板列表
 func (tpl *Template) List() (templateList []*TemplateItem, err error) {
+	return tpl.ListContext(context2.Background())
+}
+
+// ListContext 获取模板列表
+func (tpl *Template) ListContext(ctx context2.Context) (templateList []*TemplateItem, err error) {
 	var accessToken string
-	accessToken, err = tpl.GetAccessToken()
+	accessToken, err = tpl.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 	uri := fmt.Sprintf("%s/cgi-bin/template/get_all_private_template?access_token=%s", tpl.Server, accessToken)
 	var response []byte
-	response, err = util.HTTPGet(uri)
+	response, err = util.HTTPGetContext(tpl.RequestContext(ctx), uri)
 	if err != nil {
 		return
 	}
@@ -118,8 +129,13 @@ type resTemplateAdd struct {
 
 // Add 添加模板.
 func (tpl *Template) Add(shortID string) (templateID string, err error) {
+	return tpl.AddContext(context2.Background(), shortID)
+}
+
+// AddContext 添加模板.
+func (tpl *Template) AddContext(ctx context2.Context, shortID string) (templateID string, err error) {
 	var accessToken string
-	accessToken, err = tpl.GetAccessToken()
+	accessToken, err = tpl.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -128,7 +144,7 @@ func (tpl *Template) Add(shortID string) (templateID string, err error) {
 	}{ShortID: shortID}
 	uri := fmt.Sprintf("%s/cgi-bin/template/api_add_template?access_token=%s", tpl.Server, accessToken)
 	var response []byte
-	response, err = util.PostJSON(uri, msg)
+	response, err = util.PostJSONContext(tpl.RequestContext(ctx), uri, msg)
 	if err != nil {
 		return
 	}
@@ -144,8 +160,13 @@ func (tpl *Template) Add(shortID string) (templateID string, err error) {
 
 // Delete 删除私有模板.
 func (tpl *Template) Delete(templateID string) (err error) {
+	return tpl.DeleteContext(context2.Background(), templateID)
+}
+
+// DeleteContext 删除私有模板.
+func (tpl *Template) DeleteContext(ctx context2.Context, templateID string) (err error) {
 	var accessToken string
-	accessToken, err = tpl.GetAccessToken()
+	accessToken, err = tpl.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -155,7 +176,7 @@ func (tpl *Template) Delete(templateID string) (err error) {
 
 	uri := fmt.Sprintf("%s/cgi-bin/template/del_private_template?access_token=%s", tpl.Server, accessToken)
 	var response []byte
-	response, err = util.PostJSON(uri, msg)
+	response, err = util.PostJSONContext(tpl.RequestContext(ctx), uri, msg)
 	if err != nil {
 		return
 	}
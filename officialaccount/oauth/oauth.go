@@ -1,6 +1,7 @@
 package oauth
 
 import (
+	context2 "context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -11,8 +12,8 @@ import (
 )
 
 const (
-	redirectOauthURL       = "https://open.weixin.qq.com/connect/oauth2/authorize?appid=%s&redirect_uri=%s&response_type=code&scope=%s&state=%s#wechat_redirect"
-	webAppRedirectOauthURL = "https://open.weixin.qq.com/connect/qrconnect?appid=%s&redirect_uri=%s&response_type=code&scope=%s&state=%s#wechat_redirect"
+	redirectOauthURL       = "%s/connect/oauth2/authorize?appid=%s&redirect_uri=%s&response_type=code&scope=%s&state=%s#wechat_redirect"
+	webAppRedirectOauthURL = "%s/connect/qrconnect?appid=%s&redirect_uri=%s&response_type=code&scope=%s&state=%s#wechat_redirect"
 )
 
 // Oauth 保存用户授权信息
@@ -31,13 +32,13 @@ func NewOauth(context *context.Context) *Oauth {
 func (oauth *Oauth) GetRedirectURL(redirectURI, scope, state string) (string, error) {
 	// url encode
 	urlStr := url.QueryEscape(redirectURI)
-	return fmt.Sprintf(redirectOauthURL, oauth.AppID, urlStr, scope, state), nil
+	return fmt.Sprintf(redirectOauthURL, oauth.OpenServer, oauth.AppID, urlStr, scope, state), nil
 }
 
 // GetWebAppRedirectURL 获取网页应用跳转的url地址
 func (oauth *Oauth) GetWebAppRedirectURL(redirectURI, scope, state string) (string, error) {
 	urlStr := url.QueryEscape(redirectURI)
-	return fmt.Sprintf(webAppRedirectOauthURL, oauth.AppID, urlStr, scope, state), nil
+	return fmt.Sprintf(webAppRedirectOauthURL, oauth.OpenServer, oauth.AppID, urlStr, scope, state), nil
 }
 
 // Redirect 跳转到网页授权
@@ -67,9 +68,14 @@ type ResAccessToken struct {
 
 // GetUserAccessToken 通过网页授权的code 换取access_token(区别于context中的access_token)
 func (oauth *Oauth) GetUserAccessToken(code string) (result ResAccessToken, err error) {
+	return oauth.GetUserAccessTokenContext(context2.Background(), code)
+}
+
+// GetUserAccessTokenContext 通过网页授权的code 换取access_token(区别于context中的access_token)
+func (oauth *Oauth) GetUserAccessTokenContext(ctx context2.Context, code string) (result ResAccessToken, err error) {
 	urlStr := fmt.Sprintf("%s/sns/oauth2/access_token?appid=%s&secret=%s&code=%s&grant_type=authorization_code", oauth.Server, oauth.AppID, oauth.AppSecret, code)
 	var response []byte
-	response, err = util.HTTPGet(urlStr)
+	response, err = util.HTTPGetContext(oauth.RequestContext(ctx), urlStr)
 	if err != nil {
 		return
 	}
@@ -78,7 +84,7 @@ func (oauth *Oauth) GetUserAccessToken(code string) (result ResAccessToken, err
 		return
 	}
 	if result.ErrCode != 0 {
-		err = fmt.Errorf("GetUserAccessToken error : errcode=%v , errmsg=%v", result.ErrCode, result.ErrMsg)
+		err = util.NewError("GetUserAccessToken", result.ErrCode, result.ErrMsg)
 		return
 	}
 	return
@@ -86,9 +92,14 @@ func (oauth *Oauth) GetUserAccessToken(code string) (result ResAccessToken, err
 
 // RefreshAccessToken 刷新access_token
 func (oauth *Oauth) RefreshAccessToken(refreshToken string) (result ResAccessToken, err error) {
+	return oauth.RefreshAccessTokenContext(context2.Background(), refreshToken)
+}
+
+// RefreshAccessTokenContext 刷新access_token
+func (oauth *Oauth) RefreshAccessTokenContext(ctx context2.Context, refreshToken string) (result ResAccessToken, err error) {
 	urlStr := fmt.Sprintf("%s/sns/oauth2/refresh_token?appid=%s&grant_type=refresh_token&refresh_token=%s", oauth.Server, oauth.AppID, refreshToken)
 	var response []byte
-	response, err = util.HTTPGet(urlStr)
+	response, err = util.HTTPGetContext(oauth.RequestContext(ctx), urlStr)
 	if err != nil {
 		return
 	}
@@ -97,7 +108,7 @@ func (oauth *Oauth) RefreshAccessToken(refreshToken string) (result ResAccessTok
 		return
 	}
 	if result.ErrCode != 0 {
-		err = fmt.Errorf("GetUserAccessToken error : errcode=%v , errmsg=%v", result.ErrCode, result.ErrMsg)
+		err = util.NewError("GetUserAccessToken", result.ErrCode, result.ErrMsg)
 		return
 	}
 	return
@@ -105,9 +116,14 @@ func (oauth *Oauth) RefreshAccessToken(refreshToken string) (result ResAccessTok
 
 // CheckAccessToken 检验access_token是否有效
 func (oauth *Oauth) CheckAccessToken(accessToken, openID string) (b bool, err error) {
+	return oauth.CheckAccessTokenContext(context2.Background(), accessToken, openID)
+}
+
+// CheckAccessTokenContext 检验access_token是否有效
+func (oauth *Oauth) CheckAccessTokenContext(ctx context2.Context, accessToken, openID string) (b bool, err error) {
 	urlStr := fmt.Sprintf("%s/sns/auth?access_token=%s&openid=%s", oauth.Server, accessToken, openID)
 	var response []byte
-	response, err = util.HTTPGet(urlStr)
+	response, err = util.HTTPGetContext(oauth.RequestContext(ctx), urlStr)
 	if err != nil {
 		return
 	}
@@ -141,12 +157,17 @@ type UserInfo struct {
 
 // GetUserInfo 如果scope为 snsapi_userinfo 则可以通过此方法获取到用户基本信息
 func (oauth *Oauth) GetUserInfo(accessToken, openID, lang string) (result UserInfo, err error) {
+	return oauth.GetUserInfoContext(context2.Background(), accessToken, openID, lang)
+}
+
+// GetUserInfoContext 如果scope为 snsapi_userinfo 则可以通过此方法获取到用户基本信息
+func (oauth *Oauth) GetUserInfoContext(ctx context2.Context, accessToken, openID, lang string) (result UserInfo, err error) {
 	if lang == "" {
 		lang = "zh_CN"
 	}
 	urlStr := fmt.Sprintf("%s/sns/userinfo?access_token=%s&openid=%s&lang=%s", oauth.Server, accessToken, openID, lang)
 	var response []byte
-	response, err = util.HTTPGet(urlStr)
+	response, err = util.HTTPGetContext(oauth.RequestContext(ctx), urlStr)
 	if err != nil {
 		return
 	}
@@ -155,7 +176,7 @@ func (oauth *Oauth) GetUserInfo(accessToken, openID, lang string) (result UserIn
 		return
 	}
 	if result.ErrCode != 0 {
-		err = fmt.Errorf("GetUserInfo error : errcode=%v , errmsg=%v", result.ErrCode, result.ErrMsg)
+		err = util.NewError("GetUserInfo", result.ErrCode, result.ErrMsg)
 		return
 	}
 	return
@@ -1,6 +1,7 @@
 package ocr
 
 import (
+	context2 "context"
 	"fmt"
 	"net/url"
 
@@ -145,14 +146,19 @@ func NewOCR(c *context.Context) *OCR {
 
 // IDCard 身份证OCR识别接口
 func (ocr *OCR) IDCard(path string) (ResIDCard ResIDCard, err error) {
-	accessToken, err := ocr.GetAccessToken()
+	return ocr.IDCardContext(context2.Background(), path)
+}
+
+// IDCardContext 身份证OCR识别接口
+func (ocr *OCR) IDCardContext(ctx context2.Context, path string) (ResIDCard ResIDCard, err error) {
+	accessToken, err := ocr.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 
 	uri := fmt.Sprintf("%s/cv/ocr/idcard?img_url=%s&access_token=%s", ocr.Server, url.QueryEscape(path), accessToken)
 
-	response, err := util.HTTPPost(uri, "")
+	response, err := util.HTTPPostContext(util.WithIdempotent(ocr.RequestContext(ctx)), uri, "")
 	if err != nil {
 		return
 	}
@@ -164,14 +170,19 @@ func (ocr *OCR) IDCard(path string) (ResIDCard ResIDCard, err error) {
 
 // BankCard 银行卡OCR识别接口
 func (ocr *OCR) BankCard(path string) (ResBankCard ResBankCard, err error) {
-	accessToken, err := ocr.GetAccessToken()
+	return ocr.BankCardContext(context2.Background(), path)
+}
+
+// BankCardContext 银行卡OCR识别接口
+func (ocr *OCR) BankCardContext(ctx context2.Context, path string) (ResBankCard ResBankCard, err error) {
+	accessToken, err := ocr.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 
 	uri := fmt.Sprintf("%s/cv/ocr/bankcard?img_url=%s&access_token=%s", ocr.Server, url.QueryEscape(path), accessToken)
 
-	response, err := util.HTTPPost(uri, "")
+	response, err := util.HTTPPostContext(util.WithIdempotent(ocr.RequestContext(ctx)), uri, "")
 	if err != nil {
 		return
 	}
@@ -183,14 +194,19 @@ func (ocr *OCR) BankCard(path string) (ResBankCard ResBankCard, err error) {
 
 // Driving 行驶证OCR识别接口
 func (ocr *OCR) Driving(path string) (ResDriving ResDriving, err error) {
-	accessToken, err := ocr.GetAccessToken()
+	return ocr.DrivingContext(context2.Background(), path)
+}
+
+// DrivingContext 行驶证OCR识别接口
+func (ocr *OCR) DrivingContext(ctx context2.Context, path string) (ResDriving ResDriving, err error) {
+	accessToken, err := ocr.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 
 	uri := fmt.Sprintf("%s/cv/ocr/driving?img_url=%s&access_token=%s", ocr.Server, url.QueryEscape(path), accessToken)
 
-	response, err := util.HTTPPost(uri, "")
+	response, err := util.HTTPPostContext(util.WithIdempotent(ocr.RequestContext(ctx)), uri, "")
 	if err != nil {
 		return
 	}
@@ -202,14 +218,19 @@ func (ocr *OCR) Driving(path string) (ResDriving ResDriving, err error) {
 
 // DrivingLicense 驾驶证OCR识别接口
 func (ocr *OCR) DrivingLicense(path string) (ResDrivingLicense ResDrivingLicense, err error) {
-	accessToken, err := ocr.GetAccessToken()
+	return ocr.DrivingLicenseContext(context2.Background(), path)
+}
+
+// DrivingLicenseContext 驾驶证OCR识别接口
+func (ocr *OCR) DrivingLicenseContext(ctx context2.Context, path string) (ResDrivingLicense ResDrivingLicense, err error) {
+	accessToken, err := ocr.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 
 	uri := fmt.Sprintf("%s/cv/ocr/drivinglicense?img_url=%s&access_token=%s", ocr.Server, url.QueryEscape(path), accessToken)
 
-	response, err := util.HTTPPost(uri, "")
+	response, err := util.HTTPPostContext(util.WithIdempotent(ocr.RequestContext(ctx)), uri, "")
 	if err != nil {
 		return
 	}
@@ -221,14 +242,19 @@ func (ocr *OCR) DrivingLicense(path string) (ResDrivingLicense ResDrivingLicense
 
 // BizLicense 营业执照OCR识别接口
 func (ocr *OCR) BizLicense(path string) (ResBizLicense ResBizLicense, err error) {
-	accessToken, err := ocr.GetAccessToken()
+	return ocr.BizLicenseContext(context2.Background(), path)
+}
+
+// BizLicenseContext 营业执照OCR识别接口
+func (ocr *OCR) BizLicenseContext(ctx context2.Context, path string) (ResBizLicense ResBizLicense, err error) {
+	accessToken, err := ocr.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 
 	uri := fmt.Sprintf("%s/cv/ocr/bizlicense?img_url=%s&access_token=%s", ocr.Server, url.QueryEscape(path), accessToken)
 
-	response, err := util.HTTPPost(uri, "")
+	response, err := util.HTTPPostContext(util.WithIdempotent(ocr.RequestContext(ctx)), uri, "")
 	if err != nil {
 		return
 	}
@@ -240,14 +266,19 @@ func (ocr *OCR) BizLicense(path string) (ResBizLicense ResBizLicense, err error)
 
 // Common 通用印刷体OCR识别接口
 func (ocr *OCR) Common(path string) (ResCommon ResCommon, err error) {
-	accessToken, err := ocr.GetAccessToken()
+	return ocr.CommonContext(context2.Background(), path)
+}
+
+// CommonContext 通用印刷体OCR识别接口
+func (ocr *OCR) CommonContext(ctx context2.Context, path string) (ResCommon ResCommon, err error) {
+	accessToken, err := ocr.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 
 	uri := fmt.Sprintf("%s/cv/ocr/comm?img_url=%s&access_token=%s", ocr.Server, url.QueryEscape(path), accessToken)
 
-	response, err := util.HTTPPost(uri, "")
+	response, err := util.HTTPPostContext(util.WithIdempotent(ocr.RequestContext(ctx)), uri, "")
 	if err != nil {
 		return
 	}
@@ -259,14 +290,19 @@ func (ocr *OCR) Common(path string) (ResCommon ResCommon, err error) {
 
 // PlateNumber 车牌OCR识别接口
 func (ocr *OCR) PlateNumber(path string) (ResPlateNumber ResPlateNumber, err error) {
-	accessToken, err := ocr.GetAccessToken()
+	return ocr.PlateNumberContext(context2.Background(), path)
+}
+
+// PlateNumberContext 车牌OCR识别接口
+func (ocr *OCR) PlateNumberContext(ctx context2.Context, path string) (ResPlateNumber ResPlateNumber, err error) {
+	accessToken, err := ocr.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 
 	uri := fmt.Sprintf("%s/cv/ocr/platenum?img_url=%s&access_token=%s", ocr.Server, url.QueryEscape(path), accessToken)
 
-	response, err := util.HTTPPost(uri, "")
+	response, err := util.HTTPPostContext(util.WithIdempotent(ocr.RequestContext(ctx)), uri, "")
 	if err != nil {
 		return
 	}
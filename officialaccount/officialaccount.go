@@ -1,7 +1,9 @@
 package officialaccount
 
 import (
+	context2 "context"
 	"net/http"
+	"sync"
 
 	"github.com/amazing-gao/wechat/v2/credential"
 	"github.com/amazing-gao/wechat/v2/officialaccount/basic"
@@ -20,11 +22,15 @@ import (
 	"github.com/amazing-gao/wechat/v2/officialaccount/ocr"
 	"github.com/amazing-gao/wechat/v2/officialaccount/server"
 	"github.com/amazing-gao/wechat/v2/officialaccount/user"
+	"github.com/amazing-gao/wechat/v2/util"
 )
 
 // OfficialAccount 微信公众号相关API
 type OfficialAccount struct {
 	ctx *context.Context
+
+	mu            sync.Mutex
+	stopRefresher context2.CancelFunc
 }
 
 // NewOfficialAccount 实例化公众号API
@@ -32,12 +38,19 @@ func NewOfficialAccount(cfg *config.Config) *OfficialAccount {
 	if cfg.Server == "" {
 		cfg.Server = "https://api.weixin.qq.com"
 	}
+	if cfg.OpenServer == "" {
+		cfg.OpenServer = "https://open.weixin.qq.com"
+	}
 
-	defaultAkHandle := credential.NewDefaultAccessToken(cfg.Server, cfg.AppID, cfg.AppSecret, credential.CacheKeyOfficialAccountPrefix, cfg.Cache)
-	ctx := &context.Context{
-		Config:            cfg,
-		AccessTokenHandle: defaultAkHandle,
+	ctx := &context.Context{Config: cfg, APIFailover: cfg.Failover}
+	if ctx.APIFailover == nil && cfg.EnableFailover {
+		ctx.APIFailover = util.NewAPIFailover(cfg.Server, cfg.BackupServers, cfg.HTTPClient)
 	}
+	defaultAkHandle := credential.NewDefaultAccessToken(cfg.Server, cfg.AppID, cfg.AppSecret, credential.CacheKeyOfficialAccountPrefix, cfg.Cache)
+	defaultAkHandle.SetHTTPClient(ctx.HTTPDoer())
+	defaultAkHandle.SetRefreshHook(cfg.RefreshHook)
+	defaultAkHandle.SetUseStableToken(cfg.UseStableToken)
+	ctx.AccessTokenHandle = defaultAkHandle
 	return &OfficialAccount{ctx: ctx}
 }
 
@@ -46,6 +59,37 @@ func (officialAccount *OfficialAccount) SetAccessTokenHandle(accessTokenHandle c
 	officialAccount.ctx.AccessTokenHandle = accessTokenHandle
 }
 
+// StartTokenRefresher 在后台于过期前主动刷新access_token及jsapi_ticket，ctx取消或调用 StopTokenRefresher 后停止
+// 重复调用时先停止之前启动的刷新；自定义的AccessTokenHandle未实现 credential.Renewer 时不生效
+func (officialAccount *OfficialAccount) StartTokenRefresher(ctx context2.Context) {
+	renewer, ok := officialAccount.ctx.AccessTokenHandle.(credential.Renewer)
+	if !ok {
+		return
+	}
+	ctx, cancel := context2.WithCancel(ctx)
+	officialAccount.mu.Lock()
+	if officialAccount.stopRefresher != nil {
+		officialAccount.stopRefresher()
+	}
+	officialAccount.stopRefresher = cancel
+	officialAccount.mu.Unlock()
+
+	credential.NewBackgroundRefresher(renewer).Start(ctx)
+	if js, ok := officialAccount.GetJs().JsTicketHandle.(*credential.DefaultJsTicket); ok {
+		credential.NewBackgroundRefresher(credential.JsTicketRenewer(js, officialAccount.ctx)).Start(ctx)
+	}
+}
+
+// StopTokenRefresher 停止 StartTokenRefresher 启动的后台刷新，未启动时不处理
+func (officialAccount *OfficialAccount) StopTokenRefresher() {
+	officialAccount.mu.Lock()
+	defer officialAccount.mu.Unlock()
+	if officialAccount.stopRefresher != nil {
+		officialAccount.stopRefresher()
+		officialAccount.stopRefresher = nil
+	}
+}
+
 // GetContext get Context
 func (officialAccount *OfficialAccount) GetContext() *context.Context {
 	return officialAccount.ctx
@@ -69,11 +113,21 @@ func (officialAccount *OfficialAccount) GetServer(req *http.Request, writer http
 	return srv
 }
 
+// NewHTTPHandler 创建可复用的消息处理 http.Handler，handler 可以为 server.Router 的 Handle 方法
+func (officialAccount *OfficialAccount) NewHTTPHandler(handler server.Handler) *server.HTTPHandler {
+	return server.NewHTTPHandler(officialAccount.ctx, handler)
+}
+
 // GetAccessToken 获取access_token
 func (officialAccount *OfficialAccount) GetAccessToken() (string, error) {
 	return officialAccount.ctx.GetAccessToken()
 }
 
+// GetAccessTokenContext 获取access_token
+func (officialAccount *OfficialAccount) GetAccessTokenContext(ctx context2.Context) (string, error) {
+	return officialAccount.ctx.GetAccessTokenContext(ctx)
+}
+
 // GetOauth oauth2网页授权
 func (officialAccount *OfficialAccount) GetOauth() *oauth.Oauth {
 	return oauth.NewOauth(officialAccount.ctx)
@@ -0,0 +1,68 @@
+package officialaccount
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amazing-gao/wechat/v2/cache"
+	"github.com/amazing-gao/wechat/v2/officialaccount/config"
+)
+
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestFailover(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		hosts []string
+	)
+	client := doerFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		hosts = append(hosts, req.URL.Host+req.URL.Path)
+		mu.Unlock()
+		if req.URL.Host == "api.weixin.qq.com" {
+			return nil, errors.New("connection refused")
+		}
+		body := `{"ip_list":["127.0.0.1"]}`
+		if req.URL.Path == "/cgi-bin/token" {
+			body = `{"access_token":"ACCESS_TOKEN","expires_in":7200}`
+		}
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+	})
+	cfg := &config.Config{AppID: "wx1", AppSecret: "secret", Cache: cache.NewMemory(), HTTPClient: client, EnableFailover: true}
+	officialAccount := NewOfficialAccount(cfg)
+	assert.Nil(t, cfg.Failover)
+
+	// 默认域名连续失败3次后，之后的调用切换到容灾域名
+	for i := 0; i < 3; i++ {
+		_, err := officialAccount.GetBasic().GetCallbackIP()
+		assert.NotNil(t, err)
+	}
+	ipList, err := officialAccount.GetBasic().GetCallbackIP()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"127.0.0.1"}, ipList)
+	assert.Equal(t, []string{
+		"api.weixin.qq.com/cgi-bin/token",
+		"api.weixin.qq.com/cgi-bin/token",
+		"api.weixin.qq.com/cgi-bin/token",
+		"api2.weixin.qq.com/cgi-bin/token",
+		"api2.weixin.qq.com/cgi-bin/getcallbackip",
+	}, hosts)
+
+	state := officialAccount.GetContext().APIFailover.State()
+	assert.False(t, state[0].Healthy)
+	assert.True(t, state[1].Healthy)
+
+	// 默认不启用容灾，自定义域名没有容灾域名时不切换
+	assert.Nil(t, NewOfficialAccount(&config.Config{Cache: cache.NewMemory()}).GetContext().APIFailover)
+	assert.Nil(t, NewOfficialAccount(&config.Config{Cache: cache.NewMemory(), Server: "http://127.0.0.1:8080", EnableFailover: true}).GetContext().APIFailover)
+}
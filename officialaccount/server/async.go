@@ -0,0 +1,203 @@
+package server
+
+import (
+	context2 "context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/amazing-gao/wechat/v2/officialaccount/context"
+	"github.com/amazing-gao/wechat/v2/officialaccount/message"
+)
+
+// ErrAsyncQueueFull 异步处理的等待队列已满，消息未被处理
+var ErrAsyncQueueFull = errors.New("async queue is full")
+
+// ErrAsyncClosed 异步处理已关闭
+var ErrAsyncClosed = errors.New("async is closed")
+
+// AsyncOpts 异步处理配置
+type AsyncOpts struct {
+	Workers   int // 同时处理的消息数，默认10
+	QueueSize int // 等待处理的消息数，默认100，队列已满时消息不被处理，Server.Serve 返回 ErrAsyncQueueFull
+	// PassiveTimeout 在该时间内处理完成时仍使用被动回复，否则先回复success，处理完成后通过客服消息发送回复
+	// 为0时总是立即回复success，微信要求5秒内回复，应小于4秒
+	PassiveTimeout time.Duration
+	// Timeout 处理方法的超时时间，通过ctx传递给处理方法，默认30秒
+	Timeout time.Duration
+	// OnError 消息未被处理或客服消息发送失败时调用，为空时记录日志
+	OnError func(ctx context2.Context, msg *message.MixMessage, err error)
+}
+
+// Async 在有限的协程中异步处理消息，立即回复微信服务器，通过 Server.SetAsync 使用
+// 处理方法在 AsyncOpts.PassiveTimeout 后返回的回复会转换为客服消息，通过 message.Manager 发送
+type Async struct {
+	opts AsyncOpts
+	jobs chan func()
+	wg   sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewAsync 创建异步处理并启动处理协程，同一 Async 可用于多个账号的 Server
+func NewAsync(opts *AsyncOpts) *Async {
+	async := &Async{}
+	if opts != nil {
+		async.opts = *opts
+	}
+	if async.opts.Workers <= 0 {
+		async.opts.Workers = 10
+	}
+	if async.opts.QueueSize <= 0 {
+		async.opts.QueueSize = 100
+	}
+	if async.opts.Timeout <= 0 {
+		async.opts.Timeout = 30 * time.Second
+	}
+	async.jobs = make(chan func(), async.opts.QueueSize)
+	for i := 0; i < async.opts.Workers; i++ {
+		async.wg.Add(1)
+		go func() {
+			defer async.wg.Done()
+			for job := range async.jobs {
+				job()
+			}
+		}()
+	}
+	return async
+}
+
+// Close 不再接收新消息，等待队列中的消息处理完成
+func (async *Async) Close() {
+	async.mu.Lock()
+	if !async.closed {
+		async.closed = true
+		close(async.jobs)
+	}
+	async.mu.Unlock()
+	async.wg.Wait()
+}
+
+// submit 将任务加入队列
+func (async *Async) submit(job func()) error {
+	async.mu.RLock()
+	defer async.mu.RUnlock()
+	if async.closed {
+		return ErrAsyncClosed
+	}
+	select {
+	case async.jobs <- job:
+		return nil
+	default:
+		return ErrAsyncQueueFull
+	}
+}
+
+// handle 异步处理消息，在 PassiveTimeout 内处理完成时返回回复，否则返回的deferred为true
+// 队列已满或已关闭时返回错误，此时消息未被处理，不能回复success
+func (async *Async) handle(ctx context2.Context, accountCtx *context.Context, handler func(context2.Context, *message.MixMessage) *message.Reply, msg *message.MixMessage) (reply *message.Reply, deferred bool, err error) {
+	// 处理方法在请求结束后继续执行，不随请求取消，但保留ctx中的值
+	jobCtx, cancel := context2.WithTimeout(detachedContext{ctx}, async.opts.Timeout)
+	result := &asyncResult{passive: make(chan *message.Reply, 1), expired: async.opts.PassiveTimeout <= 0}
+	err = async.submit(func() {
+		defer cancel()
+		defer func() {
+			if e := recover(); e != nil {
+				async.onError(jobCtx, accountCtx, msg, fmt.Errorf("panic error: %v\n%s", e, debug.Stack()))
+			}
+		}()
+		reply := handler(jobCtx, msg)
+		if result.deliver(reply) || reply == nil {
+			return
+		}
+		customerMsg, err := message.NewCustomerMessageFromReply(msg.GetOpenID(), reply)
+		if err == nil {
+			err = message.NewMessageManager(accountCtx).SendContext(jobCtx, customerMsg)
+		}
+		if err != nil {
+			async.onError(jobCtx, accountCtx, msg, fmt.Errorf("send customer message: %w", err))
+		}
+	})
+	if err != nil {
+		cancel()
+		async.onError(ctx, accountCtx, msg, err)
+		return nil, false, err
+	}
+
+	if result.expired {
+		return nil, true, nil
+	}
+	timer := time.NewTimer(async.opts.PassiveTimeout)
+	defer timer.Stop()
+	select {
+	case reply = <-result.passive:
+		return reply, false, nil
+	case <-timer.C:
+		reply, deferred = result.expire()
+		return reply, deferred, nil
+	}
+}
+
+// asyncResult 协调被动回复与客服消息，保证回复只通过其中一种方式发送
+type asyncResult struct {
+	mu      sync.Mutex
+	passive chan *message.Reply
+	expired bool
+}
+
+// deliver 被动回复未超时时将回复交给请求并返回true
+func (r *asyncResult) deliver(reply *message.Reply) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.expired {
+		return false
+	}
+	r.passive <- reply
+	return true
+}
+
+// expire 被动回复超时，处理恰好完成时仍返回其回复
+func (r *asyncResult) expire() (*message.Reply, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expired = true
+	select {
+	case reply := <-r.passive:
+		return reply, false
+	default:
+		return nil, true
+	}
+}
+
+func (async *Async) onError(ctx context2.Context, accountCtx *context.Context, msg *message.MixMessage, err error) {
+	if async.opts.OnError != nil {
+		async.opts.OnError(ctx, msg, err)
+		return
+	}
+	accountCtx.GetLogger().Error("async handle message failed", "appid", accountCtx.AppID, "msg_type", msg.MsgType,
+		"event", msg.Event, "openid", msg.GetOpenID(), "error", err)
+}
+
+// detachedContext 保留parent中的值，但不随parent取消
+type detachedContext struct {
+	parent context2.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (ctx detachedContext) Value(key interface{}) interface{} {
+	return ctx.parent.Value(key)
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	context2 "context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amazing-gao/wechat/v2/cache"
+	"github.com/amazing-gao/wechat/v2/officialaccount/config"
+	"github.com/amazing-gao/wechat/v2/officialaccount/context"
+	"github.com/amazing-gao/wechat/v2/officialaccount/message"
+)
+
+type staticAccessToken string
+
+func (token staticAccessToken) GetAccessToken() (string, error) {
+	return string(token), nil
+}
+
+func TestAsync(t *testing.T) {
+	sent := make(chan string, 1)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		sent <- r.URL.Path + " " + string(body)
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer api.Close()
+
+	ctx := &context.Context{
+		Config:            &config.Config{AppID: "wx1", Server: api.URL},
+		AccessTokenHandle: staticAccessToken("ACCESS_TOKEN"),
+	}
+	async := NewAsync(&AsyncOpts{Workers: 1, PassiveTimeout: 50 * time.Millisecond})
+	defer async.Close()
+
+	serve := func(content string) string {
+		body := `<xml><ToUserName>gh</ToUserName><FromUserName>o1</FromUserName><CreateTime>1</CreateTime><MsgType>text</MsgType><Content>` + content + `</Content></xml>`
+		rec := httptest.NewRecorder()
+		srv := NewServer(ctx)
+		srv.Request, srv.Writer = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)), rec
+		srv.SkipValidate(true)
+		srv.SetAsync(async)
+		router := NewRouter()
+		router.Fallback(func(ctx context2.Context, msg *message.MixMessage) *message.Reply {
+			if msg.Content == "slow" {
+				time.Sleep(100 * time.Millisecond)
+			}
+			return &message.Reply{MsgType: message.MsgTypeText, MsgData: message.NewText("re " + msg.Content)}
+		})
+		srv.SetRouter(router)
+		assert.Nil(t, srv.Serve())
+		assert.Nil(t, srv.Send())
+		return rec.Body.String()
+	}
+
+	// 在 PassiveTimeout 内完成时被动回复
+	assert.Contains(t, serve("fast"), "re fast")
+
+	// 超时后先回复success，处理完成后发送客服消息
+	assert.Equal(t, "success", serve("slow"))
+	select {
+	case got := <-sent:
+		assert.Equal(t, `/cgi-bin/message/custom/send {"touser":"o1","msgtype":"text","text":{"content":"re slow"}}`+"\n", got)
+	case <-time.After(time.Second):
+		t.Fatal("customer message not sent")
+	}
+}
+
+func TestAsyncQueueFull(t *testing.T) {
+	ctx := &context.Context{Config: &config.Config{AppID: "wx1", Cache: cache.NewMemory(), MsgDedupTTL: cache.DefaultMsgDedupTTL}}
+	async := NewAsync(&AsyncOpts{Workers: 1, QueueSize: 1, OnError: func(context2.Context, *message.MixMessage, error) {}})
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	defer async.Close()
+	defer close(release)
+
+	serve := func(msgID string) (*httptest.ResponseRecorder, error) {
+		body := `<xml><ToUserName>gh</ToUserName><FromUserName>o1</FromUserName><CreateTime>1</CreateTime><MsgType>text</MsgType><Content>hi</Content><MsgId>` + msgID + `</MsgId></xml>`
+		rec := httptest.NewRecorder()
+		srv := NewServer(ctx)
+		srv.Request, srv.Writer = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)), rec
+		srv.SkipValidate(true)
+		srv.SetAsync(async)
+		srv.SetMessageHandler(func(msg *message.MixMessage) *message.Reply {
+			started <- struct{}{}
+			<-release
+			return nil
+		})
+		err := srv.Serve()
+		if err == nil {
+			err = srv.Send()
+		}
+		return rec, err
+	}
+
+	// 第一条消息占用处理协程，第二条进入队列
+	_, err := serve("1")
+	assert.Nil(t, err)
+	<-started
+	_, err = serve("2")
+	assert.Nil(t, err)
+
+	// 队列已满时消息未被处理，不回复success，也不记录为已处理，微信重试时重新处理
+	rec, err := serve("3")
+	assert.ErrorIs(t, err, ErrAsyncQueueFull)
+	assert.Empty(t, rec.Body.String())
+	_, first := ctx.GetMsgDedup().Begin(cache.MsgDedupKey("wx1", 3, "o1", 1, ""))
+	assert.True(t, first)
+}
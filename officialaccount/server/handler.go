@@ -0,0 +1,81 @@
+package server
+
+import (
+	context2 "context"
+	"errors"
+	"net/http"
+
+	"github.com/amazing-gao/wechat/v2/officialaccount/context"
+)
+
+type serverKey struct{}
+
+// ServerFromContext 获取处理当前请求的 Server，仅在 HTTPHandler 调用的处理方法中有效
+// 可用于读取 RequestRawXMLMsg、GetOpenID 等请求相关的信息
+func ServerFromContext(ctx context2.Context) (*Server, bool) {
+	srv, ok := ctx.Value(serverKey{}).(*Server)
+	return srv, ok
+}
+
+// HTTPHandler 可复用的消息处理 http.Handler，创建一次后可并发处理所有回调请求
+// 每个请求的状态（RequestMsg、random、nonce、timestamp等）保存在仅用于该请求的 Server 中
+// SkipValidate、SetAsync 及 SetErrorHandler 需在开始处理请求前调用
+type HTTPHandler struct {
+	ctx          *context.Context
+	handler      Handler
+	skipValidate bool
+	async        *Async
+	errorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// NewHTTPHandler 创建消息处理 http.Handler，handler 可以为 Router.Handle
+func NewHTTPHandler(ctx *context.Context, handler Handler) *HTTPHandler {
+	return &HTTPHandler{ctx: ctx, handler: handler}
+}
+
+// SkipValidate 是否跳过签名校验
+func (h *HTTPHandler) SkipValidate(skip bool) {
+	h.skipValidate = skip
+}
+
+// SetAsync 使用异步模式处理消息，参见 Server.SetAsync
+func (h *HTTPHandler) SetAsync(async *Async) {
+	h.async = async
+}
+
+// SetErrorHandler 设置处理失败时的回调，未设置时签名校验失败返回403，异步处理队列已满或已关闭返回503，其他错误返回400
+func (h *HTTPHandler) SetErrorHandler(errorHandler func(w http.ResponseWriter, r *http.Request, err error)) {
+	h.errorHandler = errorHandler
+}
+
+// ServeHTTP 校验签名、解析消息，调用处理方法并回复
+func (h *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	srv := NewServer(h.ctx)
+	srv.Writer = w
+	srv.Request = r.WithContext(context2.WithValue(r.Context(), serverKey{}, srv))
+	srv.skipValidate = h.skipValidate
+	srv.async = h.async
+	srv.messageHandler = h.handler
+
+	err := srv.Serve()
+	if err == nil {
+		err = srv.Send()
+	}
+	if err == nil {
+		return
+	}
+	if h.errorHandler != nil {
+		h.errorHandler(w, r, err)
+		return
+	}
+	if errors.Is(err, ErrInvalidSignature) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, ErrAsyncQueueFull) || errors.Is(err, ErrAsyncClosed) {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	srv.GetLogger().Error("serve message failed", "appid", srv.AppID, "error", err)
+	http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+}
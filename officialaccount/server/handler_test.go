@@ -0,0 +1,55 @@
+package server
+
+import (
+	context2 "context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amazing-gao/wechat/v2/officialaccount/config"
+	"github.com/amazing-gao/wechat/v2/officialaccount/context"
+	"github.com/amazing-gao/wechat/v2/officialaccount/message"
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	ctx := &context.Context{Config: &config.Config{AppID: "wx1", Token: "token"}}
+	router := NewRouter()
+	router.OnText(TextPrefix(""), func(ctx context2.Context, msg *TextMessage) *message.Reply {
+		srv, ok := ServerFromContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, msg.GetOpenID(), srv.GetOpenID())
+		return &message.Reply{MsgType: message.MsgTypeText, MsgData: message.NewText("re " + msg.Content)}
+	})
+	handler := NewHTTPHandler(ctx, router.Handle)
+
+	query := "?timestamp=1&nonce=2&signature=" + util.Signature("token", "1", "2")
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			openID := fmt.Sprintf("o%d", i)
+			body := fmt.Sprintf(`<xml><ToUserName>gh</ToUserName><FromUserName>%s</FromUserName><CreateTime>1</CreateTime><MsgType>text</MsgType><Content>%d</Content></xml>`, openID, i)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/wechat"+query+"&openid="+openID, strings.NewReader(body)))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf("<Content><![CDATA[re %d]]></Content>", i))
+			assert.Contains(t, rec.Body.String(), fmt.Sprintf("<ToUserName><![CDATA[%s]]></ToUserName>", openID))
+		}(i)
+	}
+	wg.Wait()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/wechat"+query+"&echostr=hello", nil))
+	assert.Equal(t, "hello", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/wechat?timestamp=1&nonce=2&signature=bad&echostr=hello", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
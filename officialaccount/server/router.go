@@ -0,0 +1,294 @@
+package server
+
+import (
+	context2 "context"
+	"fmt"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/amazing-gao/wechat/v2/cache"
+	"github.com/amazing-gao/wechat/v2/officialaccount/message"
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+// sceneKeyPrefix 扫描带参数二维码关注时EventKey的前缀
+const sceneKeyPrefix = "qrscene_"
+
+// Handler 消息处理方法，返回nil时不回复
+type Handler func(ctx context2.Context, msg *message.MixMessage) *message.Reply
+
+// Middleware 消息处理中间件
+type Middleware func(next Handler) Handler
+
+// TextMessage 文本消息
+type TextMessage struct {
+	message.CommonToken
+	MsgID   int64
+	Content string
+	Raw     *message.MixMessage // 原始消息
+}
+
+// EventMessage 事件推送
+type EventMessage struct {
+	message.CommonToken
+	Event    message.EventType
+	EventKey string
+	Raw      *message.MixMessage // 原始消息
+}
+
+// ClickEvent 点击菜单拉取消息的事件推送
+type ClickEvent struct {
+	message.CommonToken
+	EventKey string
+	Raw      *message.MixMessage // 原始消息
+}
+
+// ScanEvent 扫描带参数二维码的事件推送，包括已关注用户扫码及未关注用户扫码后关注
+type ScanEvent struct {
+	message.CommonToken
+	Subscribe bool   // 是否为扫码后关注
+	Scene     string // 二维码的场景值，已去掉关注事件EventKey中的 qrscene_ 前缀
+	Ticket    string
+	Raw       *message.MixMessage // 原始消息
+}
+
+// TemplateSendJobFinishEvent 模板消息发送结果的事件推送
+type TemplateSendJobFinishEvent struct {
+	message.CommonToken
+	MsgID  int64
+	Status string              // success、failed:user block、failed: system failed
+	Raw    *message.MixMessage // 原始消息
+}
+
+// TextMatcher 文本消息的匹配规则
+type TextMatcher func(content string) bool
+
+// TextExact 内容与text完全相同
+func TextExact(text string) TextMatcher {
+	return func(content string) bool {
+		return content == text
+	}
+}
+
+// TextPrefix 内容以prefix开头
+func TextPrefix(prefix string) TextMatcher {
+	return func(content string) bool {
+		return strings.HasPrefix(content, prefix)
+	}
+}
+
+// TextRegexp 内容匹配正则表达式expr，expr不合法时panic
+func TextRegexp(expr string) TextMatcher {
+	re := regexp.MustCompile(expr)
+	return re.MatchString
+}
+
+type textRoute struct {
+	matcher TextMatcher
+	handler func(context2.Context, *TextMessage) *message.Reply
+}
+
+type scanRoute struct {
+	prefix  string
+	handler func(context2.Context, *ScanEvent) *message.Reply
+}
+
+// Router 消息路由，按消息类型、事件及内容分发到对应的处理方法，通过 Server.SetRouter 使用
+// 文本及扫码规则按注册顺序匹配，未匹配到任何规则的消息交给 Fallback 处理
+type Router struct {
+	middlewares     []Middleware
+	textRoutes      []textRoute
+	scanRoutes      []scanRoute
+	clickHandlers   map[string]func(context2.Context, *ClickEvent) *message.Reply
+	eventHandlers   map[message.EventType]func(context2.Context, *EventMessage) *message.Reply
+	templateHandler func(context2.Context, *TemplateSendJobFinishEvent) *message.Reply
+	fallback        Handler
+}
+
+// NewRouter 创建消息路由
+func NewRouter() *Router {
+	return &Router{
+		clickHandlers: map[string]func(context2.Context, *ClickEvent) *message.Reply{},
+		eventHandlers: map[message.EventType]func(context2.Context, *EventMessage) *message.Reply{},
+	}
+}
+
+// Use 添加中间件，先添加的中间件在外层
+func (router *Router) Use(middlewares ...Middleware) {
+	router.middlewares = append(router.middlewares, middlewares...)
+}
+
+// OnText 处理匹配matcher的文本消息
+func (router *Router) OnText(matcher TextMatcher, handler func(ctx context2.Context, msg *TextMessage) *message.Reply) {
+	router.textRoutes = append(router.textRoutes, textRoute{matcher: matcher, handler: handler})
+}
+
+// OnEvent 处理指定类型的事件推送，OnClick、OnScan 及 OnTemplateSendJobFinish 匹配的事件不再交给该方法处理
+func (router *Router) OnEvent(event message.EventType, handler func(ctx context2.Context, msg *EventMessage) *message.Reply) {
+	router.eventHandlers[event] = handler
+}
+
+// OnClick 处理EventKey为key的点击菜单事件
+func (router *Router) OnClick(key string, handler func(ctx context2.Context, msg *ClickEvent) *message.Reply) {
+	router.clickHandlers[key] = handler
+}
+
+// OnScan 处理场景值以scenePrefix开头的扫码事件，包括扫码后关注，scenePrefix为空时匹配所有场景值
+func (router *Router) OnScan(scenePrefix string, handler func(ctx context2.Context, msg *ScanEvent) *message.Reply) {
+	router.scanRoutes = append(router.scanRoutes, scanRoute{prefix: scenePrefix, handler: handler})
+}
+
+// OnTemplateSendJobFinish 处理模板消息发送结果的事件推送
+func (router *Router) OnTemplateSendJobFinish(handler func(ctx context2.Context, msg *TemplateSendJobFinishEvent) *message.Reply) {
+	router.templateHandler = handler
+}
+
+// Fallback 处理未匹配到任何规则的消息
+func (router *Router) Fallback(handler Handler) {
+	router.fallback = handler
+}
+
+// Handle 经过中间件处理消息
+func (router *Router) Handle(ctx context2.Context, msg *message.MixMessage) *message.Reply {
+	handler := Handler(router.dispatch)
+	for i := len(router.middlewares) - 1; i >= 0; i-- {
+		handler = router.middlewares[i](handler)
+	}
+	return handler(ctx, msg)
+}
+
+// dispatch 将消息分发到匹配的处理方法
+func (router *Router) dispatch(ctx context2.Context, msg *message.MixMessage) *message.Reply {
+	switch msg.MsgType {
+	case message.MsgTypeText:
+		for _, route := range router.textRoutes {
+			if route.matcher(msg.Content) {
+				return route.handler(ctx, &TextMessage{CommonToken: msg.CommonToken, MsgID: msg.MsgID, Content: msg.Content, Raw: msg})
+			}
+		}
+	case message.MsgTypeEvent:
+		if reply, ok := router.dispatchEvent(ctx, msg); ok {
+			return reply
+		}
+	}
+	if router.fallback != nil {
+		return router.fallback(ctx, msg)
+	}
+	return nil
+}
+
+// dispatchEvent 分发事件推送，未匹配到处理方法时ok为false
+func (router *Router) dispatchEvent(ctx context2.Context, msg *message.MixMessage) (reply *message.Reply, ok bool) {
+	switch msg.Event {
+	case message.EventClick:
+		if handler, ok := router.clickHandlers[msg.EventKey]; ok {
+			return handler(ctx, &ClickEvent{CommonToken: msg.CommonToken, EventKey: msg.EventKey, Raw: msg}), true
+		}
+	case message.EventScan, message.EventSubscribe:
+		subscribe := msg.Event == message.EventSubscribe
+		// 直接关注时EventKey为空，不属于扫码事件
+		if subscribe && !strings.HasPrefix(msg.EventKey, sceneKeyPrefix) {
+			break
+		}
+		scene := strings.TrimPrefix(msg.EventKey, sceneKeyPrefix)
+		for _, route := range router.scanRoutes {
+			if strings.HasPrefix(scene, route.prefix) {
+				return route.handler(ctx, &ScanEvent{CommonToken: msg.CommonToken, Subscribe: subscribe, Scene: scene, Ticket: msg.Ticket, Raw: msg}), true
+			}
+		}
+	case message.EventTemplateSendJobFinish:
+		if router.templateHandler != nil {
+			return router.templateHandler(ctx, &TemplateSendJobFinishEvent{CommonToken: msg.CommonToken, MsgID: msg.TemplateMsgID, Status: msg.Status, Raw: msg}), true
+		}
+	}
+	if handler, ok := router.eventHandlers[msg.Event]; ok {
+		return handler(ctx, &EventMessage{CommonToken: msg.CommonToken, Event: msg.Event, EventKey: msg.EventKey, Raw: msg}), true
+	}
+	return nil, false
+}
+
+// Recover 捕获处理方法中的panic并记录日志，此时不回复消息，logger为nil时使用 util.DefaultLogger
+func Recover(logger util.Logger) Middleware {
+	logger = util.RedactLogger(logger)
+	return func(next Handler) Handler {
+		return func(ctx context2.Context, msg *message.MixMessage) (reply *message.Reply) {
+			defer func() {
+				if e := recover(); e != nil {
+					logger.Error("handle message panic", "msg_type", msg.MsgType, "event", msg.Event,
+						"error", fmt.Sprint(e), "stack", string(debug.Stack()))
+					reply = nil
+				}
+			}()
+			return next(ctx, msg)
+		}
+	}
+}
+
+// Logging 以info级别记录每条消息的类型、事件、发送者及处理耗时，logger为nil时使用 util.DefaultLogger
+func Logging(logger util.Logger) Middleware {
+	logger = util.RedactLogger(logger)
+	return func(next Handler) Handler {
+		return func(ctx context2.Context, msg *message.MixMessage) *message.Reply {
+			start := time.Now()
+			reply := next(ctx, msg)
+			var replyType message.MsgType
+			if reply != nil {
+				replyType = reply.MsgType
+			}
+			logger.Info("handle message", "msg_type", msg.MsgType, "event", msg.Event, "event_key", msg.EventKey,
+				"openid", msg.GetOpenID(), "reply", replyType, "latency", time.Since(start))
+			return reply
+		}
+	}
+}
+
+// UserLock 对同一用户的消息串行处理，避免用户连续发送的消息被并发处理
+// locker 为nil时使用进程内的锁，多实例部署时可使用 cache.Redis；ttl为锁的租期，小于等于0时为5秒
+// 获取锁时会一直等待到ctx结束，此时不处理消息；locker返回错误时不加锁继续处理
+func UserLock(locker cache.Locker, ttl time.Duration) Middleware {
+	if locker == nil {
+		locker = cache.NewMemoryLocker()
+	}
+	if ttl <= 0 {
+		ttl = 5 * time.Second
+	}
+	return func(next Handler) Handler {
+		return func(ctx context2.Context, msg *message.MixMessage) *message.Reply {
+			key := "gowechat_user_lock_" + string(msg.ToUserName) + "_" + msg.GetOpenID()
+			unlock, err := waitLock(ctx, locker, key, ttl)
+			// 获取到锁后ctx恰好结束时也需要释放锁，否则该用户之后的消息需等待整个租期
+			if err == nil {
+				defer func() { _ = unlock() }()
+			}
+			if ctx.Err() != nil {
+				return nil
+			}
+			return next(ctx, msg)
+		}
+	}
+}
+
+// lockRetryInterval 获取锁失败后重试的间隔
+var lockRetryInterval = 20 * time.Millisecond
+
+// waitLock 等待获取锁
+func waitLock(ctx context2.Context, locker cache.Locker, key string, ttl time.Duration) (func() error, error) {
+	for {
+		unlock, ok, err := locker.TryLock(ctx, key, ttl)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return unlock, nil
+		}
+		timer := time.NewTimer(lockRetryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	context2 "context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amazing-gao/wechat/v2/officialaccount/message"
+)
+
+func textReply(content string) *message.Reply {
+	return &message.Reply{MsgType: message.MsgTypeText, MsgData: message.NewText(content)}
+}
+
+func replyContent(reply *message.Reply) string {
+	if reply == nil {
+		return ""
+	}
+	return string(reply.MsgData.(*message.Text).Content)
+}
+
+func TestRouter(t *testing.T) {
+	router := NewRouter()
+	router.OnText(TextExact("help"), func(ctx context2.Context, msg *TextMessage) *message.Reply {
+		return textReply("exact")
+	})
+	router.OnText(TextPrefix("help"), func(ctx context2.Context, msg *TextMessage) *message.Reply {
+		return textReply("prefix " + msg.Content)
+	})
+	router.OnText(TextRegexp(`^\d{6}$`), func(ctx context2.Context, msg *TextMessage) *message.Reply {
+		return textReply("code")
+	})
+	router.OnEvent(message.EventSubscribe, func(ctx context2.Context, msg *EventMessage) *message.Reply {
+		return textReply("subscribe")
+	})
+	router.OnClick("V1001", func(ctx context2.Context, msg *ClickEvent) *message.Reply {
+		return textReply("click " + msg.EventKey)
+	})
+	router.OnScan("order_", func(ctx context2.Context, msg *ScanEvent) *message.Reply {
+		if msg.Subscribe {
+			return textReply("subscribe scan " + msg.Scene)
+		}
+		return textReply("scan " + msg.Scene)
+	})
+	router.OnTemplateSendJobFinish(func(ctx context2.Context, msg *TemplateSendJobFinishEvent) *message.Reply {
+		return textReply("template " + msg.Status)
+	})
+	router.Fallback(func(ctx context2.Context, msg *message.MixMessage) *message.Reply {
+		return textReply("fallback")
+	})
+
+	var order []string
+	router.Use(func(next Handler) Handler {
+		return func(ctx context2.Context, msg *message.MixMessage) *message.Reply {
+			order = append(order, "outer")
+			return next(ctx, msg)
+		}
+	}, func(next Handler) Handler {
+		return func(ctx context2.Context, msg *message.MixMessage) *message.Reply {
+			order = append(order, "inner")
+			return next(ctx, msg)
+		}
+	})
+
+	text := func(content string) *message.MixMessage {
+		msg := &message.MixMessage{Content: content}
+		msg.MsgType = message.MsgTypeText
+		return msg
+	}
+	event := func(event message.EventType, key string) *message.MixMessage {
+		msg := &message.MixMessage{Event: event, EventKey: key, Status: "success"}
+		msg.MsgType = message.MsgTypeEvent
+		return msg
+	}
+
+	ctx := context2.Background()
+	cases := []struct {
+		msg  *message.MixMessage
+		want string
+	}{
+		{text("help"), "exact"},
+		{text("help me"), "prefix help me"},
+		{text("123456"), "code"},
+		{text("hello"), "fallback"},
+		{event(message.EventSubscribe, ""), "subscribe"},
+		{event(message.EventSubscribe, "qrscene_order_1"), "subscribe scan order_1"},
+		{event(message.EventSubscribe, "qrscene_other"), "subscribe"},
+		{event(message.EventScan, "order_2"), "scan order_2"},
+		{event(message.EventScan, "other"), "fallback"},
+		{event(message.EventClick, "V1001"), "click V1001"},
+		{event(message.EventClick, "V1002"), "fallback"},
+		{event(message.EventTemplateSendJobFinish, ""), "template success"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, replyContent(router.Handle(ctx, c.msg)), c.msg)
+	}
+	assert.Equal(t, []string{"outer", "inner"}, order[:2])
+}
+
+func TestRouterMiddlewares(t *testing.T) {
+	router := NewRouter()
+	router.Use(Recover(nil), UserLock(nil, time.Second))
+
+	var running, maxRunning int32
+	router.Fallback(func(ctx context2.Context, msg *message.MixMessage) *message.Reply {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		if n > atomic.LoadInt32(&maxRunning) {
+			atomic.StoreInt32(&maxRunning, n)
+		}
+		time.Sleep(10 * time.Millisecond)
+		if msg.Content == "panic" {
+			panic("boom")
+		}
+		return textReply("ok")
+	})
+
+	msg := func(content string) *message.MixMessage {
+		m := &message.MixMessage{Content: content}
+		m.MsgType = message.MsgTypeText
+		m.FromUserName = "openid"
+		return m
+	}
+	assert.Nil(t, router.Handle(context2.Background(), msg("panic")))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, "ok", replyContent(router.Handle(context2.Background(), msg("hi"))))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&maxRunning))
+}
+
+// cancelLocker 获取锁成功的同时取消ctx
+type cancelLocker struct {
+	cancel   context2.CancelFunc
+	unlocked bool
+}
+
+func (l *cancelLocker) TryLock(ctx context2.Context, key string, ttl time.Duration) (func() error, bool, error) {
+	l.cancel()
+	return func() error {
+		l.unlocked = true
+		return nil
+	}, true, nil
+}
+
+func TestUserLockCanceled(t *testing.T) {
+	ctx, cancel := context2.WithCancel(context2.Background())
+	locker := &cancelLocker{cancel: cancel}
+	handled := false
+	handler := UserLock(locker, time.Second)(func(ctx context2.Context, msg *message.MixMessage) *message.Reply {
+		handled = true
+		return nil
+	})
+
+	// 获取到锁后ctx结束时不处理消息，但释放锁
+	msg := &message.MixMessage{}
+	msg.FromUserName = "openid"
+	assert.Nil(t, handler(ctx, msg))
+	assert.False(t, handled)
+	assert.True(t, locker.unlocked)
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	context2 "context"
 	"encoding/xml"
 	"errors"
 	"fmt"
@@ -10,13 +11,15 @@ import (
 	"runtime/debug"
 	"strconv"
 
+	"github.com/amazing-gao/wechat/v2/cache"
 	"github.com/amazing-gao/wechat/v2/officialaccount/context"
 	"github.com/amazing-gao/wechat/v2/officialaccount/message"
-	log "github.com/sirupsen/logrus"
-
 	"github.com/amazing-gao/wechat/v2/util"
 )
 
+// ErrInvalidSignature 请求签名校验失败
+var ErrInvalidSignature = errors.New("请求校验失败")
+
 // Server struct
 type Server struct {
 	*context.Context
@@ -27,7 +30,7 @@ type Server struct {
 
 	openID string
 
-	messageHandler func(*message.MixMessage) *message.Reply
+	messageHandler func(context2.Context, *message.MixMessage) *message.Reply
 
 	RequestRawXMLMsg  []byte
 	RequestMsg        *message.MixMessage
@@ -38,6 +41,13 @@ type Server struct {
 	random     []byte
 	nonce      string
 	timestamp  int64
+
+	dedup     *cache.MsgDedup
+	dedupKey  string
+	duplicate bool // 是否为微信重试投递的消息
+
+	async    *Async
+	deferred bool // 是否已转为异步处理，此时先回复success
 }
 
 // NewServer init
@@ -53,10 +63,24 @@ func (srv *Server) SkipValidate(skip bool) {
 }
 
 // Serve 处理微信的请求消息
-func (srv *Server) Serve() error {
+func (srv *Server) Serve() (err error) {
+	callback := &util.Callback{AppID: srv.AppID}
+	ctx := util.StartCallback(srv.Request.Context(), srv.CallbackHooks, callback)
+	defer func() {
+		if srv.RequestMsg != nil {
+			callback.MsgType = string(srv.RequestMsg.MsgType)
+			callback.Event = string(srv.RequestMsg.Event)
+		}
+		util.FinishCallback(ctx, srv.CallbackHooks, callback, err)
+	}()
+	return srv.serve(ctx)
+}
+
+// serve 校验签名、解析消息并构建回复
+func (srv *Server) serve(ctx context2.Context) error {
 	if !srv.Validate() {
-		log.Error("Validate Signature Failed.")
-		return fmt.Errorf("请求校验失败")
+		srv.GetLogger().Error("validate signature failed", "appid", srv.AppID)
+		return ErrInvalidSignature
 	}
 
 	echostr, exists := srv.GetQuery("echostr")
@@ -65,15 +89,34 @@ func (srv *Server) Serve() error {
 		return nil
 	}
 
-	response, err := srv.handleRequest()
+	// 处理方法panic或构建回复失败时释放认领，微信重试时重新处理该消息
+	finished := false
+	defer func() {
+		if srv.dedup != nil && !finished {
+			srv.dedup.Abort(srv.dedupKey)
+		}
+	}()
+
+	response, err := srv.handleRequest(ctx)
 	if err != nil {
 		return err
 	}
 
 	// debug print request msg
-	log.Debugf("request msg =%s", string(srv.RequestRawXMLMsg))
+	srv.GetLogger().Debug("request msg", "appid", srv.AppID, "body", srv.RequestRawXMLMsg)
 
-	return srv.buildResponse(response)
+	if srv.duplicate {
+		srv.GetLogger().Info("duplicate msg", "appid", srv.AppID, "key", srv.dedupKey)
+		return nil
+	}
+	if err = srv.buildResponse(response); err != nil {
+		return err
+	}
+	if srv.dedup != nil {
+		srv.dedup.Finish(srv.dedupKey, srv.ResponseRawXMLMsg)
+	}
+	finished = true
+	return nil
 }
 
 // Validate 校验请求是否合法
@@ -84,12 +127,12 @@ func (srv *Server) Validate() bool {
 	timestamp := srv.Query("timestamp")
 	nonce := srv.Query("nonce")
 	signature := srv.Query("signature")
-	log.Debugf("validate signature, timestamp=%s, nonce=%s", timestamp, nonce)
+	srv.GetLogger().Debug("validate signature", "timestamp", timestamp, "nonce", nonce)
 	return signature == util.Signature(srv.Token, timestamp, nonce)
 }
 
 // HandleRequest 处理微信的请求
-func (srv *Server) handleRequest() (reply *message.Reply, err error) {
+func (srv *Server) handleRequest(ctx context2.Context) (reply *message.Reply, err error) {
 	// set isSafeMode
 	srv.isSafeMode = false
 	encryptType := srv.Query("encrypt_type")
@@ -110,7 +153,22 @@ func (srv *Server) handleRequest() (reply *message.Reply, err error) {
 		err = errors.New("消息类型转换失败")
 	}
 	srv.RequestMsg = mixMessage
-	reply = srv.messageHandler(mixMessage)
+
+	// 微信在5秒内未收到回复时会重试，重复投递的消息不再处理，回复首次处理的结果
+	if srv.dedup = srv.GetMsgDedup(); srv.dedup != nil && mixMessage != nil {
+		srv.dedupKey = cache.MsgDedupKey(srv.AppID, mixMessage.MsgID, string(mixMessage.FromUserName), mixMessage.CreateTime, string(mixMessage.Event))
+		var first bool
+		if srv.ResponseRawXMLMsg, first = srv.dedup.Begin(srv.dedupKey); !first {
+			srv.duplicate = true
+			srv.dedup = nil
+			return
+		}
+	}
+	if srv.async != nil {
+		reply, srv.deferred, err = srv.async.handle(ctx, srv.Context, srv.messageHandler, mixMessage)
+		return
+	}
+	reply = srv.messageHandler(ctx, mixMessage)
 	return
 }
 
@@ -168,7 +226,19 @@ func (srv *Server) parseRequestMessage(rawXMLMsgBytes []byte) (msg *message.MixM
 
 // SetMessageHandler 设置用户自定义的回调方法
 func (srv *Server) SetMessageHandler(handler func(*message.MixMessage) *message.Reply) {
-	srv.messageHandler = handler
+	srv.messageHandler = func(ctx context2.Context, msg *message.MixMessage) *message.Reply {
+		return handler(msg)
+	}
+}
+
+// SetAsync 使用异步模式处理消息，处理方法在 AsyncOpts.PassiveTimeout 内未完成时先回复success，之后通过客服消息发送回复
+func (srv *Server) SetAsync(async *Async) {
+	srv.async = async
+}
+
+// SetRouter 使用消息路由处理消息，与 SetMessageHandler 只需设置其一
+func (srv *Server) SetRouter(router *Router) {
+	srv.messageHandler = router.Handle
 }
 
 func (srv *Server) buildResponse(reply *message.Reply) (err error) {
@@ -224,7 +294,23 @@ func (srv *Server) buildResponse(reply *message.Reply) (err error) {
 // Send 将自定义的消息发送
 func (srv *Server) Send() (err error) {
 	replyMsg := srv.ResponseMsg
-	log.Debugf("response msg =%+v", replyMsg)
+	srv.GetLogger().Debug("response msg", "appid", srv.AppID, "body", srv.ResponseRawXMLMsg)
+	if srv.deferred {
+		srv.String("success")
+		return
+	}
+	if srv.duplicate {
+		// 首次处理尚未完成或没有回复
+		if len(srv.ResponseRawXMLMsg) == 0 {
+			srv.String("success")
+			return
+		}
+		if !srv.isSafeMode {
+			writeContextType(srv.Writer, xmlContentType)
+			srv.Render(srv.ResponseRawXMLMsg)
+			return
+		}
+	}
 	if srv.isSafeMode {
 		// 安全模式下对消息进行加密
 		var encryptedMsg []byte
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amazing-gao/wechat/v2/cache"
+	"github.com/amazing-gao/wechat/v2/officialaccount/config"
+	"github.com/amazing-gao/wechat/v2/officialaccount/context"
+	"github.com/amazing-gao/wechat/v2/officialaccount/message"
+)
+
+func TestServeDedup(t *testing.T) {
+	ctx := &context.Context{Config: &config.Config{AppID: "wx1", Cache: cache.NewMemory(), MsgDedupTTL: cache.DefaultMsgDedupTTL}}
+
+	calls := 0
+	serve := func(body string) string {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		srv := NewServer(ctx)
+		srv.Request, srv.Writer = req, rec
+		srv.SkipValidate(true)
+		srv.SetMessageHandler(func(msg *message.MixMessage) *message.Reply {
+			calls++
+			return &message.Reply{MsgType: message.MsgTypeText, MsgData: message.NewText("coupon")}
+		})
+		assert.Nil(t, srv.Serve())
+		assert.Nil(t, srv.Send())
+		return rec.Body.String()
+	}
+
+	text := `<xml><ToUserName>gh</ToUserName><FromUserName>o1</FromUserName><CreateTime>1</CreateTime><MsgType>text</MsgType><Content>hi</Content><MsgId>100</MsgId></xml>`
+	first := serve(text)
+	assert.Contains(t, first, "coupon")
+	assert.Equal(t, first, serve(text))
+	assert.Equal(t, 1, calls)
+
+	// 事件推送没有MsgId，使用 FromUserName+CreateTime+Event 去重
+	event := `<xml><ToUserName>gh</ToUserName><FromUserName>o1</FromUserName><CreateTime>2</CreateTime><MsgType>event</MsgType><Event>subscribe</Event></xml>`
+	serve(event)
+	serve(event)
+	assert.Equal(t, 2, calls)
+
+	// 首次处理尚未完成时回复success
+	dedup := ctx.GetMsgDedup()
+	key := cache.MsgDedupKey("wx1", 101, "o1", 3, "")
+	_, isFirst := dedup.Begin(key)
+	assert.True(t, isFirst)
+	assert.Equal(t, "success", serve(strings.Replace(text, "100", "101", 1)))
+	assert.Equal(t, 2, calls)
+
+	// 未启用去重时每次都处理
+	ctx.MsgDedupTTL = 0
+	serve(text)
+	assert.Equal(t, 3, calls)
+}
+
+func TestServeDedupPanic(t *testing.T) {
+	ctx := &context.Context{Config: &config.Config{AppID: "wx1", Cache: cache.NewMemory(), MsgDedupTTL: cache.DefaultMsgDedupTTL}}
+
+	calls := 0
+	serve := func(body string) string {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		srv := NewServer(ctx)
+		srv.Request, srv.Writer = req, rec
+		srv.SkipValidate(true)
+		srv.SetMessageHandler(func(msg *message.MixMessage) *message.Reply {
+			calls++
+			if calls == 1 {
+				panic("handler failed")
+			}
+			return &message.Reply{MsgType: message.MsgTypeText, MsgData: message.NewText("coupon")}
+		})
+		assert.Nil(t, srv.Serve())
+		assert.Nil(t, srv.Send())
+		return rec.Body.String()
+	}
+
+	// 首次处理panic后释放认领，微信重试时重新处理
+	text := `<xml><ToUserName>gh</ToUserName><FromUserName>o1</FromUserName><CreateTime>1</CreateTime><MsgType>text</MsgType><Content>hi</Content><MsgId>100</MsgId></xml>`
+	assert.Panics(t, func() { serve(text) })
+	assert.Contains(t, serve(text), "coupon")
+	assert.Equal(t, 2, calls)
+}
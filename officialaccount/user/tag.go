@@ -1,6 +1,7 @@
 package user
 
 import (
+	context2 "context"
 	"encoding/json"
 	"fmt"
 
@@ -25,8 +26,13 @@ type TagOpenIDList struct {
 
 // CreateTag 创建标签
 func (user *User) CreateTag(tagName string) (tagInfo *TagInfo, err error) {
+	return user.CreateTagContext(context2.Background(), tagName)
+}
+
+// CreateTagContext 创建标签
+func (user *User) CreateTagContext(ctx context2.Context, tagName string) (tagInfo *TagInfo, err error) {
 	var accessToken string
-	accessToken, err = user.GetAccessToken()
+	accessToken, err = user.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -38,7 +44,7 @@ func (user *User) CreateTag(tagName string) (tagInfo *TagInfo, err error) {
 		} `json:"tag"`
 	}
 	request.Tag.Name = tagName
-	response, err = util.PostJSON(uri, &request)
+	response, err = util.PostJSONContext(user.RequestContext(ctx), uri, &request)
 	if err != nil {
 		return
 	}
@@ -51,7 +57,7 @@ func (user *User) CreateTag(tagName string) (tagInfo *TagInfo, err error) {
 		return
 	}
 	if result.ErrCode != 0 {
-		err = fmt.Errorf("CreateTag Error , errcode=%d , errmsg=%s", result.ErrCode, result.ErrMsg)
+		err = util.NewError("CreateTag", result.ErrCode, result.ErrMsg)
 		return
 	}
 	return result.Tag, nil
@@ -59,7 +65,12 @@ func (user *User) CreateTag(tagName string) (tagInfo *TagInfo, err error) {
 
 // DeleteTag  删除标签
 func (user *User) DeleteTag(tagID int32) (err error) {
-	accessToken, err := user.GetAccessToken()
+	return user.DeleteTagContext(context2.Background(), tagID)
+}
+
+// DeleteTagContext  删除标签
+func (user *User) DeleteTagContext(ctx context2.Context, tagID int32) (err error) {
+	accessToken, err := user.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -70,7 +81,7 @@ func (user *User) DeleteTag(tagID int32) (err error) {
 		} `json:"tag"`
 	}
 	request.Tag.ID = tagID
-	resp, err := util.PostJSON(url, &request)
+	resp, err := util.PostJSONContext(user.RequestContext(ctx), url, &request)
 	if err != nil {
 		return
 	}
@@ -79,7 +90,12 @@ func (user *User) DeleteTag(tagID int32) (err error) {
 
 // UpdateTag  编辑标签
 func (user *User) UpdateTag(tagID int32, tagName string) (err error) {
-	accessToken, err := user.GetAccessToken()
+	return user.UpdateTagContext(context2.Background(), tagID, tagName)
+}
+
+// UpdateTagContext  编辑标签
+func (user *User) UpdateTagContext(ctx context2.Context, tagID int32, tagName string) (err error) {
+	accessToken, err := user.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -92,7 +108,7 @@ func (user *User) UpdateTag(tagID int32, tagName string) (err error) {
 	}
 	request.Tag.ID = tagID
 	request.Tag.Name = tagName
-	resp, err := util.PostJSON(url, &request)
+	resp, err := util.PostJSONContext(user.RequestContext(ctx), url, &request)
 	if err != nil {
 		return
 	}
@@ -101,12 +117,17 @@ func (user *User) UpdateTag(tagID int32, tagName string) (err error) {
 
 // GetTag 获取公众号已创建的标签
 func (user *User) GetTag() (tags []*TagInfo, err error) {
-	accessToken, err := user.GetAccessToken()
+	return user.GetTagContext(context2.Background())
+}
+
+// GetTagContext 获取公众号已创建的标签
+func (user *User) GetTagContext(ctx context2.Context) (tags []*TagInfo, err error) {
+	accessToken, err := user.GetAccessTokenContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 	url := fmt.Sprintf("%s/cgi-bin/tags/get?access_token=%s", user.Server, accessToken)
-	response, err := util.HTTPGet(url)
+	response, err := util.HTTPGetContext(user.RequestContext(ctx), url)
 	if err != nil {
 		return
 	}
@@ -123,7 +144,12 @@ func (user *User) GetTag() (tags []*TagInfo, err error) {
 
 // OpenIDListByTag 获取标签下粉丝列表
 func (user *User) OpenIDListByTag(tagID int32, nextOpenID ...string) (userList *TagOpenIDList, err error) {
-	accessToken, err := user.GetAccessToken()
+	return user.OpenIDListByTagContext(context2.Background(), tagID, nextOpenID...)
+}
+
+// OpenIDListByTagContext 获取标签下粉丝列表
+func (user *User) OpenIDListByTagContext(ctx context2.Context, tagID int32, nextOpenID ...string) (userList *TagOpenIDList, err error) {
+	accessToken, err := user.GetAccessTokenContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -137,7 +163,7 @@ func (user *User) OpenIDListByTag(tagID int32, nextOpenID ...string) (userList *
 	if len(nextOpenID) > 0 {
 		request.OpenID = nextOpenID[0]
 	}
-	response, err := util.PostJSON(url, &request)
+	response, err := util.PostJSONContext(util.WithIdempotent(user.RequestContext(ctx)), url, &request)
 	if err != nil {
 		return nil, err
 	}
@@ -151,7 +177,12 @@ func (user *User) OpenIDListByTag(tagID int32, nextOpenID ...string) (userList *
 
 // BatchTag 批量为用户打标签
 func (user *User) BatchTag(openIDList []string, tagID int32) (err error) {
-	accessToken, err := user.GetAccessToken()
+	return user.BatchTagContext(context2.Background(), openIDList, tagID)
+}
+
+// BatchTagContext 批量为用户打标签
+func (user *User) BatchTagContext(ctx context2.Context, openIDList []string, tagID int32) (err error) {
+	accessToken, err := user.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -166,7 +197,7 @@ func (user *User) BatchTag(openIDList []string, tagID int32) (err error) {
 		TagID:      tagID,
 	}
 	url := fmt.Sprintf("%s/cgi-bin/tags/members/batchtagging?access_token=%s", user.Server, accessToken)
-	resp, err := util.PostJSON(url, &request)
+	resp, err := util.PostJSONContext(user.RequestContext(ctx), url, &request)
 	if err != nil {
 		return
 	}
@@ -175,10 +206,15 @@ func (user *User) BatchTag(openIDList []string, tagID int32) (err error) {
 
 // BatchUntag 批量为用户取消标签
 func (user *User) BatchUntag(openIDList []string, tagID int32) (err error) {
+	return user.BatchUntagContext(context2.Background(), openIDList, tagID)
+}
+
+// BatchUntagContext 批量为用户取消标签
+func (user *User) BatchUntagContext(ctx context2.Context, openIDList []string, tagID int32) (err error) {
 	if len(openIDList) == 0 {
 		return
 	}
-	accessToken, err := user.GetAccessToken()
+	accessToken, err := user.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -190,7 +226,7 @@ func (user *User) BatchUntag(openIDList []string, tagID int32) (err error) {
 		OpenIDList: openIDList,
 		TagID:      tagID,
 	}
-	resp, err := util.PostJSON(url, &request)
+	resp, err := util.PostJSONContext(user.RequestContext(ctx), url, &request)
 	if err != nil {
 		return
 	}
@@ -199,7 +235,12 @@ func (user *User) BatchUntag(openIDList []string, tagID int32) (err error) {
 
 // UserTidList 获取用户身上的标签列表
 func (user *User) UserTidList(openID string) (tagIDList []int32, err error) {
-	accessToken, err := user.GetAccessToken()
+	return user.UserTidListContext(context2.Background(), openID)
+}
+
+// UserTidListContext 获取用户身上的标签列表
+func (user *User) UserTidListContext(ctx context2.Context, openID string) (tagIDList []int32, err error) {
+	accessToken, err := user.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
@@ -210,7 +251,7 @@ func (user *User) UserTidList(openID string) (tagIDList []int32, err error) {
 	}{
 		OpenID: openID,
 	}
-	resp, err := util.PostJSON(url, &request)
+	resp, err := util.PostJSONContext(util.WithIdempotent(user.RequestContext(ctx)), url, &request)
 	if err != nil {
 		return
 	}
@@ -223,7 +264,7 @@ func (user *User) UserTidList(openID string) (tagIDList []int32, err error) {
 		return
 	}
 	if result.ErrCode != 0 {
-		err = fmt.Errorf("UserTidList Error , errcode=%d , errmsg=%s", result.ErrCode, result.ErrMsg)
+		err = util.NewError("UserTidList", result.ErrCode, result.ErrMsg)
 		return
 	}
 	return result.TagIDList, nil
@@ -1,6 +1,7 @@
 package user
 
 import (
+	context2 "context"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -58,15 +59,20 @@ type OpenidList struct {
 
 // GetUserInfo 获取用户基本信息
 func (user *User) GetUserInfo(openID string) (userInfo *Info, err error) {
+	return user.GetUserInfoContext(context2.Background(), openID)
+}
+
+// GetUserInfoContext 获取用户基本信息
+func (user *User) GetUserInfoContext(ctx context2.Context, openID string) (userInfo *Info, err error) {
 	var accessToken string
-	accessToken, err = user.GetAccessToken()
+	accessToken, err = user.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 
 	uri := fmt.Sprintf("%s/cgi-bin/user/info?access_token=%s&openid=%s&lang=zh_CN", user.Server, accessToken, openID)
 	var response []byte
-	response, err = util.HTTPGet(uri)
+	response, err = util.HTTPGetContext(user.RequestContext(ctx), uri)
 	if err != nil {
 		return
 	}
@@ -76,7 +82,7 @@ func (user *User) GetUserInfo(openID string) (userInfo *Info, err error) {
 		return
 	}
 	if userInfo.ErrCode != 0 {
-		err = fmt.Errorf("GetUserInfo Error , errcode=%d , errmsg=%s", userInfo.ErrCode, userInfo.ErrMsg)
+		err = util.NewError("GetUserInfo", userInfo.ErrCode, userInfo.ErrMsg)
 		return
 	}
 	return
@@ -84,15 +90,20 @@ func (user *User) GetUserInfo(openID string) (userInfo *Info, err error) {
 
 // UpdateRemark 设置用户备注名
 func (user *User) UpdateRemark(openID, remark string) (err error) {
+	return user.UpdateRemarkContext(context2.Background(), openID, remark)
+}
+
+// UpdateRemarkContext 设置用户备注名
+func (user *User) UpdateRemarkContext(ctx context2.Context, openID, remark string) (err error) {
 	var accessToken string
-	accessToken, err = user.GetAccessToken()
+	accessToken, err = user.GetAccessTokenContext(ctx)
 	if err != nil {
 		return
 	}
 
 	uri := fmt.Sprintf("%s/cgi-bin/user/info/updateremark?access_token=%s", user.Server, accessToken)
 	var response []byte
-	response, err = util.PostJSON(uri, map[string]string{"openid": openID, "remark": remark})
+	response, err = util.PostJSONContext(user.RequestContext(ctx), uri, map[string]string{"openid": openID, "remark": remark})
 	if err != nil {
 		return
 	}
@@ -102,7 +113,12 @@ func (user *User) UpdateRemark(openID, remark string) (err error) {
 
 // ListUserOpenIDs 返回用户列表
 func (user *User) ListUserOpenIDs(nextOpenid ...string) (*OpenidList, error) {
-	accessToken, err := user.GetAccessToken()
+	return user.ListUserOpenIDsContext(context2.Background(), nextOpenid...)
+}
+
+// ListUserOpenIDsContext 返回用户列表
+func (user *User) ListUserOpenIDsContext(ctx context2.Context, nextOpenid ...string) (*OpenidList, error) {
+	accessToken, err := user.GetAccessTokenContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -115,7 +131,7 @@ func (user *User) ListUserOpenIDs(nextOpenid ...string) (*OpenidList, error) {
 	}
 	uri.RawQuery = q.Encode()
 
-	response, err := util.HTTPGet(uri.String())
+	response, err := util.HTTPGetContext(user.RequestContext(ctx), uri.String())
 	if err != nil {
 		return nil, err
 	}
@@ -132,11 +148,16 @@ func (user *User) ListUserOpenIDs(nextOpenid ...string) (*OpenidList, error) {
 
 // ListAllUserOpenIDs 返回所有用户OpenID列表
 func (user *User) ListAllUserOpenIDs() ([]string, error) {
+	return user.ListAllUserOpenIDsContext(context2.Background())
+}
+
+// ListAllUserOpenIDsContext 返回所有用户OpenID列表
+func (user *User) ListAllUserOpenIDsContext(ctx context2.Context) ([]string, error) {
 	nextOpenid := ""
 	openids := make([]string, 0)
 	count := 0
 	for {
-		ul, err := user.ListUserOpenIDs(nextOpenid)
+		ul, err := user.ListUserOpenIDsContext(ctx, nextOpenid)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,38 @@
+package openplatform
+
+import (
+	context2 "context"
+
+	"github.com/amazing-gao/wechat/v2/credential"
+	"github.com/amazing-gao/wechat/v2/openplatform/context"
+)
+
+// DefaultAuthrAccessToken 授权方的access_token获取方式
+// 通过第三方平台使用authorizer_refresh_token代为刷新
+type DefaultAuthrAccessToken struct {
+	opCtx *context.Context
+	appID string
+}
+
+// NewDefaultAuthrAccessToken new DefaultAuthrAccessToken
+func NewDefaultAuthrAccessToken(opCtx *context.Context, appID string) credential.AccessTokenHandle {
+	return &DefaultAuthrAccessToken{
+		opCtx: opCtx,
+		appID: appID,
+	}
+}
+
+// GetAccessToken 获取授权方的access_token
+func (ak *DefaultAuthrAccessToken) GetAccessToken() (string, error) {
+	return ak.opCtx.GetAuthrAccessToken(ak.appID)
+}
+
+// GetAccessTokenContext 获取授权方的access_token
+func (ak *DefaultAuthrAccessToken) GetAccessTokenContext(ctx context2.Context) (string, error) {
+	return ak.opCtx.GetAuthrAccessTokenContext(ctx, ak.appID)
+}
+
+// RefreshAccessTokenContext 授权方的access_token失效时，使用refresh_token重新获取
+func (ak *DefaultAuthrAccessToken) RefreshAccessTokenContext(ctx context2.Context, staleToken string) (string, error) {
+	return ak.opCtx.RefreshAuthrAccessTokenContext(ctx, ak.appID, staleToken)
+}
@@ -0,0 +1,34 @@
+// Package config 开放平台config配置
+package config
+
+import (
+	"context"
+
+	"github.com/amazing-gao/wechat/v2/cache"
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+// Config .config for 微信开放平台（第三方平台）
+type Config struct {
+	Server         string `json:"server"`           // server
+	MPServer       string `json:"mp_server"`        // 授权页域名，默认 https://mp.weixin.qq.com
+	AppID          string `json:"app_id"`           // 第三方平台 component_appid
+	AppSecret      string `json:"app_secret"`       // 第三方平台 component_appsecret
+	Token          string `json:"token"`            // 消息校验Token
+	EncodingAESKey string `json:"encoding_aes_key"` // 消息加解密Key
+	Cache          cache.Cache
+	HTTPClient     util.Doer          `json:"-"` // 自定义HTTP客户端，为空时使用 util.DefaultHTTPClient
+	Interceptors   []util.Interceptor `json:"-"` // 接口调用拦截器，在通过 Wechat.Use 注册的拦截器之后执行
+	Logger         util.Logger        `json:"-"` // 日志，为空时使用 util.DefaultLogger，敏感字段默认脱敏
+	// RefreshTokenStore 持久化保存授权方的authorizer_refresh_token，为空时保存在Cache中
+	// refresh_token丢失后只能由授权方重新授权，生产环境应使用数据库等持久化存储
+	RefreshTokenStore RefreshTokenStore `json:"-"`
+}
+
+// RefreshTokenStore 授权方authorizer_refresh_token的持久化存储
+type RefreshTokenStore interface {
+	// GetRefreshToken 获取授权方的refresh_token，不存在时返回空字符串
+	GetRefreshToken(ctx context.Context, componentAppID, authorizerAppID string) (string, error)
+	// SetRefreshToken 保存授权方的refresh_token
+	SetRefreshToken(ctx context.Context, componentAppID, authorizerAppID, refreshToken string) error
+}
@@ -0,0 +1,456 @@
+package context
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/amazing-gao/wechat/v2/cache"
+	"github.com/amazing-gao/wechat/v2/credential"
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+const (
+	componentLoginURL = "%s/cgi-bin/componentloginpage?component_appid=%s&pre_auth_code=%s&redirect_uri=%s&auth_type=%d&biz_appid=%s"
+	bindComponentURL  = "%s/safe/bindcomponent?action=bindcomponent&no_scan=1&auth_type=%d&component_appid=%s&pre_auth_code=%s&redirect_uri=%s&biz_appid=%s#wechat_redirect"
+
+	// verifyTicketExpires component_verify_ticket 的有效期为12小时
+	verifyTicketExpires = 12 * time.Hour
+	// authrRefreshTokenExpires 未设置 RefreshTokenStore 时authorizer_refresh_token 在cache中的保存时间
+	// 微信侧refresh_token不会过期（除非取消授权），每次刷新access_token时续期
+	authrRefreshTokenExpires = 30 * 24 * time.Hour
+)
+
+// ErrAuthrRefreshTokenNotFound 授权方的authorizer_refresh_token不存在，需要授权方重新授权或调用 SetAuthrRefreshToken 恢复
+var ErrAuthrRefreshTokenNotFound = errors.New("authorizer_refresh_token not found")
+
+// ResComponentAccessToken 获取component_access_token的返回结果
+type ResComponentAccessToken struct {
+	util.CommonError
+
+	ComponentAccessToken string `json:"component_access_token"`
+	ExpiresIn            int64  `json:"expires_in"`
+}
+
+// ResPreAuthCode 获取预授权码的返回结果
+type ResPreAuthCode struct {
+	util.CommonError
+
+	PreAuthCode string `json:"pre_auth_code"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// ID 权限集ID
+type ID struct {
+	ID int `json:"id"`
+}
+
+// AuthFuncInfo 授权的权限集
+type AuthFuncInfo struct {
+	FuncscopeCategory ID `json:"funcscope_category"`
+}
+
+// AuthBaseInfo 授权的基础信息
+type AuthBaseInfo struct {
+	AuthrAccessToken
+	FuncInfo []AuthFuncInfo `json:"func_info"`
+}
+
+// AuthrAccessToken 授权方的access_token
+type AuthrAccessToken struct {
+	Appid        string `json:"authorizer_appid"`
+	AccessToken  string `json:"authorizer_access_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"authorizer_refresh_token"`
+}
+
+// resQueryAuth 使用授权码获取授权信息的返回结果
+type resQueryAuth struct {
+	util.CommonError
+
+	Info AuthBaseInfo `json:"authorization_info"`
+}
+
+// resAuthrAccessToken 刷新授权方access_token的返回结果
+type resAuthrAccessToken struct {
+	util.CommonError
+
+	AuthrAccessToken
+}
+
+// AuthorizerInfo 授权方详细信息
+type AuthorizerInfo struct {
+	NickName        string `json:"nick_name"`
+	HeadImg         string `json:"head_img"`
+	ServiceTypeInfo ID     `json:"service_type_info"`
+	VerifyTypeInfo  ID     `json:"verify_type_info"`
+	UserName        string `json:"user_name"`
+	PrincipalName   string `json:"principal_name"`
+	Alias           string `json:"alias"`
+	QrcodeURL       string `json:"qrcode_url"`
+	Signature       string `json:"signature"`
+	BusinessInfo    struct {
+		OpenStore int `json:"open_store"`
+		OpenScan  int `json:"open_scan"`
+		OpenPay   int `json:"open_pay"`
+		OpenCard  int `json:"open_card"`
+		OpenShake int `json:"open_shake"`
+	} `json:"business_info"`
+	MiniProgramInfo *struct {
+		Network struct {
+			RequestDomain   []string `json:"RequestDomain"`
+			WsRequestDomain []string `json:"WsRequestDomain"`
+			UploadDomain    []string `json:"UploadDomain"`
+			DownloadDomain  []string `json:"DownloadDomain"`
+		} `json:"network"`
+		VisitStatus int `json:"visit_status"`
+	} `json:"MiniProgramInfo,omitempty"`
+}
+
+// resAuthorizerInfo 获取授权方信息的返回结果
+type resAuthorizerInfo struct {
+	util.CommonError
+
+	AuthorizerInfo    AuthorizerInfo `json:"authorizer_info"`
+	AuthorizationInfo AuthBaseInfo   `json:"authorization_info"`
+}
+
+func (ctx *Context) cacheKey(name string, ids ...string) string {
+	key := fmt.Sprintf("%s_%s_%s", credential.CacheKeyOpenPlatformPrefix, name, ctx.AppID)
+	for _, id := range ids {
+		key += "_" + id
+	}
+	return key
+}
+
+// cachedString 从cache中读取字符串，key不存在或值不是字符串时均视为未命中
+func (ctx *Context) cachedString(parent context.Context, key string) (string, bool) {
+	val, err := cache.ToContextCache(ctx.Cache).GetStringContext(parent, key)
+	return val, err == nil && val != ""
+}
+
+// SetComponentVerifyTicket 保存微信推送的component_verify_ticket
+func (ctx *Context) SetComponentVerifyTicket(ticket string) error {
+	return ctx.Cache.Set(ctx.cacheKey("component_verify_ticket"), ticket, verifyTicketExpires)
+}
+
+// GetComponentVerifyTicket 获取保存的component_verify_ticket
+func (ctx *Context) GetComponentVerifyTicket() (string, error) {
+	val, ok := ctx.cachedString(context.Background(), ctx.cacheKey("component_verify_ticket"))
+	if !ok {
+		return "", fmt.Errorf("component_verify_ticket not found, appid=%s", ctx.AppID)
+	}
+	return val, nil
+}
+
+// GetComponentAccessToken 获取component_access_token,先从cache中获取，没有则使用component_verify_ticket从服务端获取
+func (ctx *Context) GetComponentAccessToken() (string, error) {
+	return ctx.GetComponentAccessTokenContext(context.Background())
+}
+
+// GetComponentAccessTokenContext 获取component_access_token,先从cache中获取，没有则使用component_verify_ticket从服务端获取
+func (ctx *Context) GetComponentAccessTokenContext(parent context.Context) (string, error) {
+	accessTokenCacheKey := ctx.cacheKey("component_access_token")
+	if val, ok := ctx.cachedString(parent, accessTokenCacheKey); ok {
+		return val, nil
+	}
+
+	ctx.componentAccessTokenLock.Lock()
+	defer ctx.componentAccessTokenLock.Unlock()
+
+	// 双检，防止重复从微信服务器获取
+	if val, ok := ctx.cachedString(parent, accessTokenCacheKey); ok {
+		return val, nil
+	}
+
+	verifyTicket, err := ctx.GetComponentVerifyTicket()
+	if err != nil {
+		return "", err
+	}
+	res, err := ctx.SetComponentAccessTokenContext(parent, verifyTicket)
+	if err != nil {
+		return "", err
+	}
+	return res.ComponentAccessToken, nil
+}
+
+// SetComponentAccessToken 通过component_verify_ticket从服务端获取component_access_token并缓存
+func (ctx *Context) SetComponentAccessToken(verifyTicket string) (*ResComponentAccessToken, error) {
+	return ctx.SetComponentAccessTokenContext(context.Background(), verifyTicket)
+}
+
+// SetComponentAccessTokenContext 通过component_verify_ticket从服务端获取component_access_token并缓存
+func (ctx *Context) SetComponentAccessTokenContext(parent context.Context, verifyTicket string) (*ResComponentAccessToken, error) {
+	body := map[string]string{
+		"component_appid":         ctx.AppID,
+		"component_appsecret":     ctx.AppSecret,
+		"component_verify_ticket": verifyTicket,
+	}
+	uri := fmt.Sprintf("%s/cgi-bin/component/api_component_token", ctx.Server)
+	response, err := util.PostJSONContext(ctx.RequestContext(parent), uri, body)
+	if err != nil {
+		return nil, err
+	}
+	res := &ResComponentAccessToken{}
+	if err = util.DecodeWithError(response, res, "GetComponentAccessToken"); err != nil {
+		return nil, err
+	}
+
+	expires := res.ExpiresIn - 1500
+	if err = ctx.Cache.Set(ctx.cacheKey("component_access_token"), res.ComponentAccessToken, time.Duration(expires)*time.Second); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetPreCode 获取预授权码
+func (ctx *Context) GetPreCode() (string, error) {
+	return ctx.GetPreCodeContext(context.Background())
+}
+
+// GetPreCodeContext 获取预授权码
+func (ctx *Context) GetPreCodeContext(parent context.Context) (string, error) {
+	cat, err := ctx.GetComponentAccessTokenContext(parent)
+	if err != nil {
+		return "", err
+	}
+	uri := fmt.Sprintf("%s/cgi-bin/component/api_create_preauthcode?component_access_token=%s", ctx.Server, cat)
+	response, err := util.PostJSONContext(ctx.RequestContext(parent), uri, map[string]string{
+		"component_appid": ctx.AppID,
+	})
+	if err != nil {
+		return "", err
+	}
+	res := &ResPreAuthCode{}
+	if err = util.DecodeWithError(response, res, "GetPreCode"); err != nil {
+		return "", err
+	}
+	return res.PreAuthCode, nil
+}
+
+// GetComponentLoginPage 获取PC端授权页面地址
+// authType 1:仅展示公众号 2:仅展示小程序 3:公众号和小程序都展示
+func (ctx *Context) GetComponentLoginPage(redirectURI string, authType int, bizAppID string) (string, error) {
+	return ctx.GetComponentLoginPageContext(context.Background(), redirectURI, authType, bizAppID)
+}
+
+// GetComponentLoginPageContext 获取PC端授权页面地址
+// authType 1:仅展示公众号 2:仅展示小程序 3:公众号和小程序都展示
+func (ctx *Context) GetComponentLoginPageContext(parent context.Context, redirectURI string, authType int, bizAppID string) (string, error) {
+	code, err := ctx.GetPreCodeContext(parent)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(componentLoginURL, ctx.MPServer, ctx.AppID, code, url.QueryEscape(redirectURI), authType, bizAppID), nil
+}
+
+// GetBindComponentURL 获取移动端授权页面地址
+func (ctx *Context) GetBindComponentURL(redirectURI string, authType int, bizAppID string) (string, error) {
+	return ctx.GetBindComponentURLContext(context.Background(), redirectURI, authType, bizAppID)
+}
+
+// GetBindComponentURLContext 获取移动端授权页面地址
+func (ctx *Context) GetBindComponentURLContext(parent context.Context, redirectURI string, authType int, bizAppID string) (string, error) {
+	code, err := ctx.GetPreCodeContext(parent)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(bindComponentURL, ctx.MPServer, authType, ctx.AppID, code, url.QueryEscape(redirectURI), bizAppID), nil
+}
+
+// QueryAuthCode 使用授权码获取授权信息，并缓存授权方的access_token及refresh_token
+func (ctx *Context) QueryAuthCode(authCode string) (*AuthBaseInfo, error) {
+	return ctx.QueryAuthCodeContext(context.Background(), authCode)
+}
+
+// QueryAuthCodeContext 使用授权码获取授权信息，并缓存授权方的access_token及refresh_token
+func (ctx *Context) QueryAuthCodeContext(parent context.Context, authCode string) (*AuthBaseInfo, error) {
+	cat, err := ctx.GetComponentAccessTokenContext(parent)
+	if err != nil {
+		return nil, err
+	}
+	uri := fmt.Sprintf("%s/cgi-bin/component/api_query_auth?component_access_token=%s", ctx.Server, cat)
+	response, err := util.PostJSONContext(ctx.RequestContext(parent), uri, map[string]string{
+		"component_appid":    ctx.AppID,
+		"authorization_code": authCode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := &resQueryAuth{}
+	if err = util.DecodeWithError(response, res, "QueryAuthCode"); err != nil {
+		return nil, err
+	}
+	if err = ctx.cacheAuthrAccessToken(parent, &res.Info.AuthrAccessToken); err != nil {
+		return nil, err
+	}
+	return &res.Info, nil
+}
+
+// RefreshAuthrToken 使用refresh_token刷新授权方的access_token
+func (ctx *Context) RefreshAuthrToken(appID, refreshToken string) (*AuthrAccessToken, error) {
+	return ctx.RefreshAuthrTokenContext(context.Background(), appID, refreshToken)
+}
+
+// RefreshAuthrTokenContext 使用refresh_token刷新授权方的access_token
+func (ctx *Context) RefreshAuthrTokenContext(parent context.Context, appID, refreshToken string) (*AuthrAccessToken, error) {
+	cat, err := ctx.GetComponentAccessTokenContext(parent)
+	if err != nil {
+		return nil, err
+	}
+	uri := fmt.Sprintf("%s/cgi-bin/component/api_authorizer_token?component_access_token=%s", ctx.Server, cat)
+	response, err := util.PostJSONContext(ctx.RequestContext(parent), uri, map[string]string{
+		"component_appid":          ctx.AppID,
+		"authorizer_appid":         appID,
+		"authorizer_refresh_token": refreshToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := &resAuthrAccessToken{}
+	if err = util.DecodeWithError(response, res, "RefreshAuthrToken"); err != nil {
+		return nil, err
+	}
+	res.Appid = appID
+	if res.RefreshToken == "" {
+		res.RefreshToken = refreshToken
+	}
+	if err = ctx.cacheAuthrAccessToken(parent, &res.AuthrAccessToken); err != nil {
+		return nil, err
+	}
+	return &res.AuthrAccessToken, nil
+}
+
+// SetAuthrRefreshToken 保存授权方的refresh_token，用于后续自动刷新access_token
+func (ctx *Context) SetAuthrRefreshToken(appID, refreshToken string) error {
+	return ctx.SetAuthrRefreshTokenContext(context.Background(), appID, refreshToken)
+}
+
+// SetAuthrRefreshTokenContext 保存授权方的refresh_token，设置了 RefreshTokenStore 时保存到其中，否则保存在cache中
+func (ctx *Context) SetAuthrRefreshTokenContext(parent context.Context, appID, refreshToken string) error {
+	if ctx.RefreshTokenStore != nil {
+		return ctx.RefreshTokenStore.SetRefreshToken(parent, ctx.AppID, appID, refreshToken)
+	}
+	return ctx.Cache.Set(ctx.cacheKey("authorizer_refresh_token", appID), refreshToken, authrRefreshTokenExpires)
+}
+
+// GetAuthrRefreshToken 获取保存的授权方refresh_token，不存在时返回 ErrAuthrRefreshTokenNotFound
+func (ctx *Context) GetAuthrRefreshToken(appID string) (string, error) {
+	return ctx.GetAuthrRefreshTokenContext(context.Background(), appID)
+}
+
+// GetAuthrRefreshTokenContext 获取保存的授权方refresh_token，不存在时返回 ErrAuthrRefreshTokenNotFound
+func (ctx *Context) GetAuthrRefreshTokenContext(parent context.Context, appID string) (string, error) {
+	var val string
+	if ctx.RefreshTokenStore != nil {
+		var err error
+		if val, err = ctx.RefreshTokenStore.GetRefreshToken(parent, ctx.AppID, appID); err != nil {
+			return "", err
+		}
+	} else {
+		val, _ = ctx.cachedString(parent, ctx.cacheKey("authorizer_refresh_token", appID))
+	}
+	if val == "" {
+		return "", fmt.Errorf("%w, appid=%s", ErrAuthrRefreshTokenNotFound, appID)
+	}
+	return val, nil
+}
+
+// GetAuthrAccessToken 获取授权方的access_token,先从cache中获取，没有则使用refresh_token刷新
+func (ctx *Context) GetAuthrAccessToken(appID string) (string, error) {
+	return ctx.GetAuthrAccessTokenContext(context.Background(), appID)
+}
+
+// GetAuthrAccessTokenContext 获取授权方的access_token,先从cache中获取，没有则使用refresh_token刷新
+func (ctx *Context) GetAuthrAccessTokenContext(parent context.Context, appID string) (string, error) {
+	accessTokenCacheKey := ctx.cacheKey("authorizer_access_token", appID)
+	if val, ok := ctx.cachedString(parent, accessTokenCacheKey); ok {
+		return val, nil
+	}
+
+	ctx.authrAccessTokenLock.Lock()
+	defer ctx.authrAccessTokenLock.Unlock()
+
+	// 双检，防止重复从微信服务器获取
+	if val, ok := ctx.cachedString(parent, accessTokenCacheKey); ok {
+		return val, nil
+	}
+
+	refreshToken, err := ctx.GetAuthrRefreshTokenContext(parent, appID)
+	if err != nil {
+		return "", err
+	}
+	res, err := ctx.RefreshAuthrTokenContext(parent, appID, refreshToken)
+	if err != nil {
+		return "", err
+	}
+	return res.AccessToken, nil
+}
+
+// RefreshAuthrAccessTokenContext 作废失效的授权方access_token，并使用refresh_token重新获取
+// cache中的access_token已不是staleToken时，说明已被其他请求刷新，直接返回
+func (ctx *Context) RefreshAuthrAccessTokenContext(parent context.Context, appID, staleToken string) (string, error) {
+	accessTokenCacheKey := ctx.cacheKey("authorizer_access_token", appID)
+
+	ctx.authrAccessTokenLock.Lock()
+	defer ctx.authrAccessTokenLock.Unlock()
+
+	if val, ok := ctx.cachedString(parent, accessTokenCacheKey); ok && val != staleToken {
+		return val, nil
+	}
+	if err := ctx.Cache.Delete(accessTokenCacheKey); err != nil {
+		return "", err
+	}
+	refreshToken, err := ctx.GetAuthrRefreshTokenContext(parent, appID)
+	if err != nil {
+		return "", err
+	}
+	res, err := ctx.RefreshAuthrTokenContext(parent, appID, refreshToken)
+	if err != nil {
+		return "", err
+	}
+	return res.AccessToken, nil
+}
+
+// GetAuthrInfo 获取授权方的帐号基本信息
+func (ctx *Context) GetAuthrInfo(appID string) (*AuthorizerInfo, *AuthBaseInfo, error) {
+	return ctx.GetAuthrInfoContext(context.Background(), appID)
+}
+
+// GetAuthrInfoContext 获取授权方的帐号基本信息
+func (ctx *Context) GetAuthrInfoContext(parent context.Context, appID string) (*AuthorizerInfo, *AuthBaseInfo, error) {
+	cat, err := ctx.GetComponentAccessTokenContext(parent)
+	if err != nil {
+		return nil, nil, err
+	}
+	uri := fmt.Sprintf("%s/cgi-bin/component/api_get_authorizer_info?component_access_token=%s", ctx.Server, cat)
+	response, err := util.PostJSONContext(ctx.RequestContext(parent), uri, map[string]string{
+		"component_appid":  ctx.AppID,
+		"authorizer_appid": appID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	res := &resAuthorizerInfo{}
+	if err = util.DecodeWithError(response, res, "GetAuthrInfo"); err != nil {
+		return nil, nil, err
+	}
+	return &res.AuthorizerInfo, &res.AuthorizationInfo, nil
+}
+
+// cacheAuthrAccessToken 缓存授权方的access_token及refresh_token
+func (ctx *Context) cacheAuthrAccessToken(parent context.Context, token *AuthrAccessToken) error {
+	if token.Appid == "" {
+		return nil
+	}
+	expires := token.ExpiresIn - 1500
+	if err := ctx.Cache.Set(ctx.cacheKey("authorizer_access_token", token.Appid), token.AccessToken, time.Duration(expires)*time.Second); err != nil {
+		return err
+	}
+	if token.RefreshToken == "" {
+		return nil
+	}
+	return ctx.SetAuthrRefreshTokenContext(parent, token.Appid, token.RefreshToken)
+}
@@ -0,0 +1,74 @@
+package context
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amazing-gao/wechat/v2/cache"
+	"github.com/amazing-gao/wechat/v2/openplatform/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAuthrAccessToken(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]string{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case "/cgi-bin/component/api_component_token":
+			assert.Equal(t, "ticket", body["component_verify_ticket"])
+			_, _ = w.Write([]byte(`{"component_access_token":"cat","expires_in":7200}`))
+		case "/cgi-bin/component/api_authorizer_token":
+			assert.Equal(t, "cat", r.URL.Query().Get("component_access_token"))
+			assert.Equal(t, "refresh", body["authorizer_refresh_token"])
+			_, _ = w.Write([]byte(`{"authorizer_access_token":"aat","expires_in":7200,"authorizer_refresh_token":"refresh2"}`))
+		default:
+			_, _ = w.Write([]byte(`{"errcode":40001,"errmsg":"invalid"}`))
+		}
+	}))
+	defer ts.Close()
+
+	ctx := &Context{Config: &config.Config{Server: ts.URL, AppID: "component", AppSecret: "secret", Cache: cache.NewMemory()}}
+
+	_, err := ctx.GetComponentAccessToken()
+	assert.NotNil(t, err)
+
+	assert.Nil(t, ctx.SetComponentVerifyTicket("ticket"))
+	assert.Nil(t, ctx.SetAuthrRefreshToken("authorizer", "refresh"))
+
+	token, err := ctx.GetAuthrAccessToken("authorizer")
+	assert.Nil(t, err)
+	assert.Equal(t, "aat", token)
+
+	refreshToken, err := ctx.GetAuthrRefreshToken("authorizer")
+	assert.Nil(t, err)
+	assert.Equal(t, "refresh2", refreshToken)
+}
+
+type mapRefreshTokenStore map[string]string
+
+func (s mapRefreshTokenStore) GetRefreshToken(ctx context.Context, componentAppID, authorizerAppID string) (string, error) {
+	return s[componentAppID+"_"+authorizerAppID], nil
+}
+
+func (s mapRefreshTokenStore) SetRefreshToken(ctx context.Context, componentAppID, authorizerAppID, refreshToken string) error {
+	s[componentAppID+"_"+authorizerAppID] = refreshToken
+	return nil
+}
+
+func TestAuthrRefreshTokenStore(t *testing.T) {
+	ctx := &Context{Config: &config.Config{AppID: "component", Cache: cache.NewMemory()}}
+	_, err := ctx.GetAuthrAccessToken("authorizer")
+	assert.True(t, errors.Is(err, ErrAuthrRefreshTokenNotFound))
+
+	store := mapRefreshTokenStore{}
+	ctx.RefreshTokenStore = store
+	assert.Nil(t, ctx.SetAuthrRefreshToken("authorizer", "refresh"))
+	assert.Equal(t, "refresh", store["component_authorizer"])
+	refreshToken, err := ctx.GetAuthrRefreshToken("authorizer")
+	assert.Nil(t, err)
+	assert.Equal(t, "refresh", refreshToken)
+}
@@ -0,0 +1,31 @@
+package context
+
+import (
+	"context"
+	"sync"
+
+	"github.com/amazing-gao/wechat/v2/openplatform/config"
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+// Context struct
+type Context struct {
+	*config.Config
+
+	// componentAccessTokenLock 防止并发时重复获取component_access_token
+	componentAccessTokenLock sync.Mutex
+	// authrAccessTokenLock 防止并发时重复刷新authorizer_access_token
+	authrAccessTokenLock sync.Mutex
+}
+
+// RequestContext 返回发起请求使用的context，携带当前第三方平台配置的HTTP客户端及拦截器
+// parent 中已设置HTTP客户端时以 parent 为准，拦截器追加在 parent 中已有的拦截器之后
+func (ctx *Context) RequestContext(parent context.Context) context.Context {
+	if _, ok := util.HTTPClientFromContext(parent); !ok && ctx.HTTPClient != nil {
+		parent = util.WithHTTPClient(parent, ctx.HTTPClient)
+	}
+	if util.AppIDFromContext(parent) == "" {
+		parent = util.WithAppID(parent, ctx.AppID)
+	}
+	return util.WithInterceptors(parent, ctx.Interceptors...)
+}
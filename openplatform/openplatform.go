@@ -0,0 +1,88 @@
+package openplatform
+
+import (
+	"net/http"
+
+	"github.com/amazing-gao/wechat/v2/miniprogram"
+	miniConfig "github.com/amazing-gao/wechat/v2/miniprogram/config"
+	"github.com/amazing-gao/wechat/v2/officialaccount"
+	offConfig "github.com/amazing-gao/wechat/v2/officialaccount/config"
+	"github.com/amazing-gao/wechat/v2/officialaccount/server"
+	"github.com/amazing-gao/wechat/v2/openplatform/config"
+	"github.com/amazing-gao/wechat/v2/openplatform/context"
+)
+
+// OpenPlatform 微信开放平台（第三方平台）相关API
+type OpenPlatform struct {
+	*context.Context
+}
+
+// NewOpenPlatform 实例化开放平台API
+func NewOpenPlatform(cfg *config.Config) *OpenPlatform {
+	if cfg.Cache == nil {
+		panic("cache is ineed")
+	}
+	if cfg.Server == "" {
+		cfg.Server = "https://api.weixin.qq.com"
+	}
+	if cfg.MPServer == "" {
+		cfg.MPServer = "https://mp.weixin.qq.com"
+	}
+	ctx := &context.Context{
+		Config: cfg,
+	}
+	return &OpenPlatform{ctx}
+}
+
+// GetContext get Context
+func (openPlatform *OpenPlatform) GetContext() *context.Context {
+	return openPlatform.Context
+}
+
+// GetServer 授权事件接收：接收component_verify_ticket及授权变更通知
+// 收到 message.InfoTypeVerifyTicket 时需调用 SetComponentVerifyTicket 保存ticket
+func (openPlatform *OpenPlatform) GetServer(req *http.Request, writer http.ResponseWriter) *server.Server {
+	off := officialaccount.NewOfficialAccount(&offConfig.Config{
+		Server:         openPlatform.Server,
+		AppID:          openPlatform.AppID,
+		Token:          openPlatform.Token,
+		EncodingAESKey: openPlatform.EncodingAESKey,
+		Cache:          openPlatform.Cache,
+		HTTPClient:     openPlatform.HTTPClient,
+		Interceptors:   openPlatform.Interceptors,
+		Logger:         openPlatform.Logger,
+	})
+	return off.GetServer(req, writer)
+}
+
+// GetOfficialAccount 获取代授权公众号的实例，access_token由第三方平台代为刷新
+func (openPlatform *OpenPlatform) GetOfficialAccount(appID string) *officialaccount.OfficialAccount {
+	off := officialaccount.NewOfficialAccount(&offConfig.Config{
+		Server:         openPlatform.Server,
+		AppID:          appID,
+		Token:          openPlatform.Token,
+		EncodingAESKey: openPlatform.EncodingAESKey,
+		Cache:          openPlatform.Cache,
+		HTTPClient:     openPlatform.HTTPClient,
+		Interceptors:   openPlatform.Interceptors,
+		Logger:         openPlatform.Logger,
+	})
+	off.SetAccessTokenHandle(NewDefaultAuthrAccessToken(openPlatform.Context, appID))
+	return off
+}
+
+// GetMiniProgram 获取代授权小程序的实例，access_token由第三方平台代为刷新
+func (openPlatform *OpenPlatform) GetMiniProgram(appID string) *miniprogram.MiniProgram {
+	mini := miniprogram.NewMiniProgram(&miniConfig.Config{
+		Server:         openPlatform.Server,
+		AppID:          appID,
+		Token:          openPlatform.Token,
+		EncodingAESKey: openPlatform.EncodingAESKey,
+		Cache:          openPlatform.Cache,
+		HTTPClient:     openPlatform.HTTPClient,
+		Interceptors:   openPlatform.Interceptors,
+		Logger:         openPlatform.Logger,
+	})
+	mini.SetAccessTokenHandle(NewDefaultAuthrAccessToken(openPlatform.Context, appID))
+	return mini
+}
@@ -1,9 +1,59 @@
 package config
 
+import (
+	"context"
+	"strings"
+
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+// DefaultServer 微信支付接口默认域名，容灾时可使用 https://api2.mch.weixin.qq.com
+const DefaultServer = "https://api.mch.weixin.qq.com"
+
 // Config .config for pay
 type Config struct {
-	AppID     string `json:"app_id"`
-	MchID     string `json:"mch_id"`
-	Key       string `json:"key"`
-	NotifyURL string `json:"notify_url"`
+	AppID      string    `json:"app_id"`
+	MchID      string    `json:"mch_id"`
+	Key        string    `json:"key"`
+	NotifyURL  string    `json:"notify_url"`
+	Server     string    `json:"server"`    // 接口域名，为空时使用 DefaultServer
+	Sandbox    bool      `json:"sandbox"`   // 是否使用仿真测试环境（/sandboxnew/），此时Key需为沙箱密钥
+	CertPath   string    `json:"cert_path"` // 商户API证书（apiclient_cert.p12）路径，退款、企业付款等接口的参数未指定 RootCa 时使用
+	HTTPClient util.Doer `json:"-"`         // 自定义HTTP客户端，为空时使用 util.DefaultHTTPClient
+	// Interceptors 接口调用拦截器，在通过 Wechat.Use 注册的拦截器之后执行
+	Interceptors []util.Interceptor `json:"-"`
+}
+
+// GatewayURL 根据接口域名及是否为仿真测试环境拼接接口地址，path 如 /pay/unifiedorder
+// 仿真测试环境下证书接口不再带 /secapi 前缀，如 /secapi/pay/refund 对应 /sandboxnew/pay/refund
+func (cfg *Config) GatewayURL(path string) string {
+	server := cfg.Server
+	if server == "" {
+		server = DefaultServer
+	}
+	server = strings.TrimSuffix(server, "/")
+	if cfg.Sandbox {
+		path = "/sandboxnew" + strings.TrimPrefix(path, "/secapi")
+	}
+	return server + path
+}
+
+// CertFile 返回请求使用的商户API证书路径，rootCa为空时使用 CertPath
+func (cfg *Config) CertFile(rootCa string) string {
+	if rootCa != "" {
+		return rootCa
+	}
+	return cfg.CertPath
+}
+
+// RequestContext 返回发起请求使用的context，携带当前商户配置的HTTP客户端及拦截器
+// parent 中已设置HTTP客户端时以 parent 为准，拦截器追加在 parent 中已有的拦截器之后
+func (cfg *Config) RequestContext(parent context.Context) context.Context {
+	if _, ok := util.HTTPClientFromContext(parent); !ok && cfg.HTTPClient != nil {
+		parent = util.WithHTTPClient(parent, cfg.HTTPClient)
+	}
+	if util.AppIDFromContext(parent) == "" {
+		parent = util.WithAppID(parent, cfg.AppID)
+	}
+	return util.WithInterceptors(parent, cfg.Interceptors...)
 }
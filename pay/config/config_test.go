@@ -0,0 +1,19 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGatewayURL(t *testing.T) {
+	cfg := &Config{}
+	assert.Equal(t, "https://api.mch.weixin.qq.com/pay/unifiedorder", cfg.GatewayURL("/pay/unifiedorder"))
+
+	cfg.Server = "https://api2.mch.weixin.qq.com/"
+	assert.Equal(t, "https://api2.mch.weixin.qq.com/secapi/pay/refund", cfg.GatewayURL("/secapi/pay/refund"))
+
+	cfg.Sandbox = true
+	assert.Equal(t, "https://api2.mch.weixin.qq.com/sandboxnew/pay/orderquery", cfg.GatewayURL("/pay/orderquery"))
+	assert.Equal(t, "https://api2.mch.weixin.qq.com/sandboxnew/pay/refund", cfg.GatewayURL("/secapi/pay/refund"))
+}
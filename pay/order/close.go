@@ -1,6 +1,7 @@
 package order
 
 import (
+	"context"
 	"encoding/xml"
 	"errors"
 
@@ -8,7 +9,7 @@ import (
 )
 
 // https://pay.weixin.qq.com/wiki/doc/api/jsapi.php?chapter=9_3
-var closeGateway = "https://api.mch.weixin.qq.com/pay/closeorder"
+var closeGateway = "/pay/closeorder"
 
 // CloseParams 传入的参数
 type CloseParams struct {
@@ -43,6 +44,11 @@ type CloseResult struct {
 
 // CloseOrder 关闭订单
 func (o *Order) CloseOrder(p *CloseParams) (closeResult CloseResult, err error) {
+	return o.CloseOrderContext(context.Background(), p)
+}
+
+// CloseOrderContext 关闭订单
+func (o *Order) CloseOrderContext(ctx context.Context, p *CloseParams) (closeResult CloseResult, err error) {
 	nonceStr := util.RandomStr(32)
 	// 签名类型
 	if p.SignType == "" {
@@ -74,7 +80,7 @@ func (o *Order) CloseOrder(p *CloseParams) (closeResult CloseResult, err error)
 		SignType:   p.SignType,
 	}
 
-	rawRet, err = util.PostXML(closeGateway, request)
+	rawRet, err = util.PostXMLContext(o.RequestContext(ctx), o.GatewayURL(closeGateway), request)
 	if err != nil {
 		return
 	}
@@ -90,9 +96,31 @@ func (o *Order) CloseOrder(p *CloseParams) (closeResult CloseResult, err error)
 			err = nil
 			return
 		}
-		err = errors.New(*closeResult.ErrCode + *closeResult.ErrCodeDes)
+		err = newPayError("CloseOrder", closeResult.ReturnCode, closeResult.ReturnMsg, closeResult.ErrCode, closeResult.ErrCodeDes)
+		return
+	}
+	if closeResult.ReturnCode != nil {
+		err = newPayError("CloseOrder", closeResult.ReturnCode, closeResult.ReturnMsg, nil, nil)
 		return
 	}
 	err = errors.New("[msg : xmlUnmarshalError] [rawReturn : " + string(rawRet) + "] [sign : " + sign + "]")
 	return
 }
+
+// newPayError 根据返回结果中的错误信息创建*util.PayError
+func newPayError(apiName string, returnCode, returnMsg, errCode, errCodeDes *string) *util.PayError {
+	return &util.PayError{
+		APIName:    apiName,
+		ReturnCode: stringValue(returnCode),
+		ReturnMsg:  stringValue(returnMsg),
+		ErrCode:    stringValue(errCode),
+		ErrCodeDes: stringValue(errCodeDes),
+	}
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -1,6 +1,7 @@
 package order
 
 import (
+	"context"
 	"encoding/xml"
 	"errors"
 	"strconv"
@@ -12,7 +13,7 @@ import (
 )
 
 // https://pay.weixin.qq.com/wiki/doc/api/jsapi.php?chapter=9_1
-var payGateway = "https://api.mch.weixin.qq.com/pay/unifiedorder"
+var payGateway = "/pay/unifiedorder"
 
 // SUCCESS 表示支付成功
 const SUCCESS = "SUCCESS"
@@ -134,11 +135,16 @@ func (req *payRequest) BridgePayRequest(p *Params, AppID, MchID, nonceStr, sign
 
 // BridgeConfig get js bridge config
 func (o *Order) BridgeConfig(p *Params) (cfg Config, err error) {
+	return o.BridgeConfigContext(context.Background(), p)
+}
+
+// BridgeConfigContext get js bridge config
+func (o *Order) BridgeConfigContext(ctx context.Context, p *Params) (cfg Config, err error) {
 	var (
 		buffer    strings.Builder
 		timestamp = strconv.FormatInt(time.Now().Unix(), 10)
 	)
-	order, err := o.PrePayOrder(p)
+	order, err := o.PrePayOrderContext(ctx, p)
 	if err != nil {
 		return
 	}
@@ -171,12 +177,17 @@ func (o *Order) BridgeConfig(p *Params) (cfg Config, err error) {
 
 // BridgeAppConfig get app bridge config
 func (o *Order) BridgeAppConfig(p *Params) (cfg ConfigForApp, err error) {
+	return o.BridgeAppConfigContext(context.Background(), p)
+}
+
+// BridgeAppConfigContext get app bridge config
+func (o *Order) BridgeAppConfigContext(ctx context.Context, p *Params) (cfg ConfigForApp, err error) {
 	var (
 		timestamp = strconv.FormatInt(time.Now().Unix(), 10)
 		noncestr  = util.RandomStr(32)
 		_package  = "Sign=WXPay"
 	)
-	order, err := o.PrePayOrder(p)
+	order, err := o.PrePayOrderContext(ctx, p)
 	if err != nil {
 		return
 	}
@@ -209,6 +220,11 @@ func (o *Order) BridgeAppConfig(p *Params) (cfg ConfigForApp, err error) {
 
 // PrePayOrder return data for invoke wechat payment
 func (o *Order) PrePayOrder(p *Params) (payOrder PreOrder, err error) {
+	return o.PrePayOrderContext(context.Background(), p)
+}
+
+// PrePayOrderContext return data for invoke wechat payment
+func (o *Order) PrePayOrderContext(ctx context.Context, p *Params) (payOrder PreOrder, err error) {
 	nonceStr := util.RandomStr(32)
 
 	// 通知地址
@@ -252,7 +268,7 @@ func (o *Order) PrePayOrder(p *Params) (payOrder PreOrder, err error) {
 		// 如果有传入交易结束时间
 		request.TimeExpire = p.TimeExpire
 	}
-	rawRet, err := util.PostXML(payGateway, request)
+	rawRet, err := util.PostXMLContext(o.RequestContext(ctx), o.GatewayURL(payGateway), request)
 	if err != nil {
 		return
 	}
@@ -266,7 +282,11 @@ func (o *Order) PrePayOrder(p *Params) (payOrder PreOrder, err error) {
 			err = nil
 			return
 		}
-		err = errors.New(payOrder.ErrCode + payOrder.ErrCodeDes)
+		err = &util.PayError{APIName: "PrePayOrder", ReturnCode: payOrder.ReturnCode, ReturnMsg: payOrder.ReturnMsg, ErrCode: payOrder.ErrCode, ErrCodeDes: payOrder.ErrCodeDes}
+		return
+	}
+	if payOrder.ReturnCode != "" {
+		err = &util.PayError{APIName: "PrePayOrder", ReturnCode: payOrder.ReturnCode, ReturnMsg: payOrder.ReturnMsg}
 		return
 	}
 	err = errors.New("[msg : xmlUnmarshalError] [rawReturn : " + string(rawRet) + "] [sign : " + sign + "]")
@@ -275,7 +295,12 @@ func (o *Order) PrePayOrder(p *Params) (payOrder PreOrder, err error) {
 
 // PrePayID will request wechat merchant api and request for a pre payment order id
 func (o *Order) PrePayID(p *Params) (prePayID string, err error) {
-	order, err := o.PrePayOrder(p)
+	return o.PrePayIDContext(context.Background(), p)
+}
+
+// PrePayIDContext will request wechat merchant api and request for a pre payment order id
+func (o *Order) PrePayIDContext(ctx context.Context, p *Params) (prePayID string, err error) {
+	order, err := o.PrePayOrderContext(ctx, p)
 	if err != nil {
 		return
 	}
@@ -1,6 +1,7 @@
 package order
 
 import (
+	"context"
 	"encoding/xml"
 	"errors"
 
@@ -8,7 +9,7 @@ import (
 	"github.com/amazing-gao/wechat/v2/util"
 )
 
-var queryGateway = "https://api.mch.weixin.qq.com/pay/orderquery"
+var queryGateway = "/pay/orderquery"
 
 // QueryParams 传入的参数
 type QueryParams struct {
@@ -30,6 +31,11 @@ type queryRequest struct {
 
 // QueryOrder 查询订单
 func (o *Order) QueryOrder(p *QueryParams) (paidResult notify.PaidResult, err error) {
+	return o.QueryOrderContext(context.Background(), p)
+}
+
+// QueryOrderContext 查询订单
+func (o *Order) QueryOrderContext(ctx context.Context, p *QueryParams) (paidResult notify.PaidResult, err error) {
 	nonceStr := util.RandomStr(32)
 	// 签名类型
 	if p.SignType == "" {
@@ -58,7 +64,7 @@ func (o *Order) QueryOrder(p *QueryParams) (paidResult notify.PaidResult, err er
 		SignType:      p.SignType,
 	}
 
-	rawRet, err := util.PostXML(queryGateway, request)
+	rawRet, err := util.PostXMLContext(o.RequestContext(ctx), o.GatewayURL(queryGateway), request)
 	if err != nil {
 		return
 	}
@@ -74,7 +80,11 @@ func (o *Order) QueryOrder(p *QueryParams) (paidResult notify.PaidResult, err er
 			err = nil
 			return
 		}
-		err = errors.New(*paidResult.ErrCode + *paidResult.ErrCodeDes)
+		err = newPayError("QueryOrder", paidResult.ReturnCode, paidResult.ReturnMsg, paidResult.ErrCode, paidResult.ErrCodeDes)
+		return
+	}
+	if paidResult.ReturnCode != nil {
+		err = newPayError("QueryOrder", paidResult.ReturnCode, paidResult.ReturnMsg, nil, nil)
 		return
 	}
 	err = errors.New("[msg : xmlUnmarshalError] [rawReturn : " + string(rawRet) + "] [sign : " + sign + "]")
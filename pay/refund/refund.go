@@ -1,6 +1,7 @@
 package refund
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 
@@ -8,7 +9,7 @@ import (
 	"github.com/amazing-gao/wechat/v2/util"
 )
 
-var refundGateway = "https://api.mch.weixin.qq.com/secapi/pay/refund"
+var refundGateway = "/secapi/pay/refund"
 
 // Refund struct extends context
 type Refund struct {
@@ -29,7 +30,7 @@ type Params struct {
 	TotalFee      string
 	RefundFee     string
 	RefundDesc    string
-	RootCa        string // ca证书
+	RootCa        string // ca证书，为空时使用配置中的 CertPath
 	NotifyURL     string
 	SignType      string
 }
@@ -76,6 +77,11 @@ type Response struct {
 
 // Refund 退款申请
 func (refund *Refund) Refund(p *Params) (rsp Response, err error) {
+	return refund.RefundContext(context.Background(), p)
+}
+
+// RefundContext 退款申请
+func (refund *Refund) RefundContext(ctx context.Context, p *Params) (rsp Response, err error) {
 	param := refund.GetSignParam(p)
 
 	sign, err := util.ParamSign(param, refund.Key)
@@ -102,7 +108,7 @@ func (refund *Refund) Refund(p *Params) (rsp Response, err error) {
 		req.TransactionID = p.TransactionID
 	}
 
-	rawRet, err := util.PostXMLWithTLS(refundGateway, req, p.RootCa, refund.MchID)
+	rawRet, err := util.PostXMLWithTLSContext(refund.RequestContext(ctx), refund.GatewayURL(refundGateway), req, refund.CertFile(p.RootCa), refund.MchID)
 	if err != nil {
 		return
 	}
@@ -115,7 +121,11 @@ func (refund *Refund) Refund(p *Params) (rsp Response, err error) {
 			err = nil
 			return
 		}
-		err = fmt.Errorf("refund error, errcode=%s,errmsg=%s", rsp.ErrCode, rsp.ErrCodeDes)
+		err = &util.PayError{APIName: "Refund", ReturnCode: rsp.ReturnCode, ReturnMsg: rsp.ReturnMsg, ErrCode: rsp.ErrCode, ErrCodeDes: rsp.ErrCodeDes}
+		return
+	}
+	if rsp.ReturnCode != "" {
+		err = &util.PayError{APIName: "Refund", ReturnCode: rsp.ReturnCode, ReturnMsg: rsp.ReturnMsg}
 		return
 	}
 	err = fmt.Errorf("[msg : xmlUnmarshalError] [rawReturn : %s] [sign : %s]", string(rawRet), sign)
@@ -1,6 +1,7 @@
 package transfer
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 	"strconv"
@@ -11,7 +12,7 @@ import (
 
 // 付款到零钱
 // https://pay.weixin.qq.com/wiki/doc/api/tools/mch_pay.php?chapter=14_2
-var walletTransferGateway = "https://api.mch.weixin.qq.com/mmpaymkttransfers/promotion/transfers"
+var walletTransferGateway = "/mmpaymkttransfers/promotion/transfers"
 
 // Transfer struct extends context
 type Transfer struct {
@@ -34,7 +35,7 @@ type Params struct {
 	Amount         int
 	Desc           string
 	SpbillCreateIP string
-	RootCa         string // ca证书
+	RootCa         string // ca证书，为空时使用配置中的 CertPath
 }
 
 // request 接口请求参数
@@ -71,6 +72,11 @@ type Response struct {
 
 // WalletTransfer 付款到零钱
 func (transfer *Transfer) WalletTransfer(p *Params) (rsp *Response, err error) {
+	return transfer.WalletTransferContext(context.Background(), p)
+}
+
+// WalletTransferContext 付款到零钱
+func (transfer *Transfer) WalletTransferContext(ctx context.Context, p *Params) (rsp *Response, err error) {
 	nonceStr := util.RandomStr(32)
 	param := make(map[string]string)
 	param["mch_appid"] = transfer.AppID
@@ -114,7 +120,7 @@ func (transfer *Transfer) WalletTransfer(p *Params) (rsp *Response, err error) {
 		req.CheckName = "FORCE_CHECK"
 		req.ReUserName = p.ReUserName
 	}
-	rawRet, err := util.PostXMLWithTLS(walletTransferGateway, req, p.RootCa, transfer.MchID)
+	rawRet, err := util.PostXMLWithTLSContext(transfer.RequestContext(ctx), transfer.GatewayURL(walletTransferGateway), req, transfer.CertFile(p.RootCa), transfer.MchID)
 	if err != nil {
 		return
 	}
@@ -127,7 +133,11 @@ func (transfer *Transfer) WalletTransfer(p *Params) (rsp *Response, err error) {
 			err = nil
 			return
 		}
-		err = fmt.Errorf("transfer error, errcode=%s,errmsg=%s", rsp.ErrCode, rsp.ErrCodeDes)
+		err = &util.PayError{APIName: "WalletTransfer", ReturnCode: rsp.ReturnCode, ReturnMsg: rsp.ReturnMsg, ErrCode: rsp.ErrCode, ErrCodeDes: rsp.ErrCodeDes}
+		return
+	}
+	if rsp.ReturnCode != "" {
+		err = &util.PayError{APIName: "WalletTransfer", ReturnCode: rsp.ReturnCode, ReturnMsg: rsp.ReturnMsg}
 		return
 	}
 	err = fmt.Errorf("[msg : xmlUnmarshalError] [rawReturn : %s] [sign : %s]", string(rawRet), sign)
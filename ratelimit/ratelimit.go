@@ -0,0 +1,196 @@
+// Package ratelimit 客户端限流及接口调用次数统计
+// 按AppID及接口路径使用令牌桶限流，令牌桶状态可通过 cache.Redis 在多实例间共享；
+// 同时在本地按天统计每个接口的调用次数，达到 Rule.DailyQuota 后不再发出请求，避免耗尽微信的每日调用额度
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/amazing-gao/wechat/v2/cache"
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+// ErrDailyQuotaExceeded 接口当日调用次数已达到 Rule.DailyQuota
+var ErrDailyQuotaExceeded = errors.New("ratelimit: daily quota exceeded")
+
+// quotaLocation 微信接口调用次数按北京时间每日0点重置
+var quotaLocation = time.FixedZone("CST", 8*60*60)
+
+// Rule 限流规则
+type Rule struct {
+	Rate       float64 `json:"rate"`        // 每秒允许的请求数，小于等于0时不限流
+	Burst      int     `json:"burst"`       // 允许的突发请求数，默认1
+	DailyQuota int64   `json:"daily_quota"` // 每日最多调用次数，小于等于0时不限制
+}
+
+// Opts 限流配置
+type Opts struct {
+	Default  Rule                       `json:"default"`   // 未单独配置的接口使用的规则
+	Rules    map[string]Rule            `json:"rules"`     // 按接口路径配置的规则，如 /cgi-bin/user/info
+	AppRules map[string]map[string]Rule `json:"app_rules"` // 按AppID及接口路径配置的规则，优先于 Rules
+	Bucket   cache.TokenBucket          `json:"-"`         // 令牌桶状态存储，为空时使用 cache.NewMemoryTokenBucket，可使用 cache.Redis 在多实例间共享
+	Prefix   string                     `json:"prefix"`    // 令牌桶key的前缀，默认 gowechat_ratelimit_
+}
+
+// Limiter 按AppID及接口路径限流，并在本地统计每日调用次数
+type Limiter struct {
+	opts   Opts
+	bucket cache.TokenBucket
+
+	mu     sync.Mutex
+	day    string
+	counts map[string]map[string]int64
+}
+
+// New 创建Limiter
+func New(opts *Opts) *Limiter {
+	l := &Limiter{
+		opts:   *opts,
+		bucket: opts.Bucket,
+		counts: map[string]map[string]int64{},
+	}
+	if l.bucket == nil {
+		l.bucket = cache.NewMemoryTokenBucket()
+	}
+	if l.opts.Prefix == "" {
+		l.opts.Prefix = "gowechat_ratelimit_"
+	}
+	return l
+}
+
+// Wait 等待appID下path对应的接口可以调用，当日调用次数达到限制时返回 ErrDailyQuotaExceeded
+// 等待令牌时ctx取消或令牌桶出错，本次调用不计入当日调用次数，令牌桶实现了 cache.TokenRefunder 时同时归还预占的令牌
+func (l *Limiter) Wait(ctx context.Context, appID, path string) error {
+	rule := l.rule(appID, path)
+	// 先占用当日的调用次数，避免并发调用超出 DailyQuota
+	if !l.count(appID, path, rule.DailyQuota) {
+		return fmt.Errorf("%w: appid=%s , path=%s", ErrDailyQuotaExceeded, appID, path)
+	}
+	if err := l.take(ctx, appID, path, rule); err != nil {
+		l.uncount(appID, path)
+		return err
+	}
+	return nil
+}
+
+// take 从令牌桶中获取令牌，需要等待时等待到可以调用或ctx取消
+func (l *Limiter) take(ctx context.Context, appID, path string, rule Rule) error {
+	if rule.Rate <= 0 {
+		return nil
+	}
+	key := l.opts.Prefix + appID + "_" + path
+	wait, err := l.bucket.TakeToken(ctx, key, rule.Rate, rule.Burst)
+	if err != nil || wait <= 0 {
+		return err
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		// 放弃本次调用，归还预占的令牌；ctx已取消，使用新的context
+		if refunder, ok := l.bucket.(cache.TokenRefunder); ok {
+			_ = refunder.RefundToken(context.Background(), key, rule.Burst)
+		}
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// ForApp 返回绑定appID的 util.Limiter，可通过 util.WithLimiter 或各模块配置的 RateLimiter 使用
+func (l *Limiter) ForApp(appID string) util.Limiter {
+	return appLimiter{l, appID}
+}
+
+// Count 返回appID下path对应接口当日的调用次数
+func (l *Limiter) Count(appID, path string) int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.resetIfNewDay()
+	return l.counts[appID][path]
+}
+
+// Usage 返回appID下各接口当日的调用次数
+func (l *Limiter) Usage(appID string) map[string]int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.resetIfNewDay()
+	usage := make(map[string]int64, len(l.counts[appID]))
+	for path, count := range l.counts[appID] {
+		usage[path] = count
+	}
+	return usage
+}
+
+// SetCount 设置appID下path对应接口当日的调用次数，可用于根据 quota/get 接口返回的used校准本地计数
+func (l *Limiter) SetCount(appID, path string, count int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.resetIfNewDay()
+	if l.counts[appID] == nil {
+		l.counts[appID] = map[string]int64{}
+	}
+	l.counts[appID][path] = count
+}
+
+// rule 获取appID下path对应的规则
+func (l *Limiter) rule(appID, path string) Rule {
+	if rule, ok := l.opts.AppRules[appID][path]; ok {
+		return rule
+	}
+	if rule, ok := l.opts.Rules[path]; ok {
+		return rule
+	}
+	return l.opts.Default
+}
+
+// count 当日调用次数未达到quota时计数加一并返回true
+func (l *Limiter) count(appID, path string, quota int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.resetIfNewDay()
+	counts := l.counts[appID]
+	if counts == nil {
+		counts = map[string]int64{}
+		l.counts[appID] = counts
+	}
+	if quota > 0 && counts[path] >= quota {
+		return false
+	}
+	counts[path]++
+	return true
+}
+
+// uncount 撤销 count 占用的调用次数，已跨天时不处理
+func (l *Limiter) uncount(appID, path string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.resetIfNewDay()
+	if l.counts[appID][path] > 0 {
+		l.counts[appID][path]--
+	}
+}
+
+// resetIfNewDay 跨天后清空计数，调用方需持有锁
+func (l *Limiter) resetIfNewDay() {
+	day := time.Now().In(quotaLocation).Format("20060102")
+	if day != l.day {
+		l.day = day
+		l.counts = map[string]map[string]int64{}
+	}
+}
+
+// appLimiter 绑定AppID的 util.Limiter
+type appLimiter struct {
+	limiter *Limiter
+	appID   string
+}
+
+// Wait 等待path对应的接口可以调用
+func (a appLimiter) Wait(ctx context.Context, path string) error {
+	return a.limiter.Wait(ctx, a.appID, path)
+}
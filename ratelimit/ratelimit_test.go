@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amazing-gao/wechat/v2/cache"
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (fn doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+// refundBucket 记录归还令牌次数的令牌桶
+type refundBucket struct {
+	*cache.MemoryTokenBucket
+	refunds int
+}
+
+func (b *refundBucket) RefundToken(ctx context.Context, key string, burst int) error {
+	b.refunds++
+	return b.MemoryTokenBucket.RefundToken(ctx, key, burst)
+}
+
+func TestLimiterDailyQuota(t *testing.T) {
+	limiter := New(&Opts{
+		Rules: map[string]Rule{
+			"/cgi-bin/user/info": {DailyQuota: 2},
+		},
+		AppRules: map[string]map[string]Rule{
+			"app2": {"/cgi-bin/user/info": {DailyQuota: 1}},
+		},
+	})
+	ctx := context.Background()
+	assert.Nil(t, limiter.Wait(ctx, "app1", "/cgi-bin/user/info"))
+	assert.Nil(t, limiter.Wait(ctx, "app1", "/cgi-bin/user/info"))
+	assert.True(t, errors.Is(limiter.Wait(ctx, "app1", "/cgi-bin/user/info"), ErrDailyQuotaExceeded))
+	assert.Nil(t, limiter.Wait(ctx, "app1", "/cgi-bin/user/get"))
+	assert.Equal(t, map[string]int64{"/cgi-bin/user/info": 2, "/cgi-bin/user/get": 1}, limiter.Usage("app1"))
+
+	assert.Nil(t, limiter.Wait(ctx, "app2", "/cgi-bin/user/info"))
+	assert.True(t, errors.Is(limiter.Wait(ctx, "app2", "/cgi-bin/user/info"), ErrDailyQuotaExceeded))
+
+	// 根据quota/get校准后恢复调用
+	limiter.SetCount("app1", "/cgi-bin/user/info", 1)
+	assert.Nil(t, limiter.Wait(ctx, "app1", "/cgi-bin/user/info"))
+	assert.Equal(t, int64(2), limiter.Count("app1", "/cgi-bin/user/info"))
+}
+
+func TestLimiterRate(t *testing.T) {
+	limiter := New(&Opts{Default: Rule{Rate: 50, Burst: 1}})
+	requests := 0
+	client := doerFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return &http.Response{StatusCode: http.StatusBadGateway, Body: http.NoBody}, nil
+	})
+	ctx := util.WithLimiter(util.WithHTTPClient(context.Background(), client), limiter.ForApp("app1"))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, _ = util.HTTPGetContext(ctx, "http://example.com/cgi-bin/user/info")
+	}
+	// 首个请求使用初始令牌，之后每个请求等待20毫秒
+	assert.GreaterOrEqual(t, time.Since(start), 35*time.Millisecond)
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, int64(3), limiter.Count("app1", "/cgi-bin/user/info"))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err := util.HTTPGetContext(canceled, "http://example.com/cgi-bin/user/info")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, int64(3), limiter.Count("app1", "/cgi-bin/user/info"))
+
+	// 重试不重复计数
+	retryCtx := util.WithRetryPolicy(ctx, &util.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond})
+	_, _ = util.HTTPGetContext(retryCtx, "http://example.com/cgi-bin/user/info")
+	assert.Equal(t, 6, requests)
+	assert.Equal(t, int64(4), limiter.Count("app1", "/cgi-bin/user/info"))
+}
+
+func TestLimiterWaitCanceled(t *testing.T) {
+	bucket := &refundBucket{MemoryTokenBucket: cache.NewMemoryTokenBucket()}
+	limiter := New(&Opts{Default: Rule{Rate: 1, Burst: 1, DailyQuota: 2}, Bucket: bucket})
+	assert.Nil(t, limiter.Wait(context.Background(), "app1", "/cgi-bin/user/info"))
+
+	// 等待令牌时超时的调用不计入当日调用次数
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, limiter.Wait(ctx, "app1", "/cgi-bin/user/info"), context.DeadlineExceeded)
+	assert.Equal(t, int64(1), limiter.Count("app1", "/cgi-bin/user/info"))
+	// 归还预占的令牌
+	assert.Equal(t, 1, bucket.refunds)
+}
@@ -0,0 +1,341 @@
+package wechat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sync"
+
+	"github.com/amazing-gao/wechat/v2/miniprogram"
+	miniConfig "github.com/amazing-gao/wechat/v2/miniprogram/config"
+	"github.com/amazing-gao/wechat/v2/officialaccount"
+	offConfig "github.com/amazing-gao/wechat/v2/officialaccount/config"
+	"github.com/amazing-gao/wechat/v2/pay"
+	payConfig "github.com/amazing-gao/wechat/v2/pay/config"
+)
+
+// ErrAccountNotRegistered 账号未注册
+var ErrAccountNotRegistered = errors.New("wechat: account not registered")
+
+// AccountConfigs 一组账号配置，由 ConfigSource 加载
+type AccountConfigs struct {
+	OfficialAccounts []*offConfig.Config  `json:"official_accounts"`
+	MiniPrograms     []*miniConfig.Config `json:"mini_programs"`
+	Pays             []*payConfig.Config  `json:"pays"`
+}
+
+// validate 检查配置不为null且AppID不为空
+func (configs *AccountConfigs) validate() error {
+	if configs == nil {
+		return errors.New("configs is nil")
+	}
+	for i, cfg := range configs.OfficialAccounts {
+		if cfg == nil || cfg.AppID == "" {
+			return fmt.Errorf("official_accounts[%d]: app_id is required", i)
+		}
+	}
+	for i, cfg := range configs.MiniPrograms {
+		if cfg == nil || cfg.AppID == "" {
+			return fmt.Errorf("mini_programs[%d]: app_id is required", i)
+		}
+	}
+	for i, cfg := range configs.Pays {
+		if cfg == nil || cfg.AppID == "" {
+			return fmt.Errorf("pays[%d]: app_id is required", i)
+		}
+	}
+	return nil
+}
+
+// ConfigSource 账号配置来源
+type ConfigSource interface {
+	Load(ctx context.Context) (*AccountConfigs, error)
+}
+
+// ConfigSourceFunc 通过回调函数加载账号配置，如从数据库或配置中心读取
+type ConfigSourceFunc func(ctx context.Context) (*AccountConfigs, error)
+
+// Load 加载账号配置
+func (f ConfigSourceFunc) Load(ctx context.Context) (*AccountConfigs, error) {
+	return f(ctx)
+}
+
+// FileConfigSource 从json文件加载账号配置，每次加载时重新读取文件
+type FileConfigSource string
+
+// Load 加载账号配置
+func (path FileConfigSource) Load(ctx context.Context) (*AccountConfigs, error) {
+	data, err := ioutil.ReadFile(string(path))
+	if err != nil {
+		return nil, err
+	}
+	configs := &AccountConfigs{}
+	if err = json.Unmarshal(data, configs); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return configs, nil
+}
+
+// EnvConfigSource 从环境变量加载账号配置，环境变量的值为与 FileConfigSource 相同格式的json
+type EnvConfigSource string
+
+// Load 加载账号配置
+func (key EnvConfigSource) Load(ctx context.Context) (*AccountConfigs, error) {
+	data, ok := os.LookupEnv(string(key))
+	if !ok {
+		return nil, fmt.Errorf("environment variable %s not set", key)
+	}
+	configs := &AccountConfigs{}
+	if err := json.Unmarshal([]byte(data), configs); err != nil {
+		return nil, fmt.Errorf("decode environment variable %s: %w", key, err)
+	}
+	return configs, nil
+}
+
+// registry 按AppID保存账号配置及已创建的实例
+type registry struct {
+	mu               sync.RWMutex
+	offConfigs       map[string]*offConfig.Config
+	officialAccounts map[string]*officialaccount.OfficialAccount
+	miniConfigs      map[string]*miniConfig.Config
+	miniPrograms     map[string]*miniprogram.MiniProgram
+	payConfigs       map[string]*payConfig.Config
+	pays             map[string]*pay.Pay
+}
+
+// init 初始化各map，调用方需持有写锁
+func (r *registry) init() {
+	if r.offConfigs != nil {
+		return
+	}
+	r.offConfigs = map[string]*offConfig.Config{}
+	r.officialAccounts = map[string]*officialaccount.OfficialAccount{}
+	r.miniConfigs = map[string]*miniConfig.Config{}
+	r.miniPrograms = map[string]*miniprogram.MiniProgram{}
+	r.payConfigs = map[string]*payConfig.Config{}
+	r.pays = map[string]*pay.Pay{}
+}
+
+// dropOfficialAccount 移除已创建的公众号实例并停止其后台刷新，调用方需持有写锁
+func (r *registry) dropOfficialAccount(appID string) {
+	if officialAccount, ok := r.officialAccounts[appID]; ok {
+		officialAccount.StopTokenRefresher()
+		delete(r.officialAccounts, appID)
+	}
+}
+
+// dropMiniProgram 移除已创建的小程序实例并停止其后台刷新，调用方需持有写锁
+func (r *registry) dropMiniProgram(appID string) {
+	if miniProgram, ok := r.miniPrograms[appID]; ok {
+		miniProgram.StopTokenRefresher()
+		delete(r.miniPrograms, appID)
+	}
+}
+
+// RegisterOfficialAccount 按AppID注册公众号配置，已注册的同AppID配置会被替换，实例在首次获取时创建
+func (wc *Wechat) RegisterOfficialAccount(cfg *offConfig.Config) {
+	wc.registry.mu.Lock()
+	defer wc.registry.mu.Unlock()
+	wc.registry.init()
+	wc.registry.offConfigs[cfg.AppID] = cfg
+	wc.registry.dropOfficialAccount(cfg.AppID)
+}
+
+// RemoveOfficialAccount 移除已注册的公众号
+func (wc *Wechat) RemoveOfficialAccount(appID string) {
+	wc.registry.mu.Lock()
+	defer wc.registry.mu.Unlock()
+	delete(wc.registry.offConfigs, appID)
+	wc.registry.dropOfficialAccount(appID)
+}
+
+// OfficialAccountAppIDs 返回已注册的公众号AppID
+func (wc *Wechat) OfficialAccountAppIDs() []string {
+	wc.registry.mu.RLock()
+	defer wc.registry.mu.RUnlock()
+	appIDs := make([]string, 0, len(wc.registry.offConfigs))
+	for appID := range wc.registry.offConfigs {
+		appIDs = append(appIDs, appID)
+	}
+	return appIDs
+}
+
+// GetOfficialAccountByAppID 获取已注册的公众号实例，实例创建后会被缓存，未注册时返回 ErrAccountNotRegistered
+func (wc *Wechat) GetOfficialAccountByAppID(appID string) (*officialaccount.OfficialAccount, error) {
+	wc.registry.mu.RLock()
+	officialAccount, ok := wc.registry.officialAccounts[appID]
+	wc.registry.mu.RUnlock()
+	if ok {
+		return officialAccount, nil
+	}
+
+	wc.registry.mu.Lock()
+	defer wc.registry.mu.Unlock()
+	if officialAccount, ok = wc.registry.officialAccounts[appID]; ok {
+		return officialAccount, nil
+	}
+	cfg, ok := wc.registry.offConfigs[appID]
+	if !ok {
+		return nil, fmt.Errorf("%w: official account appid=%s", ErrAccountNotRegistered, appID)
+	}
+	officialAccount = wc.GetOfficialAccount(cfg)
+	wc.registry.officialAccounts[appID] = officialAccount
+	return officialAccount, nil
+}
+
+// RegisterMiniProgram 按AppID注册小程序配置，已注册的同AppID配置会被替换，实例在首次获取时创建
+func (wc *Wechat) RegisterMiniProgram(cfg *miniConfig.Config) {
+	wc.registry.mu.Lock()
+	defer wc.registry.mu.Unlock()
+	wc.registry.init()
+	wc.registry.miniConfigs[cfg.AppID] = cfg
+	wc.registry.dropMiniProgram(cfg.AppID)
+}
+
+// RemoveMiniProgram 移除已注册的小程序
+func (wc *Wechat) RemoveMiniProgram(appID string) {
+	wc.registry.mu.Lock()
+	defer wc.registry.mu.Unlock()
+	delete(wc.registry.miniConfigs, appID)
+	wc.registry.dropMiniProgram(appID)
+}
+
+// MiniProgramAppIDs 返回已注册的小程序AppID
+func (wc *Wechat) MiniProgramAppIDs() []string {
+	wc.registry.mu.RLock()
+	defer wc.registry.mu.RUnlock()
+	appIDs := make([]string, 0, len(wc.registry.miniConfigs))
+	for appID := range wc.registry.miniConfigs {
+		appIDs = append(appIDs, appID)
+	}
+	return appIDs
+}
+
+// GetMiniProgramByAppID 获取已注册的小程序实例，实例创建后会被缓存，未注册时返回 ErrAccountNotRegistered
+func (wc *Wechat) GetMiniProgramByAppID(appID string) (*miniprogram.MiniProgram, error) {
+	wc.registry.mu.RLock()
+	miniProgram, ok := wc.registry.miniPrograms[appID]
+	wc.registry.mu.RUnlock()
+	if ok {
+		return miniProgram, nil
+	}
+
+	wc.registry.mu.Lock()
+	defer wc.registry.mu.Unlock()
+	if miniProgram, ok = wc.registry.miniPrograms[appID]; ok {
+		return miniProgram, nil
+	}
+	cfg, ok := wc.registry.miniConfigs[appID]
+	if !ok {
+		return nil, fmt.Errorf("%w: mini program appid=%s", ErrAccountNotRegistered, appID)
+	}
+	miniProgram = wc.GetMiniProgram(cfg)
+	wc.registry.miniPrograms[appID] = miniProgram
+	return miniProgram, nil
+}
+
+// RegisterPay 按AppID注册微信支付配置，已注册的同AppID配置会被替换，实例在首次获取时创建
+func (wc *Wechat) RegisterPay(cfg *payConfig.Config) {
+	wc.registry.mu.Lock()
+	defer wc.registry.mu.Unlock()
+	wc.registry.init()
+	wc.registry.payConfigs[cfg.AppID] = cfg
+	delete(wc.registry.pays, cfg.AppID)
+}
+
+// RemovePay 移除已注册的微信支付配置
+func (wc *Wechat) RemovePay(appID string) {
+	wc.registry.mu.Lock()
+	defer wc.registry.mu.Unlock()
+	delete(wc.registry.payConfigs, appID)
+	delete(wc.registry.pays, appID)
+}
+
+// PayAppIDs 返回已注册的微信支付AppID
+func (wc *Wechat) PayAppIDs() []string {
+	wc.registry.mu.RLock()
+	defer wc.registry.mu.RUnlock()
+	appIDs := make([]string, 0, len(wc.registry.payConfigs))
+	for appID := range wc.registry.payConfigs {
+		appIDs = append(appIDs, appID)
+	}
+	return appIDs
+}
+
+// GetPayByAppID 获取已注册的微信支付实例，实例创建后会被缓存，未注册时返回 ErrAccountNotRegistered
+func (wc *Wechat) GetPayByAppID(appID string) (*pay.Pay, error) {
+	wc.registry.mu.RLock()
+	p, ok := wc.registry.pays[appID]
+	wc.registry.mu.RUnlock()
+	if ok {
+		return p, nil
+	}
+
+	wc.registry.mu.Lock()
+	defer wc.registry.mu.Unlock()
+	if p, ok = wc.registry.pays[appID]; ok {
+		return p, nil
+	}
+	cfg, ok := wc.registry.payConfigs[appID]
+	if !ok {
+		return nil, fmt.Errorf("%w: pay appid=%s", ErrAccountNotRegistered, appID)
+	}
+	p = wc.GetPay(cfg)
+	wc.registry.pays[appID] = p
+	return p, nil
+}
+
+// LoadAccounts 从source加载账号配置并替换当前注册的全部账号
+// 配置未变化的账号保留已创建的实例，source中不存在或配置变化的账号会移除已创建的实例并停止其 StartTokenRefresher 启动的后台刷新
+// 加载失败或配置为null、AppID为空时返回错误，不修改已注册的账号
+func (wc *Wechat) LoadAccounts(ctx context.Context, source ConfigSource) error {
+	configs, err := source.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("load accounts: %w", err)
+	}
+	if err = configs.validate(); err != nil {
+		return fmt.Errorf("load accounts: %w", err)
+	}
+
+	wc.registry.mu.Lock()
+	defer wc.registry.mu.Unlock()
+	wc.registry.init()
+
+	offConfigs := make(map[string]*offConfig.Config, len(configs.OfficialAccounts))
+	for _, cfg := range configs.OfficialAccounts {
+		offConfigs[cfg.AppID] = cfg
+	}
+	for appID := range wc.registry.officialAccounts {
+		if !reflect.DeepEqual(offConfigs[appID], wc.registry.offConfigs[appID]) {
+			wc.registry.dropOfficialAccount(appID)
+		}
+	}
+	wc.registry.offConfigs = offConfigs
+
+	miniConfigs := make(map[string]*miniConfig.Config, len(configs.MiniPrograms))
+	for _, cfg := range configs.MiniPrograms {
+		miniConfigs[cfg.AppID] = cfg
+	}
+	for appID := range wc.registry.miniPrograms {
+		if !reflect.DeepEqual(miniConfigs[appID], wc.registry.miniConfigs[appID]) {
+			wc.registry.dropMiniProgram(appID)
+		}
+	}
+	wc.registry.miniConfigs = miniConfigs
+
+	payConfigs := make(map[string]*payConfig.Config, len(configs.Pays))
+	for _, cfg := range configs.Pays {
+		payConfigs[cfg.AppID] = cfg
+	}
+	for appID := range wc.registry.pays {
+		if !reflect.DeepEqual(payConfigs[appID], wc.registry.payConfigs[appID]) {
+			delete(wc.registry.pays, appID)
+		}
+	}
+	wc.registry.payConfigs = payConfigs
+	return nil
+}
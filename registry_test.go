@@ -0,0 +1,154 @@
+package wechat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amazing-gao/wechat/v2/cache"
+	miniConfig "github.com/amazing-gao/wechat/v2/miniprogram/config"
+	offConfig "github.com/amazing-gao/wechat/v2/officialaccount/config"
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+func TestRegistry(t *testing.T) {
+	wc := NewWechat()
+	wc.SetCache(cache.NewMemory())
+	wc.Use(util.Interceptor{})
+
+	_, err := wc.GetOfficialAccountByAppID("wx1")
+	assert.True(t, errors.Is(err, ErrAccountNotRegistered))
+
+	cfg := &offConfig.Config{AppID: "wx1", AppSecret: "secret1"}
+	wc.RegisterOfficialAccount(cfg)
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			officialAccount, err := wc.GetOfficialAccountByAppID("wx1")
+			assert.Nil(t, err)
+			results[i] = officialAccount
+		}(i)
+	}
+	wg.Wait()
+	for _, result := range results {
+		assert.Same(t, results[0], result)
+	}
+	// 创建实例不修改注册的配置
+	assert.Empty(t, cfg.Interceptors)
+	officialAccount, _ := wc.GetOfficialAccountByAppID("wx1")
+	assert.Len(t, officialAccount.GetContext().Interceptors, 1)
+
+	wc.RemoveOfficialAccount("wx1")
+	_, err = wc.GetOfficialAccountByAppID("wx1")
+	assert.True(t, errors.Is(err, ErrAccountNotRegistered))
+}
+
+func TestLoadAccounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	write := func(data string) {
+		assert.Nil(t, ioutil.WriteFile(path, []byte(data), 0600))
+	}
+	write(`{"official_accounts":[{"app_id":"wx1","app_secret":"s1"},{"app_id":"wx2","app_secret":"s2"}],
+		"mini_programs":[{"app_id":"wx3","app_secret":"s3"}],"pays":[{"app_id":"wx1","mch_id":"m1"}]}`)
+
+	wc := NewWechat()
+	wc.SetCache(cache.NewMemory())
+	assert.Nil(t, wc.LoadAccounts(context.Background(), FileConfigSource(path)))
+	appIDs := wc.OfficialAccountAppIDs()
+	sort.Strings(appIDs)
+	assert.Equal(t, []string{"wx1", "wx2"}, appIDs)
+	assert.Equal(t, []string{"wx3"}, wc.MiniProgramAppIDs())
+	assert.Equal(t, []string{"wx1"}, wc.PayAppIDs())
+
+	wx1, err := wc.GetOfficialAccountByAppID("wx1")
+	assert.Nil(t, err)
+	wx2, err := wc.GetOfficialAccountByAppID("wx2")
+	assert.Nil(t, err)
+
+	// wx1 未变化，wx2 修改了密钥，wx3 被移除
+	write(`{"official_accounts":[{"app_id":"wx1","app_secret":"s1"},{"app_id":"wx2","app_secret":"s2-new"}]}`)
+	assert.Nil(t, wc.LoadAccounts(context.Background(), FileConfigSource(path)))
+	newWx1, _ := wc.GetOfficialAccountByAppID("wx1")
+	newWx2, _ := wc.GetOfficialAccountByAppID("wx2")
+	assert.Same(t, wx1, newWx1)
+	assert.NotSame(t, wx2, newWx2)
+	assert.Equal(t, "s2-new", newWx2.GetContext().AppSecret)
+	_, err = wc.GetMiniProgramByAppID("wx3")
+	assert.True(t, errors.Is(err, ErrAccountNotRegistered))
+
+	// 加载失败时保留已注册的账号
+	assert.NotNil(t, wc.LoadAccounts(context.Background(), EnvConfigSource("WECHAT_TEST_ACCOUNTS_NOT_SET")))
+	assert.Len(t, wc.OfficialAccountAppIDs(), 2)
+
+	os.Setenv("WECHAT_TEST_ACCOUNTS", `{"mini_programs":[{"app_id":"wx4"}]}`)
+	defer os.Unsetenv("WECHAT_TEST_ACCOUNTS")
+	assert.Nil(t, wc.LoadAccounts(context.Background(), EnvConfigSource("WECHAT_TEST_ACCOUNTS")))
+	assert.Empty(t, wc.OfficialAccountAppIDs())
+	assert.Equal(t, []string{"wx4"}, wc.MiniProgramAppIDs())
+}
+
+// blockingRenewer 刷新时阻塞直到ctx取消
+type blockingRenewer struct {
+	started chan struct{}
+	stopped chan struct{}
+}
+
+func (r *blockingRenewer) GetAccessToken() (string, error) {
+	return "ACCESS_TOKEN", nil
+}
+
+func (r *blockingRenewer) RenewContext(ctx context.Context) (time.Duration, error) {
+	close(r.started)
+	<-ctx.Done()
+	close(r.stopped)
+	return 0, ctx.Err()
+}
+
+func TestLoadAccountsInvalid(t *testing.T) {
+	wc := NewWechat()
+	wc.SetCache(cache.NewMemory())
+	wc.RegisterMiniProgram(&miniConfig.Config{AppID: "wx1"})
+	miniProgram, err := wc.GetMiniProgramByAppID("wx1")
+	assert.Nil(t, err)
+	renewer := &blockingRenewer{started: make(chan struct{}), stopped: make(chan struct{})}
+	miniProgram.SetAccessTokenHandle(renewer)
+	miniProgram.StartTokenRefresher(context.Background())
+	<-renewer.started
+
+	// 配置为null或AppID为空时不修改已注册的账号
+	for _, data := range []string{
+		`{"official_accounts":[null]}`,
+		`{"mini_programs":[{"app_id":""}]}`,
+		`{"pays":[{"mch_id":"m1"}]}`,
+	} {
+		source := ConfigSourceFunc(func(ctx context.Context) (*AccountConfigs, error) {
+			configs := &AccountConfigs{}
+			return configs, json.Unmarshal([]byte(data), configs)
+		})
+		assert.NotNil(t, wc.LoadAccounts(context.Background(), source), data)
+		assert.Equal(t, []string{"wx1"}, wc.MiniProgramAppIDs())
+	}
+
+	// 移除的实例停止后台刷新
+	assert.Nil(t, wc.LoadAccounts(context.Background(), ConfigSourceFunc(func(ctx context.Context) (*AccountConfigs, error) {
+		return &AccountConfigs{}, nil
+	})))
+	select {
+	case <-renewer.stopped:
+	case <-time.After(time.Second):
+		t.Fatal("token refresher not stopped")
+	}
+}
@@ -0,0 +1,47 @@
+package util
+
+import (
+	"context"
+	"time"
+)
+
+// Callback 一次微信回调（接收消息与事件）的处理信息
+type Callback struct {
+	AppID     string        // 公众号、小程序的AppID
+	MsgType   string        // 消息类型，解析消息失败时为空
+	Event     string        // 事件类型，MsgType为event时有效
+	StartTime time.Time     // 开始处理的时间
+	Latency   time.Duration // 处理耗时，OnFinish 中有效
+}
+
+// CallbackHook 回调处理的钩子，各回调均可为空，可用于日志、链路追踪及监控
+type CallbackHook struct {
+	// OnStart 开始处理回调时调用，返回的context传递给之后的钩子，可用于保存span等状态
+	OnStart func(ctx context.Context, callback *Callback) context.Context
+	// OnFinish 处理完成后调用，err为校验、解析消息或构建回复时的错误
+	OnFinish func(ctx context.Context, callback *Callback, err error)
+}
+
+// StartCallback 依次调用 OnStart，返回 FinishCallback 使用的context
+func StartCallback(ctx context.Context, hooks []CallbackHook, callback *Callback) context.Context {
+	callback.StartTime = time.Now()
+	for _, hook := range hooks {
+		if hook.OnStart == nil {
+			continue
+		}
+		if next := hook.OnStart(ctx, callback); next != nil {
+			ctx = next
+		}
+	}
+	return ctx
+}
+
+// FinishCallback 记录耗时并依次调用 OnFinish
+func FinishCallback(ctx context.Context, hooks []CallbackHook, callback *Callback, err error) {
+	callback.Latency = time.Since(callback.StartTime)
+	for _, hook := range hooks {
+		if hook.OnFinish != nil {
+			hook.OnFinish(ctx, callback, err)
+		}
+	}
+}
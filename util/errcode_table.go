@@ -0,0 +1,145 @@
+// Code generated by gen_errcode.go; DO NOT EDIT.
+
+package util
+
+// errCodeDescriptions 错误码与说明的对应关系
+var errCodeDescriptions = map[int64]string{
+	-1:      "系统繁忙，此时请开发者稍候再试",
+	0:       "请求成功",
+	40001:   "获取 access_token 时 AppSecret 错误，或者 access_token 无效",
+	40002:   "不合法的凭证类型",
+	40003:   "不合法的 OpenID",
+	40004:   "不合法的媒体文件类型",
+	40005:   "不合法的文件类型",
+	40006:   "不合法的文件大小",
+	40007:   "不合法的媒体文件 id",
+	40008:   "不合法的消息类型",
+	40009:   "不合法的图片文件大小",
+	40010:   "不合法的语音文件大小",
+	40011:   "不合法的视频文件大小",
+	40012:   "不合法的缩略图文件大小",
+	40013:   "不合法的 AppID",
+	40014:   "不合法的 access_token",
+	40015:   "不合法的菜单类型",
+	40016:   "不合法的按钮个数",
+	40017:   "不合法的按钮类型",
+	40018:   "不合法的按钮名字长度",
+	40019:   "不合法的按钮 KEY 长度",
+	40020:   "不合法的按钮 URL 长度",
+	40023:   "不合法的子菜单按钮个数",
+	40024:   "不合法的子菜单按钮类型",
+	40025:   "不合法的子菜单按钮名字长度",
+	40026:   "不合法的子菜单按钮 KEY 长度",
+	40027:   "不合法的子菜单按钮 URL 长度",
+	40029:   "无效的 oauth_code",
+	40030:   "不合法的 refresh_token",
+	40031:   "不合法的 openid 列表",
+	40032:   "不合法的 openid 列表长度",
+	40033:   "不合法的请求字符，不能包含 \\uxxxx 格式的字符",
+	40035:   "不合法的参数",
+	40037:   "不合法的模板id",
+	40038:   "不合法的请求格式",
+	40039:   "不合法的 URL 长度",
+	40048:   "无效的url",
+	40050:   "不合法的分组 id",
+	40051:   "分组名字不合法",
+	40054:   "不合法的子菜单按钮 url 域名",
+	40055:   "不合法的菜单按钮 url 域名",
+	40060:   "删除单篇图文时，指定的 article_idx 不合法",
+	40066:   "不合法的 url",
+	40097:   "参数错误",
+	40117:   "分组名字不合法",
+	40118:   "media_id 大小不合法",
+	40119:   "button 类型错误",
+	40120:   "子 button 类型错误",
+	40121:   "不合法的 media_id 类型",
+	40125:   "无效的appsecret",
+	40132:   "微信号不合法",
+	40137:   "不支持的图片格式",
+	40155:   "请勿添加其他公众号的主页链接",
+	40163:   "oauth_code已使用",
+	40164:   "调用接口的IP地址不在白名单中",
+	40226:   "高风险等级用户，小程序登录拦截",
+	40243:   "AppSecret已被冻结，请登录后台解冻后再次调用",
+	41001:   "缺少 access_token 参数",
+	41002:   "缺少 appid 参数",
+	41003:   "缺少 refresh_token 参数",
+	41004:   "缺少 secret 参数",
+	41005:   "缺少多媒体文件数据",
+	41006:   "缺少 media_id 参数",
+	41007:   "缺少子菜单数据",
+	41008:   "缺少 oauth code",
+	41009:   "缺少 openid",
+	42001:   "access_token 超时，请检查 access_token 的有效期",
+	42002:   "refresh_token 超时",
+	42003:   "oauth_code 超时",
+	42007:   "用户修改微信密码，access_token 和 refresh_token 失效，需要重新授权",
+	43001:   "需要 GET 请求",
+	43002:   "需要 POST 请求",
+	43003:   "需要 HTTPS 请求",
+	43004:   "需要接收者关注",
+	43005:   "需要好友关系",
+	43019:   "需要将接收者从黑名单中移除",
+	44001:   "多媒体文件为空",
+	44002:   "POST 的数据包为空",
+	44003:   "图文消息内容为空",
+	44004:   "文本消息内容为空",
+	45001:   "多媒体文件大小超过限制",
+	45002:   "消息内容超过限制",
+	45003:   "标题字段超过限制",
+	45004:   "描述字段超过限制",
+	45005:   "链接字段超过限制",
+	45006:   "图片链接字段超过限制",
+	45007:   "语音播放时间超过限制",
+	45008:   "图文消息超过限制",
+	45009:   "接口调用超过限制",
+	45010:   "创建菜单个数超过限制",
+	45011:   "API 调用太频繁，请稍候再试",
+	45015:   "回复时间超过限制",
+	45016:   "系统分组，不允许修改",
+	45017:   "分组名字过长",
+	45018:   "分组数量超过上限",
+	45047:   "客服接口下行条数超过上限",
+	46001:   "不存在媒体数据",
+	46002:   "不存在的菜单版本",
+	46003:   "不存在的菜单数据",
+	46004:   "不存在的用户",
+	47001:   "解析 JSON/XML 内容错误",
+	48001:   "api 功能未授权，请确认公众号/小程序已获得该接口",
+	48002:   "粉丝拒收消息",
+	48004:   "api 接口被封禁",
+	48005:   "api 禁止删除被自动回复和自定义菜单引用的素材",
+	48006:   "api 禁止清零调用次数，因为清零次数达到上限",
+	48008:   "没有该类型消息的发送权限",
+	50001:   "用户未授权该 api",
+	50002:   "用户受限，可能是违规后接口被封禁",
+	50005:   "用户未关注公众号",
+	61023:   "refresh_token 已失效",
+	61024:   "第三方平台 component_access_token 相关参数错误",
+	65400:   "API不可用，即没有开通/升级到新版客服功能",
+	65401:   "无效客服帐号",
+	65403:   "客服昵称不合法",
+	65404:   "客服帐号不合法",
+	65405:   "帐号数目已达到上限，不能继续添加",
+	65406:   "已经存在的客服帐号",
+	85001:   "微信号不存在或微信号设置为不可搜索",
+	85002:   "小程序绑定的体验者数量达到上限",
+	85003:   "微信号绑定的小程序体验者达到上限",
+	85004:   "微信号已经绑定",
+	87009:   "无效的签名",
+	87014:   "内容含有违法违规内容",
+	89503:   "此IP调用需要管理员确认",
+	89506:   "24小时内该IP被管理员拒绝调用两次，24小时内不可再使用该IP调用",
+	89507:   "1小时内该IP被管理员拒绝调用一次，1小时内不可再使用该IP调用",
+	9001001: "POST 数据参数不合法",
+	9001002: "远端服务不可用",
+	9001003: "Ticket 不合法",
+	9001004: "获取摇周边用户信息失败",
+	9001005: "获取商户信息失败",
+	9001006: "获取 OpenID 失败",
+}
+
+// ErrCodeDescription 获取错误码对应的说明，未收录时返回空字符串
+func ErrCodeDescription(errCode int64) string {
+	return errCodeDescriptions[errCode]
+}
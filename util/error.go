@@ -2,16 +2,162 @@ package util
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 )
 
+//go:generate go run gen_errcode.go
+
 // CommonError 微信返回的通用错误json
 type CommonError struct {
 	ErrCode int64  `json:"errcode"`
 	ErrMsg  string `json:"errmsg"`
 }
 
+// Err 将CommonError转换为*Error，errcode为0时返回nil
+func (commonError CommonError) Err(apiName string) error {
+	if commonError.ErrCode == 0 {
+		return nil
+	}
+	return NewError(apiName, commonError.ErrCode, commonError.ErrMsg)
+}
+
+// 常用的错误码
+const (
+	ErrCodeSystemBusy          int64 = -1    // 系统繁忙
+	ErrCodeInvalidCredential   int64 = 40001 // access_token无效或不是最新的
+	ErrCodeInvalidAccessToken  int64 = 40014 // 不合法的access_token
+	ErrCodeAccessTokenExpired  int64 = 42001 // access_token超时
+	ErrCodeAPIFreqOutOfLimit   int64 = 45009 // 接口调用超过限制
+	ErrCodeAPIMinuteOutOfLimit int64 = 45011 // API调用太频繁，请稍候再试
+)
+
+// Error 微信接口返回的错误，可以通过 errors.As 获取
+type Error struct {
+	APIName string // 接口名称
+	ErrCode int64  // 错误码
+	ErrMsg  string // 错误信息
+	Rid     string // 请求id，从errmsg中解析，可用于在微信后台排查问题
+}
+
+// NewError 根据接口返回的errcode及errmsg创建*Error
+func NewError(apiName string, errCode int64, errMsg string) *Error {
+	return &Error{
+		APIName: apiName,
+		ErrCode: errCode,
+		ErrMsg:  errMsg,
+		Rid:     parseRid(errMsg),
+	}
+}
+
+// Error 保持与之前版本一致的错误描述
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s Error , errcode=%d , errmsg=%s", e.APIName, e.ErrCode, e.ErrMsg)
+}
+
+// Description 错误码对应的中文说明，未收录时返回空字符串
+func (e *Error) Description() string {
+	return ErrCodeDescription(e.ErrCode)
+}
+
+// IsAccessTokenInvalid access_token是否已失效，需要重新获取
+func (e *Error) IsAccessTokenInvalid() bool {
+	switch e.ErrCode {
+	case ErrCodeInvalidCredential, ErrCodeInvalidAccessToken, ErrCodeAccessTokenExpired:
+		return true
+	}
+	return false
+}
+
+// IsRateLimited 是否触发了接口调用频率或次数限制
+func (e *Error) IsRateLimited() bool {
+	switch e.ErrCode {
+	case ErrCodeAPIFreqOutOfLimit, ErrCodeAPIMinuteOutOfLimit:
+		return true
+	}
+	return false
+}
+
+// IsSystemBusy 微信服务器是否繁忙，稍后可重试
+func (e *Error) IsSystemBusy() bool {
+	return e.ErrCode == ErrCodeSystemBusy
+}
+
+// parseRid 从errmsg中解析rid，格式如 "invalid credential rid: 5f0c0b6c-1a2b3c4d-5e6f7a8b"
+func parseRid(errMsg string) string {
+	idx := strings.LastIndex(errMsg, "rid:")
+	if idx < 0 {
+		return ""
+	}
+	rid := strings.TrimSpace(errMsg[idx+len("rid:"):])
+	if i := strings.IndexAny(rid, " ,;"); i >= 0 {
+		rid = rid[:i]
+	}
+	return rid
+}
+
+// PayError 微信支付接口返回的错误，return_code或result_code不为SUCCESS时返回
+type PayError struct {
+	APIName    string // 接口名称
+	ReturnCode string // 通信标识
+	ReturnMsg  string // 通信错误信息
+	ErrCode    string // 业务错误码
+	ErrCodeDes string // 业务错误描述
+}
+
+// Error 错误描述
+func (e *PayError) Error() string {
+	if e.ErrCode == "" {
+		return fmt.Sprintf("%s Error , return_code=%s , return_msg=%s", e.APIName, e.ReturnCode, e.ReturnMsg)
+	}
+	return fmt.Sprintf("%s Error , err_code=%s , err_code_des=%s", e.APIName, e.ErrCode, e.ErrCodeDes)
+}
+
+// IsRateLimited 是否触发了频率限制
+func (e *PayError) IsRateLimited() bool {
+	return e.ErrCode == "FREQUENCY_LIMITED" || e.ErrCode == "FREQ_LIMIT"
+}
+
+// IsSystemBusy 微信支付系统是否繁忙，稍后可使用相同参数重试
+func (e *PayError) IsSystemBusy() bool {
+	return e.ErrCode == "SYSTEMERROR" || e.ErrCode == "SYSTEM_ERROR" || e.ErrCode == "BIZERR_NEED_RETRY"
+}
+
+// AsError 从err中获取*Error
+func AsError(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// IsAccessTokenInvalid 判断err是否为access_token失效（40001、40014、42001）
+func IsAccessTokenInvalid(err error) bool {
+	e, ok := AsError(err)
+	return ok && e.IsAccessTokenInvalid()
+}
+
+// IsRateLimited 判断err是否为接口调用频率或次数超限
+func IsRateLimited(err error) bool {
+	if e, ok := AsError(err); ok {
+		return e.IsRateLimited()
+	}
+	var payErr *PayError
+	return errors.As(err, &payErr) && payErr.IsRateLimited()
+}
+
+// IsSystemBusy 判断err是否为系统繁忙
+func IsSystemBusy(err error) bool {
+	if e, ok := AsError(err); ok {
+		return e.IsSystemBusy()
+	}
+	var payErr *PayError
+	return errors.As(err, &payErr) && payErr.IsSystemBusy()
+}
+
 // DecodeWithCommonError 将返回值按照CommonError解析
 func DecodeWithCommonError(response []byte, apiName string) (err error) {
 	var commError CommonError
@@ -19,10 +165,7 @@ func DecodeWithCommonError(response []byte, apiName string) (err error) {
 	if err != nil {
 		return
 	}
-	if commError.ErrCode != 0 {
-		return fmt.Errorf("%s Error , errcode=%d , errmsg=%s", apiName, commError.ErrCode, commError.ErrMsg)
-	}
-	return nil
+	return commError.Err(apiName)
 }
 
 // DecodeWithError 将返回值按照解析
@@ -45,7 +188,7 @@ func DecodeWithError(response []byte, obj interface{}, apiName string) error {
 		return fmt.Errorf("errcode or errmsg is invalid")
 	}
 	if errCode.Int() != 0 {
-		return fmt.Errorf("%s Error , errcode=%d , errmsg=%s", apiName, errCode.Int(), errMsg.String())
+		return NewError(apiName, errCode.Int(), errMsg.String())
 	}
 	return nil
 }
@@ -0,0 +1,45 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeWithError(t *testing.T) {
+	var res struct {
+		CommonError
+	}
+	err := DecodeWithError([]byte(`{"errcode":40001,"errmsg":"invalid credential, access_token is invalid or not latest rid: 5f0c0b6c-1a2b3c4d-5e6f7a8b"}`), &res, "GetMenu")
+	assert.NotNil(t, err)
+	assert.Equal(t, "GetMenu Error , errcode=40001 , errmsg=invalid credential, access_token is invalid or not latest rid: 5f0c0b6c-1a2b3c4d-5e6f7a8b", err.Error())
+
+	wrapped := fmt.Errorf("wrapped: %w", err)
+	e, ok := AsError(wrapped)
+	assert.True(t, ok)
+	assert.Equal(t, "GetMenu", e.APIName)
+	assert.Equal(t, int64(40001), e.ErrCode)
+	assert.Equal(t, "5f0c0b6c-1a2b3c4d-5e6f7a8b", e.Rid)
+	assert.NotEmpty(t, e.Description())
+	assert.True(t, IsAccessTokenInvalid(wrapped))
+	assert.False(t, IsRateLimited(wrapped))
+	assert.False(t, IsSystemBusy(wrapped))
+
+	assert.Nil(t, DecodeWithCommonError([]byte(`{"errcode":0,"errmsg":"ok"}`), "GetMenu"))
+	err = DecodeWithCommonError([]byte(`{"errcode":45009,"errmsg":"reach max api daily quota limit"}`), "SendTemplate")
+	assert.True(t, IsRateLimited(err))
+	assert.Empty(t, NewError("SendTemplate", 45009, "").Rid)
+}
+
+func TestPayError(t *testing.T) {
+	err := error(&PayError{APIName: "Refund", ReturnCode: "SUCCESS", ErrCode: "SYSTEMERROR", ErrCodeDes: "系统超时"})
+	assert.True(t, IsSystemBusy(err))
+	assert.False(t, IsAccessTokenInvalid(err))
+	assert.Equal(t, "Refund Error , err_code=SYSTEMERROR , err_code_des=系统超时", err.Error())
+
+	var payErr *PayError
+	assert.True(t, errors.As(err, &payErr))
+	assert.True(t, IsRateLimited(&PayError{ErrCode: "FREQUENCY_LIMITED"}))
+}
@@ -0,0 +1,334 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// DefaultAPIDomains 微信公布的API通用域名及容灾域名，按优先级排列
+// https://developers.weixin.qq.com/doc/offiaccount/Basic_Information/Interface_field_description.html
+var DefaultAPIDomains = []string{
+	"https://api.weixin.qq.com",
+	"https://api2.weixin.qq.com",
+	"https://sh.api.weixin.qq.com",
+	"https://sz.api.weixin.qq.com",
+	"https://hk.api.weixin.qq.com",
+}
+
+const (
+	defaultFailureThreshold = 3
+	defaultProbeInterval    = 30 * time.Second
+	defaultProbePath        = "/cgi-bin/getcallbackip"
+	defaultProbeTimeout     = 5 * time.Second
+)
+
+// FailoverOpts 域名容灾配置
+type FailoverOpts struct {
+	Domains          []string      // 按优先级排列的域名，如 https://api.weixin.qq.com，为空时使用 DefaultAPIDomains
+	Client           Doer          // 实际发送请求的客户端，为空时使用 DefaultHTTPClient
+	FailureThreshold int           // 连续失败多少次后标记为不可用，默认3
+	ProbeInterval    time.Duration // 探测不可用域名是否恢复的间隔，默认30秒
+	ProbePath        string        // 探测请求的路径，默认 /cgi-bin/getcallbackip，返回非5xx状态码即视为恢复
+}
+
+// DomainState 域名的当前状态
+type DomainState struct {
+	Domain     string    // 域名，如 https://api.weixin.qq.com
+	Healthy    bool      // 是否可用
+	Failures   int       // 连续失败次数
+	LastError  string    // 最近一次失败的原因
+	LastChange time.Time // 最近一次可用状态变更的时间
+}
+
+// Failover 在多个域名间自动切换的HTTP客户端，实现了 Doer
+// 请求发往 Domains 中的域名时，若该域名连续出现 FailureThreshold 次连接错误或返回5xx，会被标记为不可用，
+// 之后的请求改为发往其后第一个可用的域名；存在不可用的域名时，每隔 ProbeInterval 在后台探测一次，恢复后重新使用
+// 当前请求失败时不会在本次调用内切换域名重试
+//
+// 公众号及小程序在配置中启用 EnableFailover 时按 Server 与 BackupServers 创建，见 NewAPIFailover，也可以通过配置中的 Failover 在多个账号间共享
+type Failover struct {
+	client        Doer
+	threshold     int
+	probeInterval time.Duration
+	probePath     string
+
+	mu        sync.RWMutex
+	domains   []*DomainState
+	hosts     map[string]int
+	probing   bool
+	lastProbe time.Time
+}
+
+// NewFailover 创建域名容灾客户端，opts为nil时使用默认配置
+func NewFailover(opts *FailoverOpts) *Failover {
+	if opts == nil {
+		opts = &FailoverOpts{}
+	}
+	domains := opts.Domains
+	if len(domains) == 0 {
+		domains = DefaultAPIDomains
+	}
+	f := &Failover{
+		client:        opts.Client,
+		threshold:     opts.FailureThreshold,
+		probeInterval: opts.ProbeInterval,
+		probePath:     opts.ProbePath,
+		hosts:         make(map[string]int, len(domains)),
+	}
+	if f.threshold <= 0 {
+		f.threshold = defaultFailureThreshold
+	}
+	if f.probeInterval <= 0 {
+		f.probeInterval = defaultProbeInterval
+	}
+	if f.probePath == "" {
+		f.probePath = defaultProbePath
+	}
+	for _, domain := range domains {
+		u, err := url.Parse(domain)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		domain = u.Scheme + "://" + u.Host
+		if _, ok := f.hosts[u.Host]; ok {
+			continue
+		}
+		f.hosts[u.Host] = len(f.domains)
+		f.domains = append(f.domains, &DomainState{Domain: domain, Healthy: true})
+	}
+	return f
+}
+
+// NewAPIFailover 创建在 server 与 backupServers 间切换的域名容灾客户端，client为空时使用 DefaultHTTPClient
+// backupServers 为空且 server 为 DefaultAPIDomains 中的域名时，使用 DefaultAPIDomains 中的其他域名；没有可切换的域名时返回nil
+func NewAPIFailover(server string, backupServers []string, client Doer) *Failover {
+	domains := append([]string{server}, backupServers...)
+	if len(backupServers) == 0 {
+		for i, domain := range DefaultAPIDomains {
+			if domain == server {
+				domains = append(domains, DefaultAPIDomains[:i]...)
+				domains = append(domains, DefaultAPIDomains[i+1:]...)
+				break
+			}
+		}
+	}
+	f := NewFailover(&FailoverOpts{Domains: domains, Client: client})
+	if len(f.domains) < 2 {
+		return nil
+	}
+	return f
+}
+
+// Do 发送请求，请求的域名不可用时改写为当前可用的域名
+func (f *Failover) Do(req *http.Request) (*http.Response, error) {
+	idx, ok := f.index(req.URL.Host)
+	if !ok {
+		return f.doer().Do(req)
+	}
+	f.probeInBackground()
+	target := f.pick(idx)
+	if target != idx {
+		u, _ := url.Parse(f.domain(target))
+		req = req.Clone(req.Context())
+		req.URL.Scheme = u.Scheme
+		req.URL.Host = u.Host
+		req.Host = ""
+	}
+
+	resp, err := f.doer().Do(req)
+	switch {
+	case err != nil:
+		// 调用方取消或超时不代表域名不可用
+		if req.Context().Err() == nil {
+			f.markFailure(target, err)
+		}
+	case resp.StatusCode >= http.StatusInternalServerError:
+		f.markFailure(target, fmt.Errorf("statusCode=%d", resp.StatusCode))
+	default:
+		f.markSuccess(target)
+	}
+	return resp, err
+}
+
+// Route 返回uri当前实际会发往的地址，uri的域名不在 Domains 中时原样返回
+func (f *Failover) Route(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	idx, ok := f.index(u.Host)
+	if !ok {
+		return uri
+	}
+	target, _ := url.Parse(f.domain(f.pick(idx)))
+	u.Scheme = target.Scheme
+	u.Host = target.Host
+	return u.String()
+}
+
+// State 返回所有域名的当前状态，按优先级排列
+func (f *Failover) State() []DomainState {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	states := make([]DomainState, len(f.domains))
+	for i, d := range f.domains {
+		states[i] = *d
+	}
+	return states
+}
+
+// Start 在后台探测不可用的域名，ctx取消后停止
+func (f *Failover) Start(ctx context.Context) {
+	go f.Run(ctx)
+}
+
+// Run 定期探测不可用的域名，直到ctx取消
+func (f *Failover) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.probeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			f.Probe(ctx)
+		}
+	}
+}
+
+// Probe 立即探测一次所有不可用的域名
+func (f *Failover) Probe(ctx context.Context) {
+	f.mu.Lock()
+	f.lastProbe = time.Now()
+	f.mu.Unlock()
+	for i, state := range f.State() {
+		if state.Healthy {
+			continue
+		}
+		if err := f.probe(ctx, state.Domain); err != nil {
+			f.markFailure(i, err)
+			continue
+		}
+		f.markSuccess(i)
+	}
+}
+
+// probeInBackground 存在不可用的域名且距上次探测或标记为不可用超过 ProbeInterval 时，在后台探测一次
+// 未调用 Start 时也能在域名恢复后切换回来
+func (f *Failover) probeInBackground() {
+	f.mu.RLock()
+	due := f.probeDue()
+	f.mu.RUnlock()
+	if !due {
+		return
+	}
+	f.mu.Lock()
+	if !f.probeDue() {
+		f.mu.Unlock()
+		return
+	}
+	f.probing = true
+	f.mu.Unlock()
+	go func() {
+		defer func() {
+			f.mu.Lock()
+			f.probing = false
+			f.mu.Unlock()
+		}()
+		f.Probe(context.Background())
+	}()
+}
+
+// probeDue 是否需要在后台探测，调用方需持有锁
+func (f *Failover) probeDue() bool {
+	if f.probing || time.Since(f.lastProbe) < f.probeInterval {
+		return false
+	}
+	for _, d := range f.domains {
+		if !d.Healthy {
+			return true
+		}
+	}
+	return false
+}
+
+// probe 向域名发送探测请求，返回非5xx状态码即视为可用
+func (f *Failover) probe(ctx context.Context, domain string) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultProbeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, domain+f.probePath, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := f.doer().Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("statusCode=%d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (f *Failover) doer() Doer {
+	if f.client != nil {
+		return f.client
+	}
+	return DefaultHTTPClient
+}
+
+func (f *Failover) index(host string) (int, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	idx, ok := f.hosts[host]
+	return idx, ok
+}
+
+func (f *Failover) domain(idx int) string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.domains[idx].Domain
+}
+
+// pick 从idx开始按优先级顺序选择第一个可用的域名，均不可用时仍使用idx
+func (f *Failover) pick(idx int) int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for i := 0; i < len(f.domains); i++ {
+		j := (idx + i) % len(f.domains)
+		if f.domains[j].Healthy {
+			return j
+		}
+	}
+	return idx
+}
+
+func (f *Failover) markFailure(idx int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	d := f.domains[idx]
+	d.Failures++
+	d.LastError = err.Error()
+	if d.Healthy && d.Failures >= f.threshold {
+		d.Healthy = false
+		d.LastChange = time.Now()
+		// 刚标记为不可用时不立即探测
+		f.lastProbe = d.LastChange
+	}
+}
+
+func (f *Failover) markSuccess(idx int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	d := f.domains[idx]
+	d.Failures = 0
+	if !d.Healthy {
+		d.Healthy = true
+		d.LastChange = time.Now()
+	}
+}
@@ -0,0 +1,106 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailover(t *testing.T) {
+	var mu sync.Mutex
+	down := map[string]bool{"api.weixin.qq.com": true}
+	var hosts []string
+	client := doerFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		hosts = append(hosts, req.URL.Host)
+		if down[req.URL.Host] {
+			return nil, errors.New("connection refused")
+		}
+		return newResponse(http.StatusOK, `{"errcode":0}`), nil
+	})
+	assert.Equal(t, defaultFailureThreshold, NewFailover(nil).threshold)
+	failover := NewFailover(&FailoverOpts{Client: client, FailureThreshold: 1})
+	ctx := WithHTTPClient(context.Background(), failover)
+
+	// 失败后标记为不可用，之后的请求发往下一个域名
+	_, err := HTTPGetContext(ctx, "https://api.weixin.qq.com/cgi-bin/getcallbackip?access_token=a")
+	assert.NotNil(t, err)
+	assert.False(t, failover.State()[0].Healthy)
+	assert.Equal(t, "https://api2.weixin.qq.com/cgi-bin/getcallbackip?access_token=a", failover.Route("https://api.weixin.qq.com/cgi-bin/getcallbackip?access_token=a"))
+
+	_, err = HTTPGetContext(ctx, "https://api.weixin.qq.com/cgi-bin/getcallbackip?access_token=a")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"api.weixin.qq.com", "api2.weixin.qq.com"}, hosts)
+
+	// 不在容灾列表中的域名不改写
+	assert.Equal(t, "https://api.mch.weixin.qq.com/pay/orderquery", failover.Route("https://api.mch.weixin.qq.com/pay/orderquery"))
+
+	// 探测到恢复后重新使用
+	failover.Probe(context.Background())
+	assert.False(t, failover.State()[0].Healthy)
+	mu.Lock()
+	down["api.weixin.qq.com"] = false
+	mu.Unlock()
+	failover.Probe(context.Background())
+	assert.True(t, failover.State()[0].Healthy)
+	assert.Equal(t, "https://api.weixin.qq.com/cgi-bin/getcallbackip", failover.Route("https://api.weixin.qq.com/cgi-bin/getcallbackip"))
+}
+
+func TestFailoverServerError(t *testing.T) {
+	client := doerFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusBadGateway, ""), nil
+	})
+	failover := NewFailover(&FailoverOpts{
+		Domains:          []string{"https://api.weixin.qq.com", "https://api2.weixin.qq.com"},
+		Client:           client,
+		FailureThreshold: 2,
+	})
+	_, err := HTTPGetContext(WithHTTPClient(context.Background(), failover), "https://api.weixin.qq.com/cgi-bin/token")
+	assert.NotNil(t, err)
+	state := failover.State()
+	assert.True(t, state[0].Healthy)
+	assert.Equal(t, 1, state[0].Failures)
+
+	_, _ = HTTPGetContext(WithHTTPClient(context.Background(), failover), "https://api.weixin.qq.com/cgi-bin/token")
+	assert.False(t, failover.State()[0].Healthy)
+}
+
+func TestFailoverProbeInBackground(t *testing.T) {
+	var mu sync.Mutex
+	down := true
+	client := doerFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if down && req.URL.Host == "api.weixin.qq.com" {
+			return nil, errors.New("connection refused")
+		}
+		return newResponse(http.StatusOK, `{"errcode":0}`), nil
+	})
+	failover := NewAPIFailover("https://api.weixin.qq.com", nil, client)
+	failover.threshold = 1
+	failover.probeInterval = 10 * time.Millisecond
+	ctx := WithHTTPClient(context.Background(), failover)
+
+	_, err := HTTPGetContext(ctx, "https://api.weixin.qq.com/cgi-bin/getcallbackip")
+	assert.NotNil(t, err)
+	mu.Lock()
+	down = false
+	mu.Unlock()
+
+	// 未调用 Start 时，超过探测间隔后的请求触发后台探测
+	time.Sleep(20 * time.Millisecond)
+	_, err = HTTPGetContext(ctx, "https://api.weixin.qq.com/cgi-bin/getcallbackip")
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool {
+		return failover.State()[0].Healthy
+	}, time.Second, 5*time.Millisecond)
+
+	assert.Nil(t, NewAPIFailover("http://127.0.0.1:8080", nil, client))
+	assert.Len(t, NewAPIFailover("https://api2.weixin.qq.com", nil, client).State(), len(DefaultAPIDomains))
+}
@@ -0,0 +1,64 @@
+//go:build ignore
+// +build ignore
+
+// gen_errcode.go 根据 errcode.txt 生成 errcode_table.go
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	f, err := os.Open("errcode.txt")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen_errcode.go; DO NOT EDIT.\n\n")
+	buf.WriteString("package util\n\n")
+	buf.WriteString("// errCodeDescriptions 错误码与说明的对应关系\n")
+	buf.WriteString("var errCodeDescriptions = map[int64]string{\n")
+
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.SplitN(text, "\t", 2)
+		if len(fields) != 2 {
+			log.Fatalf("errcode.txt:%d: want <code>\\t<description>", line)
+		}
+		code, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			log.Fatalf("errcode.txt:%d: %v", line, err)
+		}
+		fmt.Fprintf(&buf, "\t%d: %q,\n", code, strings.TrimSpace(fields[1]))
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+	buf.WriteString("}\n\n")
+	buf.WriteString("// ErrCodeDescription 获取错误码对应的说明，未收录时返回空字符串\n")
+	buf.WriteString("func ErrCodeDescription(errCode int64) string {\n\treturn errCodeDescriptions[errCode]\n}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile("errcode_table.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
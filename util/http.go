@@ -10,35 +10,141 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 
 	"golang.org/x/crypto/pkcs12"
 )
 
-// HTTPGet get 请求
-func HTTPGet(uri string) ([]byte, error) {
-	return HTTPGetContext(context.Background(), uri)
+// Doer 发送HTTP请求的接口，*http.Client 即实现了该接口
+// 可以通过自定义实现来设置超时、代理、连接池或加入链路追踪等
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
 }
 
-// HTTPGetContext get 请求
-func HTTPGetContext(ctx context.Context, uri string) ([]byte, error) {
-	request, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+// DefaultHTTPClient 未单独设置时所有请求使用的HTTP客户端
+var DefaultHTTPClient Doer = http.DefaultClient
+
+// SetDefaultHTTPClient 设置全局默认的HTTP客户端
+func SetDefaultHTTPClient(client Doer) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	DefaultHTTPClient = client
+}
+
+type httpClientKey struct{}
+
+// WithHTTPClient 返回携带HTTP客户端的context，使用该context发出的请求将使用此客户端
+func WithHTTPClient(ctx context.Context, client Doer) context.Context {
+	return context.WithValue(ctx, httpClientKey{}, client)
+}
+
+// HTTPClientFromContext 获取context中携带的HTTP客户端
+func HTTPClientFromContext(ctx context.Context) (Doer, bool) {
+	client, ok := ctx.Value(httpClientKey{}).(Doer)
+	return client, ok && client != nil
+}
+
+// httpClient 获取本次请求使用的HTTP客户端，context中未设置时使用 DefaultHTTPClient
+func httpClient(ctx context.Context) Doer {
+	if client, ok := HTTPClientFromContext(ctx); ok {
+		return client
+	}
+	return DefaultHTTPClient
+}
+
+// httpDo 发送请求并读取返回内容，非200的状态码视为错误
+// context中携带 RetryPolicy 时按策略重试，携带TokenRefresher且返回access_token失效时，刷新后重试一次
+// context中携带 Limiter 时在首次发送前等待，重试及刷新access_token后的重发不再重复计数
+func httpDo(ctx context.Context, method, uri, contentType string, body []byte) ([]byte, http.Header, error) {
+	if err := waitLimiter(ctx, uri); err != nil {
+		return nil, nil, err
+	}
+	policy, _ := RetryPolicyFromContext(ctx)
+	idempotent := isIdempotent(ctx, method)
+	for attempt := 1; ; attempt++ {
+		response, header, err := httpDoOnce(ctx, method, uri, contentType, body)
+		if !policy.retryable(ctx, attempt, idempotent, response, err) {
+			return response, header, err
+		}
+		if sleepErr := sleepContext(ctx, policy.backoff(attempt)); sleepErr != nil {
+			return response, header, err
+		}
+	}
+}
+
+// waitLimiter 等待context中携带的 Limiter 允许调用uri对应的接口
+func waitLimiter(ctx context.Context, uri string) error {
+	limiter, _ := LimiterFromContext(ctx)
+	if limiter == nil {
+		return nil
+	}
+	u, err := url.Parse(uri)
 	if err != nil {
-		return nil, err
+		// 无法解析的地址在发送时返回错误
+		return nil
 	}
-	response, err := http.DefaultClient.Do(request)
+	return limiter.Wait(ctx, u.Path)
+}
+
+// httpDoOnce 发送请求，返回access_token失效时刷新后重试一次
+func httpDoOnce(ctx context.Context, method, uri, contentType string, body []byte) ([]byte, http.Header, error) {
+	response, header, err := httpSend(ctx, method, uri, contentType, body)
 	if err != nil {
-		return nil, err
+		return response, header, err
+	}
+	if retryCtx, retryURI, ok := refreshTokenURI(ctx, uri, response); ok {
+		return httpSend(retryCtx, method, retryURI, contentType, body)
 	}
+	return response, header, nil
+}
 
+// httpSend 发送一次请求并读取返回内容
+func httpSend(ctx context.Context, method, uri, contentType string, body []byte) ([]byte, http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, uri, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	call := newCall(ctx, request)
+	if err = call.beforeRequest(ctx); err != nil {
+		return nil, nil, err
+	}
+	response, err := httpClient(ctx).Do(request)
+	if err != nil {
+		call.afterResponse(ctx, 0, nil, err)
+		return nil, nil, err
+	}
 	defer response.Body.Close()
+
 	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("http get error : uri=%v , statusCode=%v", uri, response.StatusCode)
+		err = &StatusError{Method: method, URI: uri, StatusCode: response.StatusCode}
+		call.afterResponse(ctx, response.StatusCode, nil, err)
+		return nil, response.Header, err
 	}
-	return ioutil.ReadAll(response.Body)
+	responseData, err := ioutil.ReadAll(response.Body)
+	call.afterResponse(ctx, response.StatusCode, responseData, err)
+	return responseData, response.Header, err
+}
+
+// HTTPGet get 请求
+func HTTPGet(uri string) ([]byte, error) {
+	return HTTPGetContext(context.Background(), uri)
+}
+
+// HTTPGetContext get 请求
+func HTTPGetContext(ctx context.Context, uri string) ([]byte, error) {
+	response, _, err := httpDo(ctx, http.MethodGet, uri, "", nil)
+	return response, err
 }
 
 // HTTPPost post 请求
@@ -48,70 +154,61 @@ func HTTPPost(uri string, data string) ([]byte, error) {
 
 // HTTPPostContext post 请求
 func HTTPPostContext(ctx context.Context, uri string, data string) ([]byte, error) {
-	body := bytes.NewBuffer([]byte(data))
-	request, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, body)
-	if err != nil {
-		return nil, err
-	}
-	response, err := http.DefaultClient.Do(request)
-	if err != nil {
-		return nil, err
-	}
-
-	defer response.Body.Close()
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("http get error : uri=%v , statusCode=%v", uri, response.StatusCode)
-	}
-	return ioutil.ReadAll(response.Body)
+	response, _, err := httpDo(ctx, http.MethodPost, uri, "", []byte(data))
+	return response, err
 }
 
-// PostJSON post json 数据请求
-func PostJSON(uri string, obj interface{}) ([]byte, error) {
+// encodeJSON 序列化json，不转义html字符
+func encodeJSON(obj interface{}) ([]byte, error) {
 	jsonBuf := new(bytes.Buffer)
 	enc := json.NewEncoder(jsonBuf)
 	enc.SetEscapeHTML(false)
-	err := enc.Encode(obj)
-	if err != nil {
+	if err := enc.Encode(obj); err != nil {
 		return nil, err
 	}
-	response, err := http.Post(uri, "application/json;charset=utf-8", jsonBuf)
+	return jsonBuf.Bytes(), nil
+}
+
+// PostJSON post json 数据请求
+func PostJSON(uri string, obj interface{}) ([]byte, error) {
+	return PostJSONContext(context.Background(), uri, obj)
+}
+
+// PostJSONContext post json 数据请求
+func PostJSONContext(ctx context.Context, uri string, obj interface{}) ([]byte, error) {
+	jsonData, err := encodeJSON(obj)
 	if err != nil {
 		return nil, err
 	}
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("http get error : uri=%v , statusCode=%v", uri, response.StatusCode)
-	}
-	return ioutil.ReadAll(response.Body)
+	response, _, err := httpDo(ctx, http.MethodPost, uri, "application/json;charset=utf-8", jsonData)
+	return response, err
 }
 
 // PostJSONWithRespContentType post json数据请求，且返回数据类型
 func PostJSONWithRespContentType(uri string, obj interface{}) ([]byte, string, error) {
-	jsonBuf := new(bytes.Buffer)
-	enc := json.NewEncoder(jsonBuf)
-	enc.SetEscapeHTML(false)
-	err := enc.Encode(obj)
+	return PostJSONWithRespContentTypeContext(context.Background(), uri, obj)
+}
+
+// PostJSONWithRespContentTypeContext post json数据请求，且返回数据类型
+func PostJSONWithRespContentTypeContext(ctx context.Context, uri string, obj interface{}) ([]byte, string, error) {
+	jsonData, err := encodeJSON(obj)
 	if err != nil {
 		return nil, "", err
 	}
-
-	response, err := http.Post(uri, "application/json;charset=utf-8", jsonBuf)
+	response, header, err := httpDo(ctx, http.MethodPost, uri, "application/json;charset=utf-8", jsonData)
 	if err != nil {
 		return nil, "", err
 	}
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("http get error : uri=%v , statusCode=%v", uri, response.StatusCode)
-	}
-	responseData, err := ioutil.ReadAll(response.Body)
-	contentType := response.Header.Get("Content-Type")
-	return responseData, contentType, err
+	return response, header.Get("Content-Type"), nil
 }
 
 // PostFile 上传文件
 func PostFile(fieldname, filename, uri string) ([]byte, error) {
+	return PostFileContext(context.Background(), fieldname, filename, uri)
+}
+
+// PostFileContext 上传文件
+func PostFileContext(ctx context.Context, fieldname, filename, uri string) ([]byte, error) {
 	fields := []MultipartFormField{
 		{
 			IsFile:    true,
@@ -119,7 +216,7 @@ func PostFile(fieldname, filename, uri string) ([]byte, error) {
 			Filename:  filename,
 		},
 	}
-	return PostMultipartForm(fields, uri)
+	return PostMultipartFormContext(ctx, fields, uri)
 }
 
 // MultipartFormField 保存文件或其他字段信息
@@ -132,6 +229,11 @@ type MultipartFormField struct {
 
 // PostMultipartForm 上传文件或其他多个字段
 func PostMultipartForm(fields []MultipartFormField, uri string) (respBody []byte, err error) {
+	return PostMultipartFormContext(context.Background(), fields, uri)
+}
+
+// PostMultipartFormContext 上传文件或其他多个字段
+func PostMultipartFormContext(ctx context.Context, fields []MultipartFormField, uri string) (respBody []byte, err error) {
 	bodyBuf := &bytes.Buffer{}
 	bodyWriter := multipart.NewWriter(bodyBuf)
 
@@ -169,55 +271,47 @@ func PostMultipartForm(fields []MultipartFormField, uri string) (respBody []byte
 	contentType := bodyWriter.FormDataContentType()
 	bodyWriter.Close()
 
-	resp, e := http.Post(uri, contentType, bodyBuf)
-	if e != nil {
-		err = e
-		return
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, err
-	}
-	respBody, err = ioutil.ReadAll(resp.Body)
+	respBody, _, err = httpDo(ctx, http.MethodPost, uri, contentType, bodyBuf.Bytes())
 	return
 }
 
 // PostXML perform a HTTP/POST request with XML body
 func PostXML(uri string, obj interface{}) ([]byte, error) {
-	xmlData, err := xml.Marshal(obj)
-	if err != nil {
-		return nil, err
-	}
+	return PostXMLContext(context.Background(), uri, obj)
+}
 
-	body := bytes.NewBuffer(xmlData)
-	response, err := http.Post(uri, "application/xml;charset=utf-8", body)
+// PostXMLContext perform a HTTP/POST request with XML body
+func PostXMLContext(ctx context.Context, uri string, obj interface{}) ([]byte, error) {
+	xmlData, err := xml.Marshal(obj)
 	if err != nil {
 		return nil, err
 	}
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("http code error : uri=%v , statusCode=%v", uri, response.StatusCode)
-	}
-	return ioutil.ReadAll(response.Body)
+	response, _, err := httpDo(ctx, http.MethodPost, uri, "application/xml;charset=utf-8", xmlData)
+	return response, err
 }
 
 // httpWithTLS CA证书
-func httpWithTLS(rootCa, key string) (*http.Client, error) {
-	var client *http.Client
+// base 为 *http.Client 时复用其超时、代理等设置，仅替换TLS证书
+func httpWithTLS(base Doer, rootCa, key string) (*http.Client, error) {
 	certData, err := ioutil.ReadFile(rootCa)
 	if err != nil {
 		return nil, fmt.Errorf("unable to find cert path=%s, error=%v", rootCa, err)
 	}
 	cert := pkcs12ToPem(certData, key)
-	config := &tls.Config{
-		Certificates: []tls.Certificate{cert},
+
+	client := &http.Client{}
+	tr := &http.Transport{DisableCompression: true}
+	if baseClient, ok := base.(*http.Client); ok {
+		*client = *baseClient
+		if baseTransport, ok := baseClient.Transport.(*http.Transport); ok {
+			tr = baseTransport.Clone()
+		}
 	}
-	tr := &http.Transport{
-		TLSClientConfig:    config,
-		DisableCompression: true,
+	if tr.TLSClientConfig == nil {
+		tr.TLSClientConfig = &tls.Config{}
 	}
-	client = &http.Client{Transport: tr}
+	tr.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	client.Transport = tr
 	return client, nil
 }
 
@@ -226,7 +320,7 @@ func pkcs12ToPem(p12 []byte, password string) tls.Certificate {
 	blocks, err := pkcs12.ToPEM(p12, password)
 	defer func() {
 		if x := recover(); x != nil {
-			log.Print(x)
+			DefaultLogger.Error("convert pkcs12 to pem failed", "error", x)
 		}
 	}()
 	if err != nil {
@@ -245,24 +339,14 @@ func pkcs12ToPem(p12 []byte, password string) tls.Certificate {
 
 // PostXMLWithTLS perform a HTTP/POST request with XML body and TLS
 func PostXMLWithTLS(uri string, obj interface{}, ca, key string) ([]byte, error) {
-	xmlData, err := xml.Marshal(obj)
-	if err != nil {
-		return nil, err
-	}
+	return PostXMLWithTLSContext(context.Background(), uri, obj, ca, key)
+}
 
-	body := bytes.NewBuffer(xmlData)
-	client, err := httpWithTLS(ca, key)
+// PostXMLWithTLSContext perform a HTTP/POST request with XML body and TLS
+func PostXMLWithTLSContext(ctx context.Context, uri string, obj interface{}, ca, key string) ([]byte, error) {
+	client, err := httpWithTLS(httpClient(ctx), ca, key)
 	if err != nil {
 		return nil, err
 	}
-	response, err := client.Post(uri, "application/xml;charset=utf-8", body)
-	if err != nil {
-		return nil, err
-	}
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("http code error : uri=%v , statusCode=%v", uri, response.StatusCode)
-	}
-	return ioutil.ReadAll(response.Body)
+	return PostXMLContext(WithHTTPClient(ctx, client), uri, obj)
 }
@@ -0,0 +1,111 @@
+package util
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (fn doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+func newResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	var gotContentType, gotBody string
+	client := doerFunc(func(req *http.Request) (*http.Response, error) {
+		gotContentType = req.Header.Get("Content-Type")
+		body, _ := ioutil.ReadAll(req.Body)
+		gotBody = string(body)
+		return newResponse(http.StatusOK, `{"errcode":0}`), nil
+	})
+
+	ctx := WithHTTPClient(context.Background(), client)
+	got, ok := HTTPClientFromContext(ctx)
+	assert.True(t, ok)
+	assert.NotNil(t, got)
+
+	response, contentType, err := PostJSONWithRespContentTypeContext(ctx, "http://example.com/api", map[string]string{"url": "a&b"})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"errcode":0}`, string(response))
+	assert.Equal(t, "application/json", contentType)
+	assert.Equal(t, "application/json;charset=utf-8", gotContentType)
+	assert.Equal(t, "{\"url\":\"a&b\"}\n", gotBody)
+}
+
+func TestSetDefaultHTTPClient(t *testing.T) {
+	defer SetDefaultHTTPClient(nil)
+
+	SetDefaultHTTPClient(doerFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusBadGateway, ""), nil
+	}))
+	_, err := HTTPGet("http://example.com/api")
+	assert.NotNil(t, err)
+
+	_, err = PostMultipartForm([]MultipartFormField{{Fieldname: "description", Value: []byte("{}")}}, "http://example.com/api")
+	assert.NotNil(t, err)
+}
+
+func TestHTTPGetContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := doerFunc(func(req *http.Request) (*http.Response, error) {
+		if err := req.Context().Err(); err != nil {
+			return nil, err
+		}
+		return newResponse(http.StatusOK, `{"errcode":0}`), nil
+	})
+	_, err := HTTPGetContext(WithHTTPClient(ctx, client), "http://example.com/api")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestHTTPDoRetryWithRefreshedToken(t *testing.T) {
+	var uris []string
+	client := doerFunc(func(req *http.Request) (*http.Response, error) {
+		uris = append(uris, req.URL.String())
+		body, _ := ioutil.ReadAll(req.Body)
+		assert.Equal(t, "{\"touser\":\"openid\"}\n", string(body))
+		if req.URL.Query().Get("access_token") == "stale" {
+			return newResponse(http.StatusOK, `{"errcode":40001,"errmsg":"invalid credential"}`), nil
+		}
+		return newResponse(http.StatusOK, `{"errcode":0,"errmsg":"ok"}`), nil
+	})
+
+	var refreshed []string
+	refresher := func(ctx context.Context, staleToken string) (string, error) {
+		refreshed = append(refreshed, staleToken)
+		_, ok := TokenRefresherFromContext(ctx)
+		assert.True(t, ok)
+		return "fresh", nil
+	}
+	ctx := WithTokenRefresher(WithHTTPClient(context.Background(), client), refresher)
+	response, err := PostJSONContext(ctx, "http://example.com/cgi-bin/message/custom/send?access_token=stale", map[string]string{"touser": "openid"})
+	assert.Nil(t, err)
+	assert.Nil(t, DecodeWithCommonError(response, "SendCustomMessage"))
+	assert.Equal(t, []string{"stale"}, refreshed)
+	assert.Equal(t, []string{
+		"http://example.com/cgi-bin/message/custom/send?access_token=stale",
+		"http://example.com/cgi-bin/message/custom/send?access_token=fresh",
+	}, uris)
+
+	// 未设置TokenRefresher时不重试
+	uris = nil
+	response, err = PostJSONContext(WithHTTPClient(context.Background(), client), "http://example.com/cgi-bin/message/custom/send?access_token=stale", map[string]string{"touser": "openid"})
+	assert.Nil(t, err)
+	assert.True(t, IsAccessTokenInvalid(DecodeWithCommonError(response, "SendCustomMessage")))
+	assert.Len(t, uris, 1)
+}
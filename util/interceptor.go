@@ -0,0 +1,226 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Call 一次接口调用的信息，重试及access_token失效后的重新请求均视为单独的调用
+type Call struct {
+	AppID       string        // 发起调用的账号，通过 WithAppID 设置
+	APIName     string        // 接口名称，通过 WithAPIName 设置，未设置时为接口路径
+	Method      string        // 请求方法
+	URL         string        // 请求地址，access_token等敏感参数已脱敏
+	Path        string        // 接口路径，如 /cgi-bin/message/template/send
+	StartTime   time.Time     // 开始时间
+	Header      http.Header   // 请求头，可在 BeforeRequest 中设置，如注入链路追踪的traceparent
+	Latency     time.Duration // 耗时，AfterResponse 及 OnError 中有效
+	StatusCode  int           // HTTP状态码，未收到返回时为0
+	CommonError *CommonError  // 返回内容为包含errcode的json时解析出的结果
+	Err         error         // 本次调用的错误，与传给 OnError 的相同，AfterResponse 及 OnError 中有效
+}
+
+// Interceptor 接口调用拦截器，各回调均可为空
+type Interceptor struct {
+	// BeforeRequest 发送请求前调用，返回错误时不再发送请求，此前已成功执行 BeforeRequest 的拦截器会收到 OnError
+	BeforeRequest func(ctx context.Context, call *Call) error
+	// AfterResponse 收到HTTP返回后调用，包括非200状态码及errcode不为0的情况，此时 Call.Err 不为空
+	AfterResponse func(ctx context.Context, call *Call)
+	// OnError 请求失败、返回非200状态码或errcode不为0时调用，err分别为网络错误、*StatusError、*Error
+	OnError func(ctx context.Context, call *Call, err error)
+}
+
+type interceptorsKey struct{}
+
+type apiNameKey struct{}
+
+type appIDKey struct{}
+
+// WithInterceptors 返回追加了拦截器的context，context中已有的拦截器先执行
+func WithInterceptors(ctx context.Context, interceptors ...Interceptor) context.Context {
+	if len(interceptors) == 0 {
+		return ctx
+	}
+	current := InterceptorsFromContext(ctx)
+	chain := make([]Interceptor, 0, len(current)+len(interceptors))
+	chain = append(chain, current...)
+	chain = append(chain, interceptors...)
+	return context.WithValue(ctx, interceptorsKey{}, chain)
+}
+
+// InterceptorsFromContext 获取context中携带的拦截器
+func InterceptorsFromContext(ctx context.Context) []Interceptor {
+	interceptors, _ := ctx.Value(interceptorsKey{}).([]Interceptor)
+	return interceptors
+}
+
+// WithAPIName 返回携带接口名称的context，拦截器中通过 Call.APIName 获取
+func WithAPIName(ctx context.Context, apiName string) context.Context {
+	return context.WithValue(ctx, apiNameKey{}, apiName)
+}
+
+// WithAppID 返回携带账号AppID的context，各账号的 RequestContext 会自动设置
+func WithAppID(ctx context.Context, appID string) context.Context {
+	return context.WithValue(ctx, appIDKey{}, appID)
+}
+
+// AppIDFromContext 获取context中携带的账号AppID
+func AppIDFromContext(ctx context.Context) string {
+	appID, _ := ctx.Value(appIDKey{}).(string)
+	return appID
+}
+
+// sensitiveParams 脱敏的请求参数
+var sensitiveParams = []string{
+	"access_token",
+	"component_access_token",
+	"authorizer_access_token",
+	"suite_access_token",
+	"provider_access_token",
+	"secret",
+	"corpsecret",
+	"js_code",
+}
+
+// MaskURL 将uri中access_token、secret等敏感参数脱敏，其他参数及顺序保持不变
+func MaskURL(uri string) string {
+	idx := strings.IndexByte(uri, '?')
+	if idx < 0 {
+		return uri
+	}
+	query := uri[idx+1:]
+	fragment := ""
+	if i := strings.IndexByte(query, '#'); i >= 0 {
+		query, fragment = query[:i], query[i:]
+	}
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) == 2 && isSensitiveParam(kv[0]) {
+			// uri出现在错误描述等文本中时，参数值在空白或引号处结束
+			raw, rest := kv[1], ""
+			if j := strings.IndexAny(raw, " \t\n\"'"); j >= 0 {
+				raw, rest = raw[:j], raw[j:]
+			}
+			val, err := url.QueryUnescape(raw)
+			if err != nil {
+				val = raw
+			}
+			pairs[i] = kv[0] + "=" + MaskSecret(val) + rest
+		}
+	}
+	return uri[:idx+1] + strings.Join(pairs, "&") + fragment
+}
+
+func isSensitiveParam(key string) bool {
+	for _, param := range sensitiveParams {
+		if key == param {
+			return true
+		}
+	}
+	return false
+}
+
+// MaskSecret 仅保留首尾各4个字符，较短时全部隐藏
+func MaskSecret(val string) string {
+	if len(val) <= 12 {
+		return "***"
+	}
+	return val[:4] + "***" + val[len(val)-4:]
+}
+
+// newCall 创建本次请求的调用信息，context中没有拦截器时返回nil
+func newCall(ctx context.Context, request *http.Request) *Call {
+	if len(InterceptorsFromContext(ctx)) == 0 {
+		return nil
+	}
+	apiName, _ := ctx.Value(apiNameKey{}).(string)
+	if apiName == "" {
+		apiName = request.URL.Path
+	}
+	return &Call{
+		AppID:     AppIDFromContext(ctx),
+		APIName:   apiName,
+		Method:    request.Method,
+		URL:       MaskURL(request.URL.String()),
+		Path:      request.URL.Path,
+		StartTime: time.Now(),
+		Header:    request.Header,
+	}
+}
+
+// beforeRequest 依次调用 BeforeRequest，返回错误时对已成功执行 BeforeRequest 的拦截器调用 OnError
+func (call *Call) beforeRequest(ctx context.Context) error {
+	if call == nil {
+		return nil
+	}
+	interceptors := InterceptorsFromContext(ctx)
+	for i, interceptor := range interceptors {
+		if interceptor.BeforeRequest == nil {
+			continue
+		}
+		err := interceptor.BeforeRequest(ctx, call)
+		if err == nil {
+			continue
+		}
+		call.Latency = time.Since(call.StartTime)
+		call.Err = err
+		for _, started := range interceptors[:i] {
+			if started.BeforeRequest != nil && started.OnError != nil {
+				started.OnError(ctx, call, err)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// afterResponse 记录返回结果并调用 AfterResponse 及 OnError
+func (call *Call) afterResponse(ctx context.Context, statusCode int, response []byte, err error) {
+	if call == nil {
+		return
+	}
+	call.Latency = time.Since(call.StartTime)
+	call.StatusCode = statusCode
+	if err == nil {
+		call.CommonError = decodeCommonError(response)
+		if call.CommonError != nil && call.CommonError.ErrCode != 0 {
+			err = call.CommonError.Err(call.APIName)
+		}
+	}
+	call.Err = err
+
+	interceptors := InterceptorsFromContext(ctx)
+	if statusCode != 0 {
+		for _, interceptor := range interceptors {
+			if interceptor.AfterResponse != nil {
+				interceptor.AfterResponse(ctx, call)
+			}
+		}
+	}
+	if err != nil {
+		for _, interceptor := range interceptors {
+			if interceptor.OnError != nil {
+				interceptor.OnError(ctx, call, err)
+			}
+		}
+	}
+}
+
+// decodeCommonError 返回内容为包含errcode的json时解析errcode及errmsg
+func decodeCommonError(response []byte) *CommonError {
+	response = bytes.TrimSpace(response)
+	if len(response) == 0 || response[0] != '{' || !bytes.Contains(response, []byte(`"errcode"`)) {
+		return nil
+	}
+	var commError CommonError
+	if err := json.Unmarshal(response, &commError); err != nil {
+		return nil
+	}
+	return &commError
+}
@@ -0,0 +1,77 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterceptors(t *testing.T) {
+	client := doerFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/cgi-bin/message/template/send" {
+			return newResponse(http.StatusOK, `{"errcode":40003,"errmsg":"invalid openid rid: 6123-abc"}`), nil
+		}
+		return newResponse(http.StatusOK, `{"errcode":0,"errmsg":"ok"}`), nil
+	})
+
+	var events []string
+	var calls []*Call
+	var callErr error
+	ctx := WithInterceptors(WithHTTPClient(context.Background(), client), Interceptor{
+		BeforeRequest: func(ctx context.Context, call *Call) error {
+			events = append(events, "before1")
+			return nil
+		},
+		AfterResponse: func(ctx context.Context, call *Call) {
+			events = append(events, "after1")
+			calls = append(calls, call)
+		},
+	})
+	ctx = WithInterceptors(ctx, Interceptor{
+		BeforeRequest: func(ctx context.Context, call *Call) error {
+			events = append(events, "before2")
+			return nil
+		},
+		OnError: func(ctx context.Context, call *Call, err error) {
+			events = append(events, "error2")
+			callErr = err
+		},
+	})
+
+	_, err := PostJSONContext(WithAPIName(ctx, "SendTemplate"), "http://example.com/cgi-bin/message/template/send?access_token=ACCESS_TOKEN_123456", map[string]string{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"before1", "before2", "after1", "error2"}, events)
+	call := calls[0]
+	assert.Equal(t, "SendTemplate", call.APIName)
+	assert.Equal(t, http.MethodPost, call.Method)
+	assert.Equal(t, "/cgi-bin/message/template/send", call.Path)
+	assert.Equal(t, "http://example.com/cgi-bin/message/template/send?access_token=ACCE***3456", call.URL)
+	assert.Equal(t, http.StatusOK, call.StatusCode)
+	assert.Equal(t, int64(40003), call.CommonError.ErrCode)
+	e, ok := AsError(callErr)
+	assert.True(t, ok)
+	assert.Equal(t, "SendTemplate", e.APIName)
+	assert.Equal(t, "6123-abc", e.Rid)
+
+	// 未设置接口名称时使用路径，BeforeRequest返回错误时不发送请求，已执行 BeforeRequest 的拦截器收到 OnError
+	events = nil
+	callErr = nil
+	stop := errors.New("stop")
+	_, err = HTTPGetContext(WithInterceptors(ctx, Interceptor{
+		BeforeRequest: func(ctx context.Context, call *Call) error {
+			assert.Equal(t, "/cgi-bin/user/info", call.APIName)
+			return stop
+		},
+	}), "http://example.com/cgi-bin/user/info")
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, []string{"before1", "before2", "error2"}, events)
+	assert.Equal(t, stop, callErr)
+}
+
+func TestMaskURL(t *testing.T) {
+	assert.Equal(t, "https://api.weixin.qq.com/cgi-bin/token?grant_type=client_credential&appid=wx1&secret=***", MaskURL("https://api.weixin.qq.com/cgi-bin/token?grant_type=client_credential&appid=wx1&secret=abc"))
+	assert.Equal(t, "https://api.weixin.qq.com/cgi-bin/user/info?openid=o1", MaskURL("https://api.weixin.qq.com/cgi-bin/user/info?openid=o1"))
+}
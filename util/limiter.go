@@ -0,0 +1,29 @@
+package util
+
+import "context"
+
+// Limiter 发送请求前调用，用于客户端限流及配额统计
+type Limiter interface {
+	// Wait 等待path对应的接口可以调用，返回错误时不再发送请求
+	Wait(ctx context.Context, path string) error
+}
+
+type limiterKey struct{}
+
+// limiterValue 包装Limiter，使显式设置的nil也能被识别
+type limiterValue struct {
+	limiter Limiter
+}
+
+// WithLimiter 返回携带Limiter的context，使用该context发起的每次接口调用都会先调用一次 Limiter.Wait，重试不再重复调用
+// limiter为nil时不限流
+func WithLimiter(ctx context.Context, limiter Limiter) context.Context {
+	return context.WithValue(ctx, limiterKey{}, limiterValue{limiter})
+}
+
+// LimiterFromContext 获取context中携带的Limiter
+// ok 表示context中是否设置过，包括通过 WithLimiter(ctx, nil) 显式关闭的情况
+func LimiterFromContext(ctx context.Context) (Limiter, bool) {
+	value, ok := ctx.Value(limiterKey{}).(limiterValue)
+	return value.limiter, ok
+}
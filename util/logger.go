@@ -0,0 +1,196 @@
+package util
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+)
+
+// Logger 日志接口，附加字段以 key, value 交替传入
+// Go 1.21 及以上版本的 *slog.Logger 已实现该接口，可直接使用
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// LogLevel 日志级别
+type LogLevel int
+
+// 日志级别
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// String 日志级别名称
+func (level LogLevel) String() string {
+	switch level {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	}
+	return "ERROR"
+}
+
+// StdLogger 使用标准库 log.Logger 输出的Logger
+type StdLogger struct {
+	logger *log.Logger
+	level  LogLevel
+}
+
+// NewStdLogger 创建StdLogger，低于level的日志不输出，logger为nil时输出到标准错误
+func NewStdLogger(logger *log.Logger, level LogLevel) *StdLogger {
+	if logger == nil {
+		logger = log.New(os.Stderr, "", log.LstdFlags)
+	}
+	return &StdLogger{logger: logger, level: level}
+}
+
+// Debug debug日志
+func (l *StdLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.output(LevelDebug, msg, keysAndValues)
+}
+
+// Info info日志
+func (l *StdLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.output(LevelInfo, msg, keysAndValues)
+}
+
+// Warn warn日志
+func (l *StdLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.output(LevelWarn, msg, keysAndValues)
+}
+
+// Error error日志
+func (l *StdLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.output(LevelError, msg, keysAndValues)
+}
+
+func (l *StdLogger) output(level LogLevel, msg string, keysAndValues []interface{}) {
+	if level < l.level {
+		return
+	}
+	var b strings.Builder
+	b.WriteString(level.String())
+	b.WriteByte(' ')
+	b.WriteString(msg)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		b.WriteByte(' ')
+		if i+1 == len(keysAndValues) {
+			fmt.Fprintf(&b, "%v", keysAndValues[i])
+			break
+		}
+		fmt.Fprintf(&b, "%v=%v", keysAndValues[i], keysAndValues[i+1])
+	}
+	_ = l.logger.Output(3, b.String())
+}
+
+// NopLogger 不输出任何日志
+var NopLogger Logger = NewStdLogger(log.New(ioutil.Discard, "", 0), LevelError+1)
+
+// DefaultLogger 未单独设置时使用的Logger，仅输出warn及以上级别的日志到标准错误
+var DefaultLogger Logger = NewStdLogger(nil, LevelWarn)
+
+// SetDefaultLogger 设置全局默认的Logger，logger为nil时不输出日志
+func SetDefaultLogger(logger Logger) {
+	if logger == nil {
+		logger = NopLogger
+	}
+	DefaultLogger = logger
+}
+
+// SensitiveLogKeys 日志中需要脱敏的字段，值会被替换为 MaskSecret 的结果或仅保留长度
+var SensitiveLogKeys = []string{
+	"access_token",
+	"session_key",
+	"secret",
+	"ticket",
+	"encoding_aes_key",
+	"body",
+	"decrypted",
+}
+
+// RedactLogger 返回对敏感字段脱敏的Logger
+// 字段名在 SensitiveLogKeys 中时脱敏，其他字符串、error 及 fmt.Stringer 的值中的access_token等url参数通过 MaskURL 脱敏
+func RedactLogger(logger Logger) Logger {
+	if logger == nil {
+		logger = DefaultLogger
+	}
+	if _, ok := logger.(redactLogger); ok {
+		return logger
+	}
+	return redactLogger{logger}
+}
+
+type redactLogger struct {
+	logger Logger
+}
+
+func (l redactLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.logger.Debug(msg, redactFields(keysAndValues)...)
+}
+
+func (l redactLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.logger.Info(msg, redactFields(keysAndValues)...)
+}
+
+func (l redactLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.logger.Warn(msg, redactFields(keysAndValues)...)
+}
+
+func (l redactLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.logger.Error(msg, redactFields(keysAndValues)...)
+}
+
+// redactFields 复制并脱敏日志字段
+func redactFields(keysAndValues []interface{}) []interface{} {
+	fields := make([]interface{}, len(keysAndValues))
+	copy(fields, keysAndValues)
+	for i := 0; i+1 < len(fields); i += 2 {
+		key, _ := fields[i].(string)
+		if isSensitiveLogKey(key) {
+			fields[i+1] = redactValue(fields[i+1])
+			continue
+		}
+		switch v := fields[i+1].(type) {
+		case string:
+			fields[i+1] = MaskURL(v)
+		case error:
+			// 如 *StatusError 的描述中包含完整的请求地址
+			fields[i+1] = MaskURL(v.Error())
+		case fmt.Stringer:
+			fields[i+1] = MaskURL(v.String())
+		}
+	}
+	return fields
+}
+
+func isSensitiveLogKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range SensitiveLogKeys {
+		if key == sensitive {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValue 字符串保留首尾各4个字符，[]byte 仅保留长度
+func redactValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case string:
+		return MaskSecret(v)
+	case []byte:
+		return fmt.Sprintf("[REDACTED %d bytes]", len(v))
+	}
+	return "[REDACTED]"
+}
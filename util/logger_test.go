@@ -0,0 +1,40 @@
+package util
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewStdLogger(log.New(&buf, "", 0), LevelInfo)
+
+	logger.Debug("debug msg", "k", "v")
+	assert.Empty(t, buf.String())
+
+	logger.Info("info msg", "appid", "wx123", "count", 2, "dangling")
+	assert.Equal(t, "INFO info msg appid=wx123 count=2 dangling\n", buf.String())
+}
+
+func TestRedactLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := RedactLogger(NewStdLogger(log.New(&buf, "", 0), LevelDebug))
+	assert.Equal(t, logger, RedactLogger(logger))
+
+	logger.Debug("request",
+		"body", []byte("<xml><Content>hello</Content></xml>"),
+		"session_key", "tiihtNczf5v6AKRyjwEUhQ==",
+		"url", "https://api.weixin.qq.com/cgi-bin/user/info?access_token=ACCESS_TOKEN_VALUE_1234&openid=o1",
+		"appid", "wx123")
+	assert.Equal(t, "DEBUG request body=[REDACTED 35 bytes] session_key=tiih***hQ== "+
+		"url=https://api.weixin.qq.com/cgi-bin/user/info?access_token=ACCE***1234&openid=o1 appid=wx123\n", buf.String())
+
+	// error 的描述中包含完整的请求地址
+	buf.Reset()
+	err := &StatusError{Method: "GET", URI: "https://api.weixin.qq.com/cgi-bin/token?grant_type=client_credential&appid=wx1&secret=APP_SECRET_VALUE_5678", StatusCode: 502}
+	logger.Error("request failed", "error", err)
+	assert.Equal(t, "ERROR request failed error=http get error : uri=https://api.weixin.qq.com/cgi-bin/token?grant_type=client_credential&appid=wx1&secret=APP_***5678 , statusCode=502\n", buf.String())
+}
@@ -0,0 +1,181 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// StatusError 微信服务器返回非200状态码
+type StatusError struct {
+	Method     string
+	URI        string
+	StatusCode int
+}
+
+// Error 保持与之前版本一致的错误描述
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("http %s error : uri=%v , statusCode=%v", strings.ToLower(e.Method), e.URI, e.StatusCode)
+}
+
+// RetryPolicy 请求失败时的重试策略
+// GET请求及通过 WithIdempotent 标记的请求视为幂等，在网络错误、RetryableStatuses、RetryableErrCodes 时重试；
+// 其他请求（如发送模板消息、群发）可能已被微信处理，仅在连接建立失败（DNS解析、TCP连接失败）时重试
+type RetryPolicy struct {
+	MaxAttempts       int           `json:"max_attempts"` // 最大尝试次数，包含首次请求，小于等于1时不重试
+	MinBackoff        time.Duration // 首次重试前的等待时间，默认100毫秒，之后每次翻倍
+	MaxBackoff        time.Duration // 最长等待时间，默认2秒
+	Jitter            float64       `json:"jitter"`             // 等待时间的随机浮动比例，取值0~1
+	RetryableErrCodes []int64       `json:"retryable_errcodes"` // 可重试的errcode，为nil时为 -1（系统繁忙）
+	RetryableStatuses []int         `json:"retryable_statuses"` // 可重试的HTTP状态码，为nil时为 500、502、503、504
+}
+
+// DefaultRetryPolicy 默认重试策略，最多请求3次
+var DefaultRetryPolicy = &RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  100 * time.Millisecond,
+	MaxBackoff:  2 * time.Second,
+	Jitter:      0.2,
+}
+
+var (
+	defaultRetryableErrCodes = []int64{ErrCodeSystemBusy}
+	defaultRetryableStatuses = []int{
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	}
+)
+
+type retryPolicyKey struct{}
+
+type idempotentKey struct{}
+
+// WithRetryPolicy 返回携带重试策略的context，policy为nil时不重试
+func WithRetryPolicy(ctx context.Context, policy *RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+// RetryPolicyFromContext 获取context中携带的重试策略
+// ok 表示context中是否设置过，包括通过 WithRetryPolicy(ctx, nil) 显式关闭的情况
+func RetryPolicyFromContext(ctx context.Context) (policy *RetryPolicy, ok bool) {
+	policy, ok = ctx.Value(retryPolicyKey{}).(*RetryPolicy)
+	return
+}
+
+// WithIdempotent 将使用该context发出的POST请求标记为幂等，如各类查询接口，失败时按GET请求的规则重试
+func WithIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// isIdempotent 请求是否可以安全重试
+func isIdempotent(ctx context.Context, method string) bool {
+	if method == http.MethodGet || method == http.MethodHead {
+		return true
+	}
+	idempotent, _ := ctx.Value(idempotentKey{}).(bool)
+	return idempotent
+}
+
+// retryable 判断第attempt次请求的结果是否需要重试
+func (p *RetryPolicy) retryable(ctx context.Context, attempt int, idempotent bool, response []byte, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		if isDialError(err) {
+			return true
+		}
+		if !idempotent {
+			return false
+		}
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) {
+			return p.retryableStatus(statusErr.StatusCode)
+		}
+		// 其他网络错误，如读取返回内容时连接断开
+		var netErr net.Error
+		return errors.As(err, &netErr)
+	}
+	return idempotent && p.retryableErrCode(response)
+}
+
+func (p *RetryPolicy) retryableStatus(statusCode int) bool {
+	statuses := p.RetryableStatuses
+	if statuses == nil {
+		statuses = defaultRetryableStatuses
+	}
+	for _, status := range statuses {
+		if status == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) retryableErrCode(response []byte) bool {
+	commError := decodeCommonError(response)
+	if commError == nil || commError.ErrCode == 0 {
+		return false
+	}
+	codes := p.RetryableErrCodes
+	if codes == nil {
+		codes = defaultRetryableErrCodes
+	}
+	for _, code := range codes {
+		if code == commError.ErrCode {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff 第attempt次请求失败后的等待时间
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	minBackoff, maxBackoff := p.MinBackoff, p.MaxBackoff
+	if minBackoff <= 0 {
+		minBackoff = 100 * time.Millisecond
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = 2 * time.Second
+	}
+	d := minBackoff
+	for i := 1; i < attempt && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	if p.Jitter > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(d))
+	}
+	return d
+}
+
+// isDialError 是否为连接建立失败，此时请求尚未发出，任何请求都可以安全重试
+func isDialError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// sleepContext 等待d，ctx取消时提前返回错误
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,82 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testRetryPolicy = &RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+func TestRetryIdempotent(t *testing.T) {
+	attempts := 0
+	client := doerFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		switch attempts {
+		case 1:
+			return newResponse(http.StatusBadGateway, ""), nil
+		case 2:
+			return newResponse(http.StatusOK, `{"errcode":-1,"errmsg":"system error"}`), nil
+		}
+		return newResponse(http.StatusOK, `{"errcode":0,"errmsg":"ok"}`), nil
+	})
+	ctx := WithRetryPolicy(WithHTTPClient(context.Background(), client), testRetryPolicy)
+	response, err := HTTPGetContext(ctx, "http://example.com/cgi-bin/user/info")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"errcode":0,"errmsg":"ok"}`, string(response))
+	assert.Equal(t, 3, attempts)
+
+	// 超过最大尝试次数后返回最后一次的结果
+	attempts = 0
+	client = doerFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return newResponse(http.StatusServiceUnavailable, ""), nil
+	})
+	_, err = HTTPGetContext(WithRetryPolicy(WithHTTPClient(context.Background(), client), testRetryPolicy), "http://example.com/cgi-bin/user/info")
+	var statusErr *StatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	attempts := 0
+	client := doerFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return newResponse(http.StatusOK, `{"errcode":-1,"errmsg":"system error"}`), nil
+	})
+	ctx := WithRetryPolicy(WithHTTPClient(context.Background(), client), testRetryPolicy)
+	_, err := PostJSONContext(ctx, "http://example.com/cgi-bin/message/template/send", map[string]string{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, attempts)
+
+	// 标记为幂等后按GET的规则重试
+	attempts = 0
+	_, _ = PostJSONContext(WithIdempotent(ctx), "http://example.com/cgi-bin/user/info/batchget", map[string]string{})
+	assert.Equal(t, 3, attempts)
+
+	// 连接建立失败时请求未发出，可以重试
+	attempts = 0
+	client = doerFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+		}
+		return newResponse(http.StatusOK, `{"errcode":0,"errmsg":"ok"}`), nil
+	})
+	_, err = PostJSONContext(WithRetryPolicy(WithHTTPClient(context.Background(), client), testRetryPolicy), "http://example.com/cgi-bin/message/template/send", map[string]string{})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := &RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(1))
+	assert.Equal(t, 200*time.Millisecond, policy.backoff(2))
+	assert.Equal(t, time.Second, policy.backoff(10))
+}
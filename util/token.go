@@ -0,0 +1,63 @@
+package util
+
+import (
+	"context"
+	"net/url"
+)
+
+// TokenRefresher access_token失效时调用，作废staleToken并返回重新获取的access_token
+type TokenRefresher func(ctx context.Context, staleToken string) (accessToken string, err error)
+
+type tokenRefresherKey struct{}
+
+// WithTokenRefresher 返回携带TokenRefresher的context
+// 使用该context发出的请求，若url中携带的access_token被微信判定为失效（40001、40014、42001），
+// 会调用refresher刷新access_token后使用新的access_token重试一次
+// refresher为nil时关闭自动重试
+func WithTokenRefresher(ctx context.Context, refresher TokenRefresher) context.Context {
+	return context.WithValue(ctx, tokenRefresherKey{}, refresher)
+}
+
+// TokenRefresherFromContext 获取context中携带的TokenRefresher
+// ok 表示context中是否设置过，包括通过 WithTokenRefresher(ctx, nil) 显式关闭的情况
+func TokenRefresherFromContext(ctx context.Context) (refresher TokenRefresher, ok bool) {
+	refresher, ok = ctx.Value(tokenRefresherKey{}).(TokenRefresher)
+	return
+}
+
+// refreshTokenURI 返回内容为access_token失效的错误时，通过context中的TokenRefresher刷新access_token，
+// 返回替换为新access_token的uri及用于重试的context
+func refreshTokenURI(ctx context.Context, uri string, response []byte) (context.Context, string, bool) {
+	refresher, _ := TokenRefresherFromContext(ctx)
+	if refresher == nil || !isAccessTokenInvalidResponse(response) {
+		return ctx, "", false
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ctx, "", false
+	}
+	query := u.Query()
+	staleToken := query.Get("access_token")
+	if staleToken == "" {
+		return ctx, "", false
+	}
+
+	// 刷新及重试时不再触发自动重试，避免循环
+	ctx = WithTokenRefresher(ctx, nil)
+	accessToken, err := refresher(ctx, staleToken)
+	if err != nil || accessToken == "" || accessToken == staleToken {
+		return ctx, "", false
+	}
+	query.Set("access_token", accessToken)
+	u.RawQuery = query.Encode()
+	return ctx, u.String(), true
+}
+
+// isAccessTokenInvalidResponse 返回内容是否为access_token失效的错误
+func isAccessTokenInvalidResponse(response []byte) bool {
+	commError := decodeCommonError(response)
+	if commError == nil || commError.ErrCode == 0 {
+		return false
+	}
+	return NewError("", commError.ErrCode, commError.ErrMsg).IsAccessTokenInvalid()
+}
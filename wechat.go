@@ -1,8 +1,6 @@
 package wechat
 
 import (
-	"os"
-
 	"github.com/amazing-gao/wechat/v2/cache"
 	"github.com/amazing-gao/wechat/v2/miniprogram"
 	miniConfig "github.com/amazing-gao/wechat/v2/miniprogram/config"
@@ -12,26 +10,18 @@ import (
 	openConfig "github.com/amazing-gao/wechat/v2/openplatform/config"
 	"github.com/amazing-gao/wechat/v2/pay"
 	payConfig "github.com/amazing-gao/wechat/v2/pay/config"
+	"github.com/amazing-gao/wechat/v2/util"
 	"github.com/amazing-gao/wechat/v2/work"
 	workConfig "github.com/amazing-gao/wechat/v2/work/config"
-	log "github.com/sirupsen/logrus"
 )
 
-func init() {
-	// Log as JSON instead of the default ASCII formatter.
-	log.SetFormatter(&log.TextFormatter{})
-
-	// Output to stdout instead of the default stderr
-	// Can be any io.Writer, see below for File example
-	log.SetOutput(os.Stdout)
-
-	// Only log the warning severity or above.
-	log.SetLevel(log.DebugLevel)
-}
-
 // Wechat struct
 type Wechat struct {
-	cache cache.Cache
+	cache        cache.Cache
+	httpClient   util.Doer
+	interceptors []util.Interceptor
+	logger       util.Logger
+	registry     registry
 }
 
 // NewWechat init
@@ -44,33 +34,104 @@ func (wc *Wechat) SetCache(cahce cache.Cache) {
 	wc.cache = cahce
 }
 
+// SetHTTPClient 设置HTTP客户端，作为未单独设置HTTP客户端的各账号配置的默认值
+func (wc *Wechat) SetHTTPClient(client util.Doer) {
+	wc.httpClient = client
+}
+
+// SetLogger 设置日志，作为未单独设置Logger的各账号配置的默认值，均未设置时使用 util.DefaultLogger
+func (wc *Wechat) SetLogger(logger util.Logger) {
+	wc.logger = logger
+}
+
+// Use 注册接口调用拦截器，对之后通过 Wechat 获取的所有账号生效，先于账号配置中的拦截器执行
+// 获取实例时使用传入配置的副本合并拦截器及默认值，不会修改传入的配置
+func (wc *Wechat) Use(interceptors ...util.Interceptor) {
+	wc.interceptors = append(wc.interceptors, interceptors...)
+}
+
+// withInterceptors 返回 Wechat 注册的拦截器及账号配置的拦截器
+func (wc *Wechat) withInterceptors(interceptors []util.Interceptor) []util.Interceptor {
+	if len(wc.interceptors) == 0 {
+		return interceptors
+	}
+	chain := make([]util.Interceptor, 0, len(wc.interceptors)+len(interceptors))
+	chain = append(chain, wc.interceptors...)
+	return append(chain, interceptors...)
+}
+
 // GetOfficialAccount 获取微信公众号实例
 func (wc *Wechat) GetOfficialAccount(cfg *offConfig.Config) *officialaccount.OfficialAccount {
+	c := *cfg
+	cfg = &c
 	if cfg.Cache == nil {
 		cfg.Cache = wc.cache
 	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = wc.httpClient
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = wc.logger
+	}
+	cfg.Interceptors = wc.withInterceptors(cfg.Interceptors)
 	return officialaccount.NewOfficialAccount(cfg)
 }
 
 // GetMiniProgram 获取小程序的实例
 func (wc *Wechat) GetMiniProgram(cfg *miniConfig.Config) *miniprogram.MiniProgram {
+	c := *cfg
+	cfg = &c
 	if cfg.Cache == nil {
 		cfg.Cache = wc.cache
 	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = wc.httpClient
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = wc.logger
+	}
+	cfg.Interceptors = wc.withInterceptors(cfg.Interceptors)
 	return miniprogram.NewMiniProgram(cfg)
 }
 
 // GetPay 获取微信支付的实例
 func (wc *Wechat) GetPay(cfg *payConfig.Config) *pay.Pay {
+	c := *cfg
+	cfg = &c
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = wc.httpClient
+	}
+	cfg.Interceptors = wc.withInterceptors(cfg.Interceptors)
 	return pay.NewPay(cfg)
 }
 
 // GetOpenPlatform 获取微信开放平台的实例
 func (wc *Wechat) GetOpenPlatform(cfg *openConfig.Config) *openplatform.OpenPlatform {
+	c := *cfg
+	cfg = &c
+	if cfg.Cache == nil {
+		cfg.Cache = wc.cache
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = wc.httpClient
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = wc.logger
+	}
+	cfg.Interceptors = wc.withInterceptors(cfg.Interceptors)
 	return openplatform.NewOpenPlatform(cfg)
 }
 
 // GetWork 获取企业微信的实例
 func (wc *Wechat) GetWork(cfg *workConfig.Config) *work.Work {
+	c := *cfg
+	cfg = &c
+	if cfg.Cache == nil {
+		cfg.Cache = wc.cache
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = wc.httpClient
+	}
+	cfg.Interceptors = wc.withInterceptors(cfg.Interceptors)
 	return work.NewWork(cfg)
 }
@@ -0,0 +1,26 @@
+package wechat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amazing-gao/wechat/v2/cache"
+	offConfig "github.com/amazing-gao/wechat/v2/officialaccount/config"
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+func TestGetOfficialAccountInterceptors(t *testing.T) {
+	wc := NewWechat()
+	wc.SetCache(cache.NewMemory())
+	wc.Use(util.Interceptor{})
+
+	// 同一配置多次获取实例时，Wechat 注册的拦截器不会重复追加
+	cfg := &offConfig.Config{AppID: "wx1", Interceptors: []util.Interceptor{{}}}
+	for i := 0; i < 2; i++ {
+		officialAccount := wc.GetOfficialAccount(cfg)
+		assert.Len(t, officialAccount.GetContext().Interceptors, 2)
+	}
+	assert.Len(t, cfg.Interceptors, 1)
+	assert.Nil(t, cfg.Cache)
+}
@@ -0,0 +1,24 @@
+// Package config 企业微信config配置
+package config
+
+import (
+	"github.com/amazing-gao/wechat/v2/cache"
+	"github.com/amazing-gao/wechat/v2/credential"
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+// Config .config for 企业微信
+type Config struct {
+	Server         string `json:"server"`           // server
+	OpenServer     string `json:"open_server"`      // 网页授权域名，默认 https://open.weixin.qq.com
+	SSOServer      string `json:"sso_server"`       // 扫码登录域名，默认 https://open.work.weixin.qq.com
+	CorpID         string `json:"corp_id"`          // corp_id
+	CorpSecret     string `json:"corp_secret"`      // corp_secret，应用或通讯录的secret
+	AgentID        string `json:"agent_id"`         // agent_id
+	Token          string `json:"token"`            // token
+	EncodingAESKey string `json:"encoding_aes_key"` // encoding_aes_key
+	Cache          cache.Cache
+	HTTPClient     util.Doer              `json:"-"` // 自定义HTTP客户端，为空时使用 util.DefaultHTTPClient
+	Interceptors   []util.Interceptor     `json:"-"` // 接口调用拦截器，在通过 Wechat.Use 注册的拦截器之后执行
+	RefreshHook    credential.RefreshHook `json:"-"` // 每次从服务端获取access_token或jsapi_ticket后的回调
+}
@@ -0,0 +1,17 @@
+package contact
+
+import (
+	"github.com/amazing-gao/wechat/v2/work/context"
+)
+
+// Contact 通讯录管理
+type Contact struct {
+	*context.Context
+}
+
+// NewContact 实例化
+func NewContact(context *context.Context) *Contact {
+	contact := new(Contact)
+	contact.Context = context
+	return contact
+}
@@ -0,0 +1,121 @@
+package contact
+
+import (
+	context2 "context"
+	"fmt"
+
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+// Department 部门信息
+type Department struct {
+	ID               int      `json:"id,omitempty"`
+	Name             string   `json:"name,omitempty"`
+	NameEn           string   `json:"name_en,omitempty"`
+	DepartmentLeader []string `json:"department_leader,omitempty"`
+	ParentID         int      `json:"parentid,omitempty"`
+	Order            int      `json:"order,omitempty"`
+}
+
+// resDepartmentCreate 创建部门的返回结果
+type resDepartmentCreate struct {
+	util.CommonError
+
+	ID int `json:"id"`
+}
+
+// resDepartmentList 获取部门列表的返回结果
+type resDepartmentList struct {
+	util.CommonError
+
+	Department []Department `json:"department"`
+}
+
+// CreateDepartment 创建部门，返回部门id
+func (contact *Contact) CreateDepartment(department *Department) (id int, err error) {
+	return contact.CreateDepartmentContext(context2.Background(), department)
+}
+
+// CreateDepartmentContext 创建部门，返回部门id
+func (contact *Contact) CreateDepartmentContext(ctx context2.Context, department *Department) (id int, err error) {
+	var accessToken string
+	accessToken, err = contact.GetAccessTokenContext(ctx)
+	if err != nil {
+		return
+	}
+	uri := fmt.Sprintf("%s/cgi-bin/department/create?access_token=%s", contact.Server, accessToken)
+	var response []byte
+	response, err = util.PostJSONContext(contact.RequestContext(ctx), uri, department)
+	if err != nil {
+		return
+	}
+	var result resDepartmentCreate
+	err = util.DecodeWithError(response, &result, "CreateDepartment")
+	id = result.ID
+	return
+}
+
+// UpdateDepartment 更新部门
+func (contact *Contact) UpdateDepartment(department *Department) error {
+	return contact.UpdateDepartmentContext(context2.Background(), department)
+}
+
+// UpdateDepartmentContext 更新部门
+func (contact *Contact) UpdateDepartmentContext(ctx context2.Context, department *Department) error {
+	accessToken, err := contact.GetAccessTokenContext(ctx)
+	if err != nil {
+		return err
+	}
+	uri := fmt.Sprintf("%s/cgi-bin/department/update?access_token=%s", contact.Server, accessToken)
+	response, err := util.PostJSONContext(contact.RequestContext(ctx), uri, department)
+	if err != nil {
+		return err
+	}
+	return util.DecodeWithCommonError(response, "UpdateDepartment")
+}
+
+// DeleteDepartment 删除部门
+func (contact *Contact) DeleteDepartment(id int) error {
+	return contact.DeleteDepartmentContext(context2.Background(), id)
+}
+
+// DeleteDepartmentContext 删除部门
+func (contact *Contact) DeleteDepartmentContext(ctx context2.Context, id int) error {
+	accessToken, err := contact.GetAccessTokenContext(ctx)
+	if err != nil {
+		return err
+	}
+	uri := fmt.Sprintf("%s/cgi-bin/department/delete?access_token=%s&id=%d", contact.Server, accessToken, id)
+	response, err := util.HTTPGetContext(contact.RequestContext(ctx), uri)
+	if err != nil {
+		return err
+	}
+	return util.DecodeWithCommonError(response, "DeleteDepartment")
+}
+
+// ListDepartment 获取部门列表，id为0时获取全量组织架构
+func (contact *Contact) ListDepartment(id int) (departments []Department, err error) {
+	return contact.ListDepartmentContext(context2.Background(), id)
+}
+
+// ListDepartmentContext 获取部门列表，id为0时获取全量组织架构
+func (contact *Contact) ListDepartmentContext(ctx context2.Context, id int) (departments []Department, err error) {
+	var accessToken string
+	accessToken, err = contact.GetAccessTokenContext(ctx)
+	if err != nil {
+		return
+	}
+	uri := fmt.Sprintf("%s/cgi-bin/department/list?access_token=%s", contact.Server, accessToken)
+	if id > 0 {
+		uri = fmt.Sprintf("%s&id=%d", uri, id)
+	}
+	var response []byte
+	response, err = util.HTTPGetContext(contact.RequestContext(ctx), uri)
+	if err != nil {
+		return
+	}
+	var result resDepartmentList
+	err = util.DecodeWithError(response, &result, "ListDepartment")
+	departments = result.Department
+	return
+}
@@ -0,0 +1,219 @@
+package contact
+
+import (
+	context2 "context"
+	"fmt"
+
+	"github.com/amazing-gao/wechat/v2/util"
+)
+
+// UserInfo 成员信息
+type UserInfo struct {
+	UserID         string   `json:"userid"`
+	Name           string   `json:"name,omitempty"`
+	Alias          string   `json:"alias,omitempty"`
+	Mobile         string   `json:"mobile,omitempty"`
+	Department     []int    `json:"department,omitempty"`
+	Order          []int    `json:"order,omitempty"`
+	Position       string   `json:"position,omitempty"`
+	Gender         string   `json:"gender,omitempty"`
+	Email          string   `json:"email,omitempty"`
+	BizMail        string   `json:"biz_mail,omitempty"`
+	IsLeaderInDept []int    `json:"is_leader_in_dept,omitempty"`
+	DirectLeader   []string `json:"direct_leader,omitempty"`
+	Enable         *int     `json:"enable,omitempty"`
+	Avatar         string   `json:"avatar,omitempty"`
+	AvatarMediaID  string   `json:"avatar_mediaid,omitempty"`
+	Telephone      string   `json:"telephone,omitempty"`
+	MainDepartment int      `json:"main_department,omitempty"`
+	Status         int      `json:"status,omitempty"`
+	OpenUserID     string   `json:"open_userid,omitempty"`
+	ToInvite       *bool    `json:"to_invite,omitempty"`
+}
+
+// SimpleUser 部门成员简要信息
+type SimpleUser struct {
+	UserID     string `json:"userid"`
+	Name       string `json:"name"`
+	Department []int  `json:"department"`
+	OpenUserID string `json:"open_userid"`
+}
+
+// resUserInfo 读取成员的返回结果
+type resUserInfo struct {
+	util.CommonError
+	UserInfo
+}
+
+// resSimpleUserList 获取部门成员的返回结果
+type resSimpleUserList struct {
+	util.CommonError
+
+	UserList []SimpleUser `json:"userlist"`
+}
+
+// resUserList 获取部门成员详情的返回结果
+type resUserList struct {
+	util.CommonError
+
+	UserList []UserInfo `json:"userlist"`
+}
+
+// CreateUser 创建成员
+func (contact *Contact) CreateUser(user *UserInfo) error {
+	return contact.CreateUserContext(context2.Background(), user)
+}
+
+// CreateUserContext 创建成员
+func (contact *Contact) CreateUserContext(ctx context2.Context, user *UserInfo) error {
+	accessToken, err := contact.GetAccessTokenContext(ctx)
+	if err != nil {
+		return err
+	}
+	uri := fmt.Sprintf("%s/cgi-bin/user/create?access_token=%s", contact.Server, accessToken)
+	response, err := util.PostJSONContext(contact.RequestContext(ctx), uri, user)
+	if err != nil {
+		return err
+	}
+	return util.DecodeWithCommonError(response, "CreateUser")
+}
+
+// GetUser 读取成员
+func (contact *Contact) GetUser(userID string) (user *UserInfo, err error) {
+	return contact.GetUserContext(context2.Background(), userID)
+}
+
+// GetUserContext 读取成员
+func (contact *Contact) GetUserContext(ctx context2.Context, userID string) (user *UserInfo, err error) {
+	var accessToken string
+	accessToken, err = contact.GetAccessTokenContext(ctx)
+	if err != nil {
+		return
+	}
+	uri := fmt.Sprintf("%s/cgi-bin/user/get?access_token=%s&userid=%s", contact.Server, accessToken, userID)
+	var response []byte
+	response, err = util.HTTPGetContext(contact.RequestContext(ctx), uri)
+	if err != nil {
+		return
+	}
+	var result resUserInfo
+	if err = util.DecodeWithError(response, &result, "GetUser"); err != nil {
+		return
+	}
+	user = &result.UserInfo
+	return
+}
+
+// UpdateUser 更新成员
+func (contact *Contact) UpdateUser(user *UserInfo) error {
+	return contact.UpdateUserContext(context2.Background(), user)
+}
+
+// UpdateUserContext 更新成员
+func (contact *Contact) UpdateUserContext(ctx context2.Context, user *UserInfo) error {
+	accessToken, err := contact.GetAccessTokenContext(ctx)
+	if err != nil {
+		return err
+	}
+	uri := fmt.Sprintf("%s/cgi-bin/user/update?access_token=%s", contact.Server, accessToken)
+	response, err := util.PostJSONContext(contact.RequestContext(ctx), uri, user)
+	if err != nil {
+		return err
+	}
+	return util.DecodeWithCommonError(response, "UpdateUser")
+}
+
+// DeleteUser 删除成员
+func (contact *Contact) DeleteUser(userID string) error {
+	return contact.DeleteUserContext(context2.Background(), userID)
+}
+
+// DeleteUserContext 删除成员
+func (contact *Contact) DeleteUserContext(ctx context2.Context, userID string) error {
+	accessToken, err := contact.GetAccessTokenContext(ctx)
+	if err != nil {
+		return err
+	}
+	uri := fmt.Sprintf("%s/cgi-bin/user/delete?access_token=%s&userid=%s", contact.Server, accessToken, userID)
+	response, err := util.HTTPGetContext(contact.RequestContext(ctx), uri)
+	if err != nil {
+		return err
+	}
+	return util.DecodeWithCommonError(response, "DeleteUser")
+}
+
+// BatchDeleteUser 批量删除成员，每次最多200个
+func (contact *Contact) BatchDeleteUser(userIDList []string) error {
+	return contact.BatchDeleteUserContext(context2.Background(), userIDList)
+}
+
+// BatchDeleteUserContext 批量删除成员，每次最多200个
+func (contact *Contact) BatchDeleteUserContext(ctx context2.Context, userIDList []string) error {
+	accessToken, err := contact.GetAccessTokenContext(ctx)
+	if err != nil {
+		return err
+	}
+	uri := fmt.Sprintf("%s/cgi-bin/user/batchdelete?access_token=%s", contact.Server, accessToken)
+	response, err := util.PostJSONContext(contact.RequestContext(ctx), uri, map[string][]string{
+		"useridlist": userIDList,
+	})
+	if err != nil {
+		return err
+	}
+	return util.DecodeWithCommonError(response, "BatchDeleteUser")
+}
+
+// ListSimpleUser 获取部门成员
+func (contact *Contact) ListSimpleUser(departmentID int, fetchChild bool) (users []SimpleUser, err error) {
+	return contact.ListSimpleUserContext(context2.Background(), departmentID, fetchChild)
+}
+
+// ListSimpleUserContext 获取部门成员
+func (contact *Contact) ListSimpleUserContext(ctx context2.Context, departmentID int, fetchChild bool) (users []SimpleUser, err error) {
+	var accessToken string
+	accessToken, err = contact.GetAccessTokenContext(ctx)
+	if err != nil {
+		return
+	}
+	uri := fmt.Sprintf("%s/cgi-bin/user/simplelist?access_token=%s&department_id=%d&fetch_child=%d", contact.Server, accessToken, departmentID, boolToInt(fetchChild))
+	var response []byte
+	response, err = util.HTTPGetContext(contact.RequestContext(ctx), uri)
+	if err != nil {
+		return
+	}
+	var result resSimpleUserList
+	err = util.DecodeWithError(response, &result, "ListSimpleUser")
+	users = result.UserList
+	return
+}
+
+// ListUser 获取部门成员详情
+func (contact *Contact) ListUser(departmentID int, fetchChild bool) (users []UserInfo, err error) {
+	return contact.ListUserContext(context2.Background(), departmentID, fetchChild)
+}
+
+// ListUserContext 获取部门成员详情
+func (contact *Contact) ListUserContext(ctx context2.Context, departmentID int, fetchChild bool) (users []UserInfo, err error) {
+	var accessToken string
+	accessToken, err = contact.GetAccessTokenContext(ctx)
+	if err != nil {
+		return
+	}
+	uri := fmt.Sprintf("%s/cgi-bin/user/list?access_token=%s&department_id=%d&fetch_child=%d", contact.Server, accessToken, departmentID, boolToInt(fetchChild))
+	var response []byte
+	response, err = util.HTTPGetContext(contact.RequestContext(ctx), uri)
+	if err != nil {
+		return
+	}
+	var result resUserList
+	err = util.DecodeWithError(response, &result, "ListUser")
+	users = result.UserList
+	return
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,43 @@
+package context
+
+import (
+	"context"
+
+	"github.com/amazing-gao/wechat/v2/credential"
+	"github.com/amazing-gao/wechat/v2/util"
+	"github.com/amazing-gao/wechat/v2/work/config"
+)
+
+// Context struct
+type Context struct {
+	*config.Config
+	credential.AccessTokenHandle
+}
+
+// RequestContext 返回发起请求使用的context，携带当前账号配置的HTTP客户端
+// 以及access_token失效时用于刷新并重试的 util.TokenRefresher，parent 中已设置时以 parent 为准
+// 账号配置的拦截器追加在 parent 中已有的拦截器之后
+func (ctx *Context) RequestContext(parent context.Context) context.Context {
+	if _, ok := util.HTTPClientFromContext(parent); !ok && ctx.HTTPClient != nil {
+		parent = util.WithHTTPClient(parent, ctx.HTTPClient)
+	}
+	if util.AppIDFromContext(parent) == "" {
+		parent = util.WithAppID(parent, ctx.CorpID)
+	}
+	parent = util.WithInterceptors(parent, ctx.Interceptors...)
+	if _, ok := util.TokenRefresherFromContext(parent); !ok {
+		if refresher := credential.TokenRefresher(ctx.AccessTokenHandle); refresher != nil {
+			parent = util.WithTokenRefresher(parent, refresher)
+		}
+	}
+	return parent
+}
+
+// GetAccessTokenContext 获取access_token
+// AccessTokenHandle 实现了 credential.AccessTokenContextHandle 时，parent 会传递给获取token的请求
+func (ctx *Context) GetAccessTokenContext(parent context.Context) (string, error) {
+	if handle, ok := ctx.AccessTokenHandle.(credential.AccessTokenContextHandle); ok {
+		return handle.GetAccessTokenContext(ctx.RequestContext(parent))
+	}
+	return ctx.GetAccessToken()
+}
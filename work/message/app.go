@@ -0,0 +1,159 @@
+package message
+
+import (
+	context2 "context"
+	"fmt"
+	"strconv"
+
+	"github.com/amazing-gao/wechat/v2/util"
+	"github.com/amazing-gao/wechat/v2/work/context"
+)
+
+// Manager 应用消息管理者，可以发送应用消息
+type Manager struct {
+	*context.Context
+}
+
+// NewMessageManager 实例化消息管理者
+func NewMessageManager(context *context.Context) *Manager {
+	return &Manager{
+		context,
+	}
+}
+
+// AppMessage 应用消息
+type AppMessage struct {
+	ToUser   string         `json:"touser,omitempty"`  // 成员ID列表，多个用'|'分隔，@all 表示全部成员
+	ToParty  string         `json:"toparty,omitempty"` // 部门ID列表，多个用'|'分隔
+	ToTag    string         `json:"totag,omitempty"`   // 标签ID列表，多个用'|'分隔
+	Msgtype  MsgType        `json:"msgtype"`           // 消息类型
+	AgentID  int            `json:"agentid"`           // 应用id，为空时使用配置中的AgentID
+	Safe     int            `json:"safe,omitempty"`    // 是否是保密消息
+	Text     *MediaText     `json:"text,omitempty"`
+	Image    *MediaResource `json:"image,omitempty"`
+	Voice    *MediaResource `json:"voice,omitempty"`
+	File     *MediaResource `json:"file,omitempty"`
+	TextCard *MediaTextCard `json:"textcard,omitempty"`
+	Markdown *MediaText     `json:"markdown,omitempty"`
+	News     *MediaNews     `json:"news,omitempty"`
+
+	EnableIDTrans          int `json:"enable_id_trans,omitempty"`          // 是否开启id转译
+	EnableDuplicateCheck   int `json:"enable_duplicate_check,omitempty"`   // 是否开启重复消息检查
+	DuplicateCheckInterval int `json:"duplicate_check_interval,omitempty"` // 重复消息检查的时间间隔，单位秒
+}
+
+const (
+	// MsgTypeFile 表示文件消息[限发送]
+	MsgTypeFile MsgType = "file"
+	// MsgTypeTextCard 表示文本卡片消息[限发送]
+	MsgTypeTextCard MsgType = "textcard"
+	// MsgTypeMarkdown 表示markdown消息[限发送]
+	MsgTypeMarkdown MsgType = "markdown"
+)
+
+// MediaText 文本消息的文字
+type MediaText struct {
+	Content string `json:"content"`
+}
+
+// MediaResource 消息使用的素材id
+type MediaResource struct {
+	MediaID string `json:"media_id"`
+}
+
+// MediaTextCard 文本卡片消息
+type MediaTextCard struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	BtnTxt      string `json:"btntxt,omitempty"`
+}
+
+// MediaNews 图文消息
+type MediaNews struct {
+	Articles []MediaArticle `json:"articles"`
+}
+
+// MediaArticle 图文消息中的单篇文章
+type MediaArticle struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url,omitempty"`
+	PicURL      string `json:"picurl,omitempty"`
+}
+
+// ResAppMessage 发送应用消息的返回结果
+type ResAppMessage struct {
+	util.CommonError
+
+	InvalidUser    string `json:"invaliduser"`
+	InvalidParty   string `json:"invalidparty"`
+	InvalidTag     string `json:"invalidtag"`
+	UnlicensedUser string `json:"unlicenseduser"`
+	MsgID          string `json:"msgid"`
+	ResponseCode   string `json:"response_code"`
+}
+
+// NewAppTextMessage 文本消息的构造方法
+func NewAppTextMessage(toUser, content string) *AppMessage {
+	return &AppMessage{
+		ToUser:  toUser,
+		Msgtype: MsgTypeText,
+		Text: &MediaText{
+			Content: content,
+		},
+	}
+}
+
+// NewAppMarkdownMessage markdown消息的构造方法
+func NewAppMarkdownMessage(toUser, content string) *AppMessage {
+	return &AppMessage{
+		ToUser:  toUser,
+		Msgtype: MsgTypeMarkdown,
+		Markdown: &MediaText{
+			Content: content,
+		},
+	}
+}
+
+// NewAppTextCardMessage 文本卡片消息的构造方法
+func NewAppTextCardMessage(toUser, title, description, url, btnTxt string) *AppMessage {
+	return &AppMessage{
+		ToUser:  toUser,
+		Msgtype: MsgTypeTextCard,
+		TextCard: &MediaTextCard{
+			Title:       title,
+			Description: description,
+			URL:         url,
+			BtnTxt:      btnTxt,
+		},
+	}
+}
+
+// Send 发送应用消息
+func (manager *Manager) Send(msg *AppMessage) (result ResAppMessage, err error) {
+	return manager.SendContext(context2.Background(), msg)
+}
+
+// SendContext 发送应用消息
+func (manager *Manager) SendContext(ctx context2.Context, msg *AppMessage) (result ResAppMessage, err error) {
+	if msg.AgentID == 0 {
+		if msg.AgentID, err = strconv.Atoi(manager.AgentID); err != nil {
+			err = fmt.Errorf("invalid agent_id: %v", err)
+			return
+		}
+	}
+	var accessToken string
+	accessToken, err = manager.GetAccessTokenContext(ctx)
+	if err != nil {
+		return
+	}
+	uri := fmt.Sprintf("%s/cgi-bin/message/send?access_token=%s", manager.Server, accessToken)
+	var response []byte
+	response, err = util.PostJSONContext(manager.RequestContext(ctx), uri, msg)
+	if err != nil {
+		return
+	}
+	err = util.DecodeWithError(response, &result, "SendAppMessage")
+	return
+}
@@ -0,0 +1,164 @@
+package message
+
+import (
+	"encoding/xml"
+)
+
+// MsgType 基本消息类型
+type MsgType string
+
+// EventType 事件类型
+type EventType string
+
+// ChangeType 通讯录变更事件类型
+type ChangeType string
+
+const (
+	// MsgTypeText 表示文本消息
+	MsgTypeText MsgType = "text"
+	// MsgTypeImage 表示图片消息
+	MsgTypeImage = "image"
+	// MsgTypeVoice 表示语音消息
+	MsgTypeVoice = "voice"
+	// MsgTypeVideo 表示视频消息
+	MsgTypeVideo = "video"
+	// MsgTypeLocation 表示位置消息[限接收]
+	MsgTypeLocation = "location"
+	// MsgTypeLink 表示链接消息[限接收]
+	MsgTypeLink = "link"
+	// MsgTypeNews 表示图文消息[限回复]
+	MsgTypeNews = "news"
+	// MsgTypeEvent 表示事件推送消息
+	MsgTypeEvent = "event"
+)
+
+const (
+	// EventSubscribe 成员关注
+	EventSubscribe EventType = "subscribe"
+	// EventUnsubscribe 成员取消关注
+	EventUnsubscribe = "unsubscribe"
+	// EventEnterAgent 进入应用
+	EventEnterAgent = "enter_agent"
+	// EventLocation 上报地理位置
+	EventLocation = "LOCATION"
+	// EventClick 点击菜单拉取消息的事件推送
+	EventClick = "click"
+	// EventView 点击菜单跳转链接的事件推送
+	EventView = "view"
+	// EventChangeContact 通讯录变更事件
+	EventChangeContact = "change_contact"
+)
+
+const (
+	// ChangeTypeCreateUser 新增成员
+	ChangeTypeCreateUser ChangeType = "create_user"
+	// ChangeTypeUpdateUser 更新成员
+	ChangeTypeUpdateUser = "update_user"
+	// ChangeTypeDeleteUser 删除成员
+	ChangeTypeDeleteUser = "delete_user"
+	// ChangeTypeCreateParty 新增部门
+	ChangeTypeCreateParty = "create_party"
+	// ChangeTypeUpdateParty 更新部门
+	ChangeTypeUpdateParty = "update_party"
+	// ChangeTypeDeleteParty 删除部门
+	ChangeTypeDeleteParty = "delete_party"
+)
+
+// MixMessage 存放所有企业微信发送过来的消息和事件
+type MixMessage struct {
+	CommonToken
+
+	AgentID int64 `xml:"AgentID"`
+
+	// 基本消息
+	MsgID        int64   `xml:"MsgId"`
+	Content      string  `xml:"Content"`
+	PicURL       string  `xml:"PicUrl"`
+	MediaID      string  `xml:"MediaId"`
+	Format       string  `xml:"Format"`
+	ThumbMediaID string  `xml:"ThumbMediaId"`
+	LocationX    float64 `xml:"Location_X"`
+	LocationY    float64 `xml:"Location_Y"`
+	Scale        float64 `xml:"Scale"`
+	Label        string  `xml:"Label"`
+	Title        string  `xml:"Title"`
+	Description  string  `xml:"Description"`
+	URL          string  `xml:"Url"`
+
+	// 事件相关
+	Event     EventType `xml:"Event"`
+	EventKey  string    `xml:"EventKey"`
+	Latitude  string    `xml:"Latitude"`
+	Longitude string    `xml:"Longitude"`
+	Precision string    `xml:"Precision"`
+
+	// 通讯录变更相关
+	ChangeType ChangeType `xml:"ChangeType"`
+	UserID     string     `xml:"UserID"`
+	NewUserID  string     `xml:"NewUserID"`
+	Name       string     `xml:"Name"`
+	Department string     `xml:"Department"`
+	ID         int64      `xml:"Id"`
+	ParentID   int64      `xml:"ParentId"`
+}
+
+// EncryptedXMLMsg 加密的消息体
+type EncryptedXMLMsg struct {
+	XMLName      struct{} `xml:"xml" json:"-"`
+	ToUserName   string   `xml:"ToUserName" json:"ToUserName"`
+	AgentID      string   `xml:"AgentID" json:"AgentID"`
+	EncryptedMsg string   `xml:"Encrypt"    json:"Encrypt"`
+}
+
+// ResponseEncryptedXMLMsg 需要返回的消息体
+type ResponseEncryptedXMLMsg struct {
+	XMLName      struct{} `xml:"xml" json:"-"`
+	EncryptedMsg CDATA    `xml:"Encrypt"      json:"Encrypt"`
+	MsgSignature CDATA    `xml:"MsgSignature" json:"MsgSignature"`
+	Timestamp    int64    `xml:"TimeStamp"    json:"TimeStamp"`
+	Nonce        CDATA    `xml:"Nonce"        json:"Nonce"`
+}
+
+// CDATA  使用该类型,在序列化为 xml 文本时文本会被解析器忽略
+type CDATA string
+
+// MarshalXML 实现自己的序列化方法
+func (c CDATA) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(struct {
+		string `xml:",cdata"`
+	}{string(c)}, start)
+}
+
+// CommonToken 消息中通用的结构
+type CommonToken struct {
+	XMLName      xml.Name `xml:"xml"`
+	ToUserName   CDATA    `xml:"ToUserName"`
+	FromUserName CDATA    `xml:"FromUserName"`
+	CreateTime   int64    `xml:"CreateTime"`
+	MsgType      MsgType  `xml:"MsgType"`
+}
+
+// SetToUserName set ToUserName
+func (msg *CommonToken) SetToUserName(toUserName CDATA) {
+	msg.ToUserName = toUserName
+}
+
+// SetFromUserName set FromUserName
+func (msg *CommonToken) SetFromUserName(fromUserName CDATA) {
+	msg.FromUserName = fromUserName
+}
+
+// SetCreateTime set createTime
+func (msg *CommonToken) SetCreateTime(createTime int64) {
+	msg.CreateTime = createTime
+}
+
+// SetMsgType set MsgType
+func (msg *CommonToken) SetMsgType(msgType MsgType) {
+	msg.MsgType = msgType
+}
+
+// GetUserID get the FromUserName value
+func (msg *CommonToken) GetUserID() string {
+	return string(msg.FromUserName)
+}
@@ -0,0 +1,114 @@
+package message
+
+import "errors"
+
+// ErrInvalidReply 无效的回复
+var ErrInvalidReply = errors.New("无效的回复消息")
+
+// ErrUnsupportReply 不支持的回复类型
+var ErrUnsupportReply = errors.New("不支持的回复消息")
+
+// Reply 消息回复
+type Reply struct {
+	MsgType MsgType
+	MsgData interface{}
+}
+
+// Text 文本消息
+type Text struct {
+	CommonToken
+	Content CDATA `xml:"Content"`
+}
+
+// NewText 初始化文本消息
+func NewText(content string) *Text {
+	text := new(Text)
+	text.Content = CDATA(content)
+	return text
+}
+
+// Image 图片消息
+type Image struct {
+	CommonToken
+
+	Image struct {
+		MediaID CDATA `xml:"MediaId"`
+	} `xml:"Image"`
+}
+
+// NewImage 回复图片消息
+func NewImage(mediaID string) *Image {
+	image := new(Image)
+	image.Image.MediaID = CDATA(mediaID)
+	return image
+}
+
+// Voice 语音消息
+type Voice struct {
+	CommonToken
+
+	Voice struct {
+		MediaID CDATA `xml:"MediaId"`
+	} `xml:"Voice"`
+}
+
+// NewVoice 回复语音消息
+func NewVoice(mediaID string) *Voice {
+	voice := new(Voice)
+	voice.Voice.MediaID = CDATA(mediaID)
+	return voice
+}
+
+// Video 视频消息
+type Video struct {
+	CommonToken
+
+	Video struct {
+		MediaID     CDATA `xml:"MediaId"`
+		Title       CDATA `xml:"Title,omitempty"`
+		Description CDATA `xml:"Description,omitempty"`
+	} `xml:"Video"`
+}
+
+// NewVideo 回复视频消息
+func NewVideo(mediaID, title, description string) *Video {
+	video := new(Video)
+	video.Video.MediaID = CDATA(mediaID)
+	video.Video.Title = CDATA(title)
+	video.Video.Description = CDATA(description)
+	return video
+}
+
+// News 图文消息
+type News struct {
+	CommonToken
+
+	ArticleCount int        `xml:"ArticleCount"`
+	Articles     []*Article `xml:"Articles>item,omitempty"`
+}
+
+// NewNews 初始化图文消息
+func NewNews(articles []*Article) *News {
+	news := new(News)
+	news.ArticleCount = len(articles)
+	news.Articles = articles
+	return news
+}
+
+// Article 单篇文章
+type Article struct {
+	Title       CDATA `xml:"Title,omitempty"`
+	Description CDATA `xml:"Description,omitempty"`
+	PicURL      CDATA `xml:"PicUrl,omitempty"`
+	URL         CDATA `xml:"Url,omitempty"`
+}
+
+// NewArticle 初始化文章
+func NewArticle(title, description, picURL, url string) *Article {
+	article := new(Article)
+	article.Title = CDATA(title)
+	article.Description = CDATA(description)
+	article.PicURL = CDATA(picURL)
+	article.URL = CDATA(url)
+	return article
+}
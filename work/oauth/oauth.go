@@ -0,0 +1,88 @@
+package oauth
+
+import (
+	context2 "context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/amazing-gao/wechat/v2/util"
+	"github.com/amazing-gao/wechat/v2/work/context"
+)
+
+const (
+	redirectOauthURL = "%s/connect/oauth2/authorize?appid=%s&redirect_uri=%s&response_type=code&scope=%s&state=%s&agentid=%s#wechat_redirect"
+	qrConnectURL     = "%s/wwopen/sso/qrConnect?appid=%s&agentid=%s&redirect_uri=%s&state=%s"
+)
+
+// Oauth 企业微信网页授权
+type Oauth struct {
+	*context.Context
+}
+
+// NewOauth 实例化授权信息
+func NewOauth(context *context.Context) *Oauth {
+	auth := new(Oauth)
+	auth.Context = context
+	return auth
+}
+
+// GetRedirectURL 获取跳转的url地址
+// scope 可选 snsapi_base、snsapi_privateinfo
+func (oauth *Oauth) GetRedirectURL(redirectURI, scope, state string) (string, error) {
+	urlStr := url.QueryEscape(redirectURI)
+	return fmt.Sprintf(redirectOauthURL, oauth.OpenServer, oauth.CorpID, urlStr, scope, state, oauth.AgentID), nil
+}
+
+// GetQRConnectURL 获取企业微信扫码登录的url地址
+func (oauth *Oauth) GetQRConnectURL(redirectURI, state string) (string, error) {
+	urlStr := url.QueryEscape(redirectURI)
+	return fmt.Sprintf(qrConnectURL, oauth.SSOServer, oauth.CorpID, oauth.AgentID, urlStr, state), nil
+}
+
+// Redirect 跳转到网页授权
+func (oauth *Oauth) Redirect(writer http.ResponseWriter, req *http.Request, redirectURI, scope, state string) error {
+	location, err := oauth.GetRedirectURL(redirectURI, scope, state)
+	if err != nil {
+		return err
+	}
+	http.Redirect(writer, req, location, http.StatusFound)
+	return nil
+}
+
+// ResUserInfo 根据code获取访问用户身份的返回结果
+type ResUserInfo struct {
+	util.CommonError
+
+	// 企业成员授权时返回
+	UserID     string `json:"UserId"`
+	DeviceID   string `json:"DeviceId"`
+	UserTicket string `json:"user_ticket"`
+	ExpiresIn  int64  `json:"expires_in"`
+
+	// 非企业成员授权时返回
+	OpenID         string `json:"OpenId"`
+	ExternalUserID string `json:"external_userid"`
+}
+
+// UserFromCode 根据code获取访问用户身份
+func (oauth *Oauth) UserFromCode(code string) (result ResUserInfo, err error) {
+	return oauth.UserFromCodeContext(context2.Background(), code)
+}
+
+// UserFromCodeContext 根据code获取访问用户身份
+func (oauth *Oauth) UserFromCodeContext(ctx context2.Context, code string) (result ResUserInfo, err error) {
+	var accessToken string
+	accessToken, err = oauth.GetAccessTokenContext(ctx)
+	if err != nil {
+		return
+	}
+	uri := fmt.Sprintf("%s/cgi-bin/user/getuserinfo?access_token=%s&code=%s", oauth.Server, accessToken, code)
+	var response []byte
+	response, err = util.HTTPGetContext(oauth.RequestContext(ctx), uri)
+	if err != nil {
+		return
+	}
+	err = util.DecodeWithError(response, &result, "UserFromCode")
+	return
+}
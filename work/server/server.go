@@ -0,0 +1,185 @@
+package server
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime/debug"
+	"strconv"
+
+	"github.com/amazing-gao/wechat/v2/util"
+	"github.com/amazing-gao/wechat/v2/work/context"
+	"github.com/amazing-gao/wechat/v2/work/message"
+)
+
+// Server struct
+type Server struct {
+	*context.Context
+	Writer  http.ResponseWriter
+	Request *http.Request
+
+	messageHandler func(*message.MixMessage) *message.Reply
+
+	RequestRawXMLMsg  []byte
+	RequestMsg        *message.MixMessage
+	ResponseRawXMLMsg []byte
+	ResponseMsg       interface{}
+
+	random    []byte
+	nonce     string
+	timestamp int64
+}
+
+// NewServer init
+func NewServer(context *context.Context) *Server {
+	srv := new(Server)
+	srv.Context = context
+	return srv
+}
+
+// Serve 处理企业微信的请求消息
+// 企业微信回调固定为加密模式，GET 请求用于验证URL有效性
+func (srv *Server) Serve() error {
+	echostr, exists := srv.GetQuery("echostr")
+	if exists {
+		return srv.verifyURL(echostr)
+	}
+
+	response, err := srv.handleRequest()
+	if err != nil {
+		return err
+	}
+	return srv.buildResponse(response)
+}
+
+// verifyURL 验证回调URL，解密echostr后原样返回
+func (srv *Server) verifyURL(echostr string) error {
+	if !srv.validate(echostr) {
+		return fmt.Errorf("请求校验失败")
+	}
+	_, plaintext, err := util.DecryptMsg(srv.CorpID, echostr, srv.EncodingAESKey)
+	if err != nil {
+		return fmt.Errorf("echostr解密失败, err=%v", err)
+	}
+	srv.String(string(plaintext))
+	return nil
+}
+
+// validate 校验消息签名
+func (srv *Server) validate(encrypted string) bool {
+	timestamp := srv.Query("timestamp")
+	nonce := srv.Query("nonce")
+	msgSignature := srv.Query("msg_signature")
+	return msgSignature == util.Signature(srv.Token, timestamp, nonce, encrypted)
+}
+
+// handleRequest 处理企业微信的请求
+func (srv *Server) handleRequest() (reply *message.Reply, err error) {
+	var encryptedXMLMsg message.EncryptedXMLMsg
+	if err = xml.NewDecoder(srv.Request.Body).Decode(&encryptedXMLMsg); err != nil {
+		return nil, fmt.Errorf("从body中解析xml失败,err=%v", err)
+	}
+	if !srv.validate(encryptedXMLMsg.EncryptedMsg) {
+		return nil, fmt.Errorf("消息不合法，验证签名失败")
+	}
+
+	srv.timestamp, err = strconv.ParseInt(srv.Query("timestamp"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	srv.nonce = srv.Query("nonce")
+
+	// 解密
+	var rawXMLMsgBytes []byte
+	srv.random, rawXMLMsgBytes, err = util.DecryptMsg(srv.CorpID, encryptedXMLMsg.EncryptedMsg, srv.EncodingAESKey)
+	if err != nil {
+		return nil, fmt.Errorf("消息解密失败, err=%v", err)
+	}
+	srv.RequestRawXMLMsg = rawXMLMsgBytes
+
+	mixMessage := &message.MixMessage{}
+	if err = xml.Unmarshal(rawXMLMsgBytes, mixMessage); err != nil {
+		return nil, err
+	}
+	srv.RequestMsg = mixMessage
+	if srv.messageHandler == nil {
+		return nil, errors.New("messageHandler is not set")
+	}
+	reply = srv.messageHandler(mixMessage)
+	return
+}
+
+// SetMessageHandler 设置用户自定义的回调方法
+func (srv *Server) SetMessageHandler(handler func(*message.MixMessage) *message.Reply) {
+	srv.messageHandler = handler
+}
+
+func (srv *Server) buildResponse(reply *message.Reply) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic error: %v\n%s", e, debug.Stack())
+		}
+	}()
+	if reply == nil {
+		// do nothing
+		return nil
+	}
+	msgType := reply.MsgType
+	switch msgType {
+	case message.MsgTypeText:
+	case message.MsgTypeImage:
+	case message.MsgTypeVoice:
+	case message.MsgTypeVideo:
+	case message.MsgTypeNews:
+	default:
+		err = message.ErrUnsupportReply
+		return
+	}
+
+	msgData := reply.MsgData
+	value := reflect.ValueOf(msgData)
+	// msgData must be a ptr
+	if value.Kind() != reflect.Ptr {
+		return message.ErrUnsupportReply
+	}
+
+	params := make([]reflect.Value, 1)
+	params[0] = reflect.ValueOf(srv.RequestMsg.FromUserName)
+	value.MethodByName("SetToUserName").Call(params)
+
+	params[0] = reflect.ValueOf(srv.RequestMsg.ToUserName)
+	value.MethodByName("SetFromUserName").Call(params)
+
+	params[0] = reflect.ValueOf(msgType)
+	value.MethodByName("SetMsgType").Call(params)
+
+	params[0] = reflect.ValueOf(util.GetCurrTS())
+	value.MethodByName("SetCreateTime").Call(params)
+
+	srv.ResponseMsg = msgData
+	srv.ResponseRawXMLMsg, err = xml.Marshal(msgData)
+	return
+}
+
+// Send 将自定义的消息加密后发送
+func (srv *Server) Send() (err error) {
+	if srv.ResponseRawXMLMsg == nil {
+		return
+	}
+	var encryptedMsg []byte
+	encryptedMsg, err = util.EncryptMsg(srv.random, srv.ResponseRawXMLMsg, srv.CorpID, srv.EncodingAESKey)
+	if err != nil {
+		return
+	}
+	timestampStr := strconv.FormatInt(srv.timestamp, 10)
+	msgSignature := util.Signature(srv.Token, timestampStr, srv.nonce, string(encryptedMsg))
+	srv.XML(message.ResponseEncryptedXMLMsg{
+		EncryptedMsg: message.CDATA(encryptedMsg),
+		MsgSignature: message.CDATA(msgSignature),
+		Timestamp:    srv.timestamp,
+		Nonce:        message.CDATA(srv.nonce),
+	})
+	return
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/amazing-gao/wechat/v2/util"
+	"github.com/amazing-gao/wechat/v2/work/config"
+	"github.com/amazing-gao/wechat/v2/work/context"
+	"github.com/amazing-gao/wechat/v2/work/message"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testCorpID = "wx5823bf96d3bd56c7"
+	testToken  = "QDG6eK"
+	testAESKey = "jWmYm7qr5nMoAUwZRjGtBxmz3KA1tkAj3ykkR6q2B2C"
+)
+
+func newTestServer(method, target, body string) (*Server, *httptest.ResponseRecorder) {
+	ctx := &context.Context{Config: &config.Config{CorpID: testCorpID, Token: testToken, EncodingAESKey: testAESKey}}
+	srv := NewServer(ctx)
+	srv.Request = httptest.NewRequest(method, target, strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	srv.Writer = recorder
+	return srv, recorder
+}
+
+func TestServeVerifyURL(t *testing.T) {
+	echostr, err := util.EncryptMsg([]byte(util.RandomStr(16)), []byte("1616140317555161061"), testCorpID, testAESKey)
+	assert.Nil(t, err)
+	signature := util.Signature(testToken, "1409659589", "263014780", string(echostr))
+
+	srv, recorder := newTestServer("GET", fmt.Sprintf("/?msg_signature=%s&timestamp=1409659589&nonce=263014780&echostr=%s", signature, url.QueryEscape(string(echostr))), "")
+	assert.Nil(t, srv.Serve())
+	assert.Equal(t, "1616140317555161061", recorder.Body.String())
+}
+
+func TestServeMessage(t *testing.T) {
+	raw := `<xml><ToUserName><![CDATA[corp]]></ToUserName><FromUserName><![CDATA[zhangsan]]></FromUserName><CreateTime>1348831860</CreateTime><MsgType><![CDATA[text]]></MsgType><Content><![CDATA[hello]]></Content><MsgId>1234567890123456</MsgId><AgentID>1</AgentID></xml>`
+	encrypted, err := util.EncryptMsg([]byte(util.RandomStr(16)), []byte(raw), testCorpID, testAESKey)
+	assert.Nil(t, err)
+	signature := util.Signature(testToken, "1409659589", "263014780", string(encrypted))
+	body := fmt.Sprintf(`<xml><ToUserName><![CDATA[corp]]></ToUserName><AgentID><![CDATA[1]]></AgentID><Encrypt><![CDATA[%s]]></Encrypt></xml>`, encrypted)
+
+	srv, recorder := newTestServer("POST", fmt.Sprintf("/?msg_signature=%s&timestamp=1409659589&nonce=263014780", signature), body)
+	srv.SetMessageHandler(func(msg *message.MixMessage) *message.Reply {
+		assert.Equal(t, "hello", msg.Content)
+		return &message.Reply{MsgType: message.MsgTypeText, MsgData: message.NewText("world")}
+	})
+	assert.Nil(t, srv.Serve())
+	assert.Nil(t, srv.Send())
+
+	var resp message.EncryptedXMLMsg
+	assert.Nil(t, xml.Unmarshal(recorder.Body.Bytes(), &resp))
+	_, plaintext, err := util.DecryptMsg(testCorpID, resp.EncryptedMsg, testAESKey)
+	assert.Nil(t, err)
+	assert.Contains(t, string(plaintext), "<Content><![CDATA[world]]></Content>")
+	assert.Contains(t, string(plaintext), "<ToUserName><![CDATA[zhangsan]]></ToUserName>")
+}
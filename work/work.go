@@ -0,0 +1,95 @@
+package work
+
+import (
+	context2 "context"
+	"net/http"
+
+	"github.com/amazing-gao/wechat/v2/credential"
+	"github.com/amazing-gao/wechat/v2/work/config"
+	"github.com/amazing-gao/wechat/v2/work/contact"
+	"github.com/amazing-gao/wechat/v2/work/context"
+	"github.com/amazing-gao/wechat/v2/work/message"
+	"github.com/amazing-gao/wechat/v2/work/oauth"
+	"github.com/amazing-gao/wechat/v2/work/server"
+)
+
+// Work 企业微信相关API
+type Work struct {
+	ctx *context.Context
+}
+
+// NewWork 实例化企业微信API
+func NewWork(cfg *config.Config) *Work {
+	if cfg.Server == "" {
+		cfg.Server = "https://qyapi.weixin.qq.com"
+	}
+	if cfg.OpenServer == "" {
+		cfg.OpenServer = "https://open.weixin.qq.com"
+	}
+	if cfg.SSOServer == "" {
+		cfg.SSOServer = "https://open.work.weixin.qq.com"
+	}
+
+	defaultAkHandle := credential.NewWorkAccessToken(cfg.CorpID, cfg.CorpSecret, credential.CacheKeyWorkPrefix, cfg.Cache)
+	defaultAkHandle.SetServer(cfg.Server)
+	defaultAkHandle.SetHTTPClient(cfg.HTTPClient)
+	defaultAkHandle.SetRefreshHook(cfg.RefreshHook)
+	ctx := &context.Context{
+		Config:            cfg,
+		AccessTokenHandle: defaultAkHandle,
+	}
+	return &Work{ctx: ctx}
+}
+
+// SetAccessTokenHandle 自定义access_token获取方式
+func (wk *Work) SetAccessTokenHandle(accessTokenHandle credential.AccessTokenHandle) {
+	wk.ctx.AccessTokenHandle = accessTokenHandle
+}
+
+// StartTokenRefresher 在后台于过期前主动刷新access_token，ctx取消后停止
+// 自定义的AccessTokenHandle未实现 credential.Renewer 时不生效
+func (wk *Work) StartTokenRefresher(ctx context2.Context) {
+	renewer, ok := wk.ctx.AccessTokenHandle.(credential.Renewer)
+	if !ok {
+		return
+	}
+	credential.NewBackgroundRefresher(renewer).Start(ctx)
+}
+
+// GetContext get Context
+func (wk *Work) GetContext() *context.Context {
+	return wk.ctx
+}
+
+// GetAccessToken 获取access_token
+func (wk *Work) GetAccessToken() (string, error) {
+	return wk.ctx.GetAccessToken()
+}
+
+// GetAccessTokenContext 获取access_token
+func (wk *Work) GetAccessTokenContext(ctx context2.Context) (string, error) {
+	return wk.ctx.GetAccessTokenContext(ctx)
+}
+
+// GetOauth 网页授权登录，获取访问用户身份
+func (wk *Work) GetOauth() *oauth.Oauth {
+	return oauth.NewOauth(wk.ctx)
+}
+
+// GetContact 通讯录管理，部门及成员的增删改查
+func (wk *Work) GetContact() *contact.Contact {
+	return contact.NewContact(wk.ctx)
+}
+
+// GetMessageManager 应用消息发送
+func (wk *Work) GetMessageManager() *message.Manager {
+	return message.NewMessageManager(wk.ctx)
+}
+
+// GetServer 接收消息与事件，被动回复消息
+func (wk *Work) GetServer(req *http.Request, writer http.ResponseWriter) *server.Server {
+	srv := server.NewServer(wk.ctx)
+	srv.Request = req
+	srv.Writer = writer
+	return srv
+}